@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"log"
 	"net/http"
 	"os"
@@ -9,9 +10,33 @@ import (
 	"syscall"
 	"time"
 
+	v1 "github.com/Marwan051/final_project_backend/internal/api/v1"
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/emissions"
+	"github.com/Marwan051/final_project_backend/internal/geo"
+	"github.com/Marwan051/final_project_backend/internal/notify"
+	"github.com/Marwan051/final_project_backend/internal/ratelimit"
+	"github.com/Marwan051/final_project_backend/internal/realtime"
 	"github.com/Marwan051/final_project_backend/internal/server"
+	"github.com/Marwan051/final_project_backend/internal/service/accessibility_service"
+	"github.com/Marwan051/final_project_backend/internal/service/alert_service"
+	"github.com/Marwan051/final_project_backend/internal/service/apikey_service"
+	"github.com/Marwan051/final_project_backend/internal/service/fare_service"
+	"github.com/Marwan051/final_project_backend/internal/service/favorite_service"
+	"github.com/Marwan051/final_project_backend/internal/service/history_service"
+	"github.com/Marwan051/final_project_backend/internal/service/itinerary_service"
+	"github.com/Marwan051/final_project_backend/internal/service/mobility_service"
+	"github.com/Marwan051/final_project_backend/internal/service/moderation_service"
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/service/notification_service"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
 	"github.com/Marwan051/final_project_backend/internal/service/route_service/pygrpc"
+	"github.com/Marwan051/final_project_backend/internal/service/share_service"
+	"github.com/Marwan051/final_project_backend/internal/service/submission_service"
+	"github.com/Marwan051/final_project_backend/internal/service/user_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
 	"github.com/Marwan051/final_project_backend/internal/utils"
+	"github.com/Marwan051/final_project_backend/internal/walking"
 )
 
 func main() {
@@ -21,9 +46,21 @@ func main() {
 	}
 	cfg := utils.Cfg
 
+	snapshotURL := cfg.NetworkSnapshotURL
+	if snapshotURL == "" {
+		snapshotURL = cfg.PublicBaseURL + "/api/v1/network/snapshot"
+	}
+	// Only the routing service may download the full network
+	snapshotToken := cfg.NetworkSnapshotToken
+	if snapshotToken == "" {
+		snapshotToken = rand.Text()
+	}
+
 	// load routing service with the routing server confgi
 	routingService, err := pygrpc.NewClient(pygrpc.ClientConfig{
-		Address: cfg.RoutingServiceAddr,
+		Address:       cfg.RoutingServiceAddr,
+		SnapshotURL:   snapshotURL,
+		SnapshotToken: snapshotToken,
 	})
 	if err != nil {
 		log.Fatalf("Failed to connect to routing service: %v", err)
@@ -43,8 +80,165 @@ func main() {
 	}
 	log.Printf("gRPC connection verified at %s", cfg.RoutingServiceAddr)
 
+	// Connect to the store and apply the schema
+	store, err := postgres.NewStore(ctx, cfg.DBUrl)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.Migrate(ctx); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	shareService := share_service.NewService(store, cfg.ShareLinkTTL)
+	go shareService.PurgeExpired(jobsCtx, time.Hour)
+
+	if err := auth.CheckSecret(cfg.JWTSecret); err != nil {
+		log.Fatalf("Invalid JWT_SECRET: %v", err)
+	}
+	tokens := auth.NewTokenManager(cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	userService := user_service.NewService(store, tokens)
+	apiKeyService := apikey_service.NewService(store)
+	favoriteService := favorite_service.NewService(store)
+	historyService := history_service.NewService(store)
+
+	// Network edits come from admins or the moderation queue and are pushed to the router
+	serviceArea, err := geo.ParseBBox(cfg.ServiceArea)
+	if err != nil {
+		log.Fatalf("Invalid SERVICE_AREA: %v", err)
+	}
+	networkService := network_service.NewService(store, routingService, serviceArea, cfg.StopDuplicateRadius)
+	go networkService.Sync(jobsCtx)
+	moderationService := moderation_service.NewService(store, networkService)
+
+	rules, fallback, err := ratelimit.ParseRules(cfg.RateLimits)
+	if err != nil {
+		log.Fatalf("Invalid RATE_LIMITS: %v", err)
+	}
+	limiterStore, err := ratelimit.NewStore(cfg.RateLimitBackend, store)
+	if err != nil {
+		log.Fatalf("Invalid RATE_LIMIT_BACKEND: %v", err)
+	}
+	rateLimiter := ratelimit.NewLimiter(limiterStore, rules, fallback)
+	go rateLimiter.PurgeIdle(jobsCtx, 10*time.Minute)
+
+	// Realtime feeds and rider reports annotate the journeys returned by the routing service
+	realtimeState := realtime.NewState(cfg.RealtimeMaxAge)
+	if len(cfg.RealtimeFeedSources) > 0 {
+		go realtime.NewPoller(realtimeState, cfg.RealtimeFeedSources, cfg.RealtimePollInterval).Run(jobsCtx)
+	}
+	crowd := realtime.NewCrowd(cfg.CrowdReportMaxAge)
+	go crowd.Purge(jobsCtx, time.Minute)
+	var router route_service.Router = realtime.NewRouter(routingService, realtimeState, crowd)
+
+	// Walks follow the streets of the OSM extract when one is configured,
+	// submitted traces are map-matched onto the streets vehicles may drive
+	var matcher submission_service.Matcher
+	if cfg.OSMExtractPath != "" {
+		// Scanning a large extract can outlast the startup timeout
+		loadStart := time.Now()
+		walkGraph, driveGraph, err := walking.Load(context.Background(), cfg.OSMExtractPath)
+		if err != nil {
+			log.Fatalf("Failed to load street graphs: %v", err)
+		}
+		log.Printf("Street graphs loaded with %d walk and %d drive nodes in %s",
+			walkGraph.Nodes(), driveGraph.Nodes(), time.Since(loadStart).Round(time.Millisecond))
+		router = walking.NewRouter(router, walkGraph, cfg.WalkingSpeed)
+		matcher = driveGraph
+	}
+	submissionService := submission_service.NewService(store, matcher)
+
+	// Bike, scooter, taxi and ride-hail legs are priced with local models
+	pricing, err := mobility_service.ParseModels(cfg.MobilityPricing)
+	if err != nil {
+		log.Fatalf("Invalid MOBILITY_PRICING: %v", err)
+	}
+	router = mobility_service.NewRouter(router, pricing)
+
+	// Emissions are estimated per leg and compared with driving
+	factors, err := emissions.ParseFactors(cfg.EmissionFactors)
+	if err != nil {
+		log.Fatalf("Invalid EMISSION_FACTORS: %v", err)
+	}
+	router = emissions.NewRouter(router, factors)
+
+	// Fares are computed from the stored fare rules
+	router = fare_service.NewRouter(router, fare_service.NewService(networkService))
+
+	// Journeys a rider with reduced mobility cannot take are dropped
+	router = accessibility_service.NewRouter(router, accessibility_service.NewService(networkService))
+
+	// Service alerts are attached to trip legs and stops
+	alertService := alert_service.NewService(store, realtimeState)
+	router = alert_service.NewRouter(router, alertService)
+
+	// Responses carry the network version they were computed from
+	router = network_service.NewRouter(router, networkService)
+
+	// Multi-stop itineraries are built from pairwise journeys
+	itineraryService := itinerary_service.NewService(router)
+	go itineraryService.Purge(jobsCtx, time.Minute)
+
+	// Reminders and alerts are delivered over the configured channels
+	transports := map[string]notify.Transport{}
+	if cfg.NotifyFakeTransport {
+		transports[notify.ChannelFake] = notify.NewFake()
+	}
+	if cfg.VAPIDPrivateKey != "" {
+		webPush, err := notify.NewWebPush(cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+		if err != nil {
+			log.Fatalf("Invalid VAPID_PRIVATE_KEY: %v", err)
+		}
+		transports[notify.ChannelWebPush] = webPush
+	}
+	if cfg.FCMCredentialsFile != "" {
+		credentials, err := os.ReadFile(cfg.FCMCredentialsFile)
+		if err != nil {
+			log.Fatalf("Failed to read FCM_CREDENTIALS_FILE: %v", err)
+		}
+		fcm, err := notify.NewFCM(credentials)
+		if err != nil {
+			log.Fatalf("Invalid FCM credentials: %v", err)
+		}
+		transports[notify.ChannelFCM] = fcm
+	}
+	if cfg.SMTPAddr != "" {
+		email, err := notify.NewEmail(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword)
+		if err != nil {
+			log.Fatalf("Invalid SMTP settings: %v", err)
+		}
+		transports[notify.ChannelEmail] = email
+	}
+	notificationService := notification_service.NewService(store, transports, alertService, cfg.PublicBaseURL)
+	go notificationService.Run(jobsCtx, cfg.NotifyPollInterval)
+
 	// Create HTTP handler with injected dependencies
-	handler := server.NewHandler(routingService)
+	handler := server.NewHandler(v1.Dependencies{
+		RoutingService:      router,
+		ShareService:        shareService,
+		UserService:         userService,
+		APIKeyService:       apiKeyService,
+		FavoriteService:     favoriteService,
+		HistoryService:      historyService,
+		Tokens:              tokens,
+		RateLimiter:         rateLimiter,
+		RealtimeState:       realtimeState,
+		AlertService:        alertService,
+		ItineraryService:    itineraryService,
+		NotificationService: notificationService,
+		Crowd:               crowd,
+		SubmissionService:   submissionService,
+		ModerationService:   moderationService,
+		NetworkService:      networkService,
+		TrustProxy:          cfg.TrustProxy,
+		SnapshotToken:       snapshotToken,
+		PublicBaseURL:       cfg.PublicBaseURL,
+	})
 
 	// Create server
 	srv := &http.Server{
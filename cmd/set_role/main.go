@@ -0,0 +1,55 @@
+// Command set_role promotes an existing account to admin or demotes it back
+// to a regular user:
+//
+//	go run ./cmd/set_role -email someone@example.com -role admin
+//
+// It reads DB_URL from the environment or .env. The account has to sign in
+// again for the new role to show up in its access token
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/service/user_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the account to update")
+	role := flag.String("role", auth.RoleAdmin, "role to give the account, admin or user")
+	flag.Parse()
+
+	if *email == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	_ = godotenv.Load()
+	dbURL := os.Getenv("DB_URL")
+	if dbURL == "" {
+		log.Fatalf("DB_URL is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	store, err := postgres.NewStore(ctx, dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer store.Close()
+
+	// Tokens are not issued here, the user service only needs the store
+	users := user_service.NewService(store, nil)
+	user, err := users.SetRole(ctx, *email, *role)
+	if err != nil {
+		log.Fatalf("Failed to set role: %v", err)
+	}
+	log.Printf("%s is now %s", user.Email, user.Role)
+}
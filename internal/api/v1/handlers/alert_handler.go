@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/service/alert_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type AlertHandler struct {
+	alertService *alert_service.Service
+}
+
+func NewAlertHandler(alertService *alert_service.Service) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+	}
+}
+
+// Active lists the published and realtime alerts currently in effect
+func (h *AlertHandler) Active(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.alertService.Active(r.Context(), time.Now())
+	if err != nil {
+		log.Printf("Error listing active alerts: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list alerts")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, alerts); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AlertHandler) List(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.alertService.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing alerts: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list alerts")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, alerts); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AlertHandler) Create(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req alert_service.AlertParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	alert, err := h.alertService.Create(r.Context(), principal.UserID, req)
+	if err != nil {
+		writeAlertError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, alert); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AlertHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req alert_service.AlertParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	alert, err := h.alertService.Update(r.Context(), id, req)
+	if err != nil {
+		writeAlertError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, alert); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AlertHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.alertService.Delete(r.Context(), id); err != nil {
+		writeAlertError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAlertError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, alert_service.ErrNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, alert_service.ErrInvalidAlert):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Error saving alert: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save alert")
+	}
+}
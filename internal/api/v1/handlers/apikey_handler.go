@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Marwan051/final_project_backend/internal/service/apikey_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *apikey_service.Service
+}
+
+func NewAPIKeyHandler(apiKeyService *apikey_service.Service) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyService.List(r.Context())
+	if err != nil {
+		log.Printf("Error listing api keys: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, keys); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// Issue creates a key, the response is the only time the secret is shown
+func (h *APIKeyHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	var req apikey_service.IssueParams
+
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	key, err := h.apiKeyService.Issue(r.Context(), req)
+	switch {
+	case errors.Is(err, apikey_service.ErrInvalidRequest), errors.Is(err, apikey_service.ErrInvalidScope),
+		errors.Is(err, apikey_service.ErrUnknownOwner):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		log.Printf("Error issuing api key: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to issue API key")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, key); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *APIKeyHandler) Rotate(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, err := h.apiKeyService.Rotate(r.Context(), id)
+	if errors.Is(err, apikey_service.ErrNotFound) {
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error rotating api key: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to rotate API key")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, key); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, err := h.apiKeyService.Revoke(r.Context(), id)
+	if errors.Is(err, apikey_service.ErrNotFound) {
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error revoking api key: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, key); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/service/user_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type AuthHandler struct {
+	userService *user_service.Service
+}
+
+func NewAuthHandler(userService *user_service.Service) *AuthHandler {
+	return &AuthHandler{
+		userService: userService,
+	}
+}
+
+type RegisterRequest struct {
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.Register(r.Context(), req.Email, req.Password, req.DisplayName)
+	switch {
+	case errors.Is(err, user_service.ErrInvalidEmail), errors.Is(err, auth.ErrPasswordTooShort), errors.Is(err, auth.ErrPasswordTooLong):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, user_service.ErrEmailTaken):
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		log.Printf("Error registering user: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to register")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, user); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	tokens, err := h.userService.Login(r.Context(), req.Email, req.Password)
+	if errors.Is(err, auth.ErrPasswordTooLong) {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, user_service.ErrInvalidCredentials) {
+		utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error logging in: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to log in")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, tokens); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	tokens, err := h.userService.Refresh(r.Context(), req.RefreshToken)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error refreshing token: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, tokens); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	err := h.userService.Logout(r.Context(), req.RefreshToken)
+	if errors.Is(err, auth.ErrInvalidToken) {
+		utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error logging out: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to log out")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me returns the account of the authenticated caller
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	user, err := h.userService.Get(r.Context(), principal.UserID)
+	if errors.Is(err, user_service.ErrNotFound) {
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading user: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load user")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, user); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/service/favorite_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type FavoriteHandler struct {
+	favoriteService *favorite_service.Service
+}
+
+func NewFavoriteHandler(favoriteService *favorite_service.Service) *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteService: favoriteService,
+	}
+}
+
+func (h *FavoriteHandler) ListPlaces(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	places, err := h.favoriteService.ListPlaces(r.Context(), principal.UserID)
+	if err != nil {
+		log.Printf("Error listing places: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list places")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, places); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *FavoriteHandler) CreatePlace(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req favorite_service.PlaceParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	place, err := h.favoriteService.CreatePlace(r.Context(), principal.UserID, req)
+	if err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, place); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *FavoriteHandler) UpdatePlace(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req favorite_service.PlaceParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	place, err := h.favoriteService.UpdatePlace(r.Context(), principal.UserID, id, req)
+	if err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, place); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *FavoriteHandler) DeletePlace(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.favoriteService.DeletePlace(r.Context(), principal.UserID, id); err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *FavoriteHandler) ListProfiles(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	profiles, err := h.favoriteService.ListProfiles(r.Context(), principal.UserID)
+	if err != nil {
+		log.Printf("Error listing profiles: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list profiles")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, profiles); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *FavoriteHandler) CreateProfile(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req favorite_service.ProfileParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	profile, err := h.favoriteService.CreateProfile(r.Context(), principal.UserID, req)
+	if err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, profile); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *FavoriteHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req favorite_service.ProfileParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	profile, err := h.favoriteService.UpdateProfile(r.Context(), principal.UserID, id, req)
+	if err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, profile); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *FavoriteHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.favoriteService.DeleteProfile(r.Context(), principal.UserID, id); err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeFavoriteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, favorite_service.ErrPlaceNotFound), errors.Is(err, favorite_service.ErrProfileNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, favorite_service.ErrInvalidPlace), errors.Is(err, favorite_service.ErrInvalidProfile):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, favorite_service.ErrNameTaken):
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Error saving favorite: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save favorite")
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/service/history_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type HistoryHandler struct {
+	historyService *history_service.Service
+}
+
+func NewHistoryHandler(historyService *history_service.Service) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+	}
+}
+
+// Record stores which journey the user picked out of a route response
+func (h *HistoryHandler) Record(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req history_service.RecordParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	selection, err := h.historyService.Record(r.Context(), principal.UserID, req)
+	if errors.Is(err, history_service.ErrSelectionNotFound) {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error recording selection: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to record selection")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, selection); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *HistoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			utils.WriteJSONError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	selections, err := h.historyService.List(r.Context(), principal.UserID, limit)
+	if err != nil {
+		log.Printf("Error listing selections: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list history")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, selections); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// Weights returns the ranking weights learned from the user's history
+func (h *HistoryHandler) Weights(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	weights, err := h.historyService.Weights(r.Context(), principal.UserID)
+	if err != nil {
+		log.Printf("Error loading learned weights: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load learned weights")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, weights); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Marwan051/final_project_backend/internal/directions"
+	"github.com/Marwan051/final_project_backend/internal/service/itinerary_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type ItineraryHandler struct {
+	itineraryService *itinerary_service.Service
+}
+
+func NewItineraryHandler(itineraryService *itinerary_service.Service) *ItineraryHandler {
+	return &ItineraryHandler{
+		itineraryService: itineraryService,
+	}
+}
+
+// Plan orders the places of a multi-stop trip and returns a journey per hop
+func (h *ItineraryHandler) Plan(w http.ResponseWriter, r *http.Request) {
+	var req itinerary_service.Request
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	itinerary, err := h.itineraryService.Plan(r.Context(), req)
+	switch {
+	case errors.Is(err, itinerary_service.ErrInvalidItinerary):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, itinerary_service.ErrNoFeasibleOrder):
+		utils.WriteJSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	case err != nil:
+		log.Printf("Error planning itinerary: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to plan itinerary")
+		return
+	}
+
+	loc := directions.FromRequest(r)
+	for i := range itinerary.Hops {
+		directions.LocalizeJourney(&itinerary.Hops[i].Journey, loc)
+	}
+	w.Header().Set("Content-Language", loc.Tag())
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, itinerary); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/service/moderation_service"
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type ModerationHandler struct {
+	moderationService *moderation_service.Service
+}
+
+func NewModerationHandler(moderationService *moderation_service.Service) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+type reviewRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+type commentRequest struct {
+	Body string `json:"body"`
+}
+
+// Propose queues a network change for review
+func (h *ModerationHandler) Propose(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req network_service.Change
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	change, err := h.moderationService.Propose(r.Context(), principal.UserID, req)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, change); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// Queue lists change requests, pending ones unless ?status says otherwise
+func (h *ModerationHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	limit, ok := queryLimit(w, r)
+	if !ok {
+		return
+	}
+
+	changes, err := h.moderationService.Queue(r.Context(), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, changes); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// Get returns a change request with its diff and comments
+func (h *ModerationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	review, err := h.moderationService.Get(r.Context(), id)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, review); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *ModerationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.moderationService.Approve)
+}
+
+func (h *ModerationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.moderationService.Reject)
+}
+
+func (h *ModerationHandler) review(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, reviewerID, id int64, comment string) (moderation_service.ChangeRequest, error)) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req reviewRequest
+	if r.ContentLength != 0 {
+		if err := utils.DecodeJSONBody(r, &req); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+	}
+
+	change, err := decide(r.Context(), principal.UserID, id, req.Comment)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, change); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *ModerationHandler) Comment(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req commentRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	comment, err := h.moderationService.Comment(r.Context(), principal.UserID, id, req.Body)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, comment); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// Audit lists the audit trail, filtered by ?entity and ?entity_id
+func (h *ModerationHandler) Audit(w http.ResponseWriter, r *http.Request) {
+	limit, ok := queryLimit(w, r)
+	if !ok {
+		return
+	}
+
+	var entityID int64
+	if v := r.URL.Query().Get("entity_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 1 {
+			utils.WriteJSONError(w, http.StatusBadRequest, "entity_id must be a positive integer")
+			return
+		}
+		entityID = id
+	}
+
+	entries, err := h.moderationService.Audit(r.Context(), r.URL.Query().Get("entity"), entityID, limit)
+	if err != nil {
+		log.Printf("Error listing audit log: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list audit log")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, entries); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// queryLimit parses the optional ?limit, writing the error response itself
+func queryLimit(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return 0, true
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > moderation_service.MaxQueueLimit {
+		utils.WriteJSONError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+		return 0, false
+	}
+	return int32(n), true
+}
+
+func writeModerationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, moderation_service.ErrNotFound), errors.Is(err, network_service.ErrNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, network_service.ErrInvalidChange), errors.Is(err, moderation_service.ErrInvalidComment),
+		errors.Is(err, moderation_service.ErrInvalidStatus):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, moderation_service.ErrAlreadyReviewed):
+		utils.WriteJSONError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Error moderating change: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to process change request")
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type NetworkHandler struct {
+	networkService *network_service.Service
+}
+
+func NewNetworkHandler(networkService *network_service.Service) *NetworkHandler {
+	return &NetworkHandler{
+		networkService: networkService,
+	}
+}
+
+// editResponse is the entity after a direct edit and the version it produced
+type editResponse struct {
+	Entity         any   `json:"entity,omitempty"`
+	NetworkVersion int64 `json:"network_version"`
+}
+
+type mergeStopsRequest struct {
+	StopIDs []int64 `json:"stop_ids"`
+}
+
+// List returns a handler listing every entity of a kind
+func (h *NetworkHandler) List(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entities, err := h.networkService.List(r.Context(), entity)
+		if err != nil {
+			log.Printf("Error listing %s: %v", entity, err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list network")
+			return
+		}
+
+		if err := utils.WriteJSONResponse(w, http.StatusOK, entities); err != nil {
+			log.Printf("Error encoding response: %v", err)
+		}
+	}
+}
+
+// Create returns a handler creating an entity of a kind from the body
+func (h *NetworkHandler) Create(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var payload json.RawMessage
+		if err := utils.DecodeJSONBody(r, &payload); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+
+		h.edit(w, r, http.StatusCreated, network_service.Change{
+			Entity:  entity,
+			Action:  network_service.ActionCreate,
+			Payload: payload,
+		})
+	}
+}
+
+// Update returns a handler updating the fields in the body of an entity
+func (h *NetworkHandler) Update(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := utils.PathInt64(r, "id")
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var payload json.RawMessage
+		if err := utils.DecodeJSONBody(r, &payload); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+
+		h.edit(w, r, http.StatusOK, network_service.Change{
+			Entity:   entity,
+			Action:   network_service.ActionUpdate,
+			EntityID: id,
+			Payload:  payload,
+		})
+	}
+}
+
+// Delete returns a handler deleting an entity of a kind
+func (h *NetworkHandler) Delete(entity string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := utils.PathInt64(r, "id")
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.edit(w, r, http.StatusOK, network_service.Change{
+			Entity:   entity,
+			Action:   network_service.ActionDelete,
+			EntityID: id,
+		})
+	}
+}
+
+// MergeStops folds the stops in the body into the one in the path
+func (h *NetworkHandler) MergeStops(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req mergeStopsRequest
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	stop, version, err := h.networkService.MergeStops(r.Context(), principal.UserID, id, req.StopIDs)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, editResponse{Entity: stop, NetworkVersion: version}); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NetworkHandler) edit(w http.ResponseWriter, r *http.Request, status int, change network_service.Change) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	entity, version, err := h.networkService.Edit(r.Context(), principal.UserID, change)
+	if err != nil {
+		writeModerationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, status, editResponse{Entity: entity, NetworkVersion: version}); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// Version reports the published network version and the one being served
+func (h *NetworkHandler) Version(w http.ResponseWriter, r *http.Request) {
+	version, err := h.networkService.Version(r.Context())
+	if err != nil {
+		log.Printf("Error loading network version: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load network version")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, version); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// Snapshot returns the current network for routers to load
+func (h *NetworkHandler) Snapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.networkService.Snapshot(r.Context())
+	if err != nil {
+		log.Printf("Error loading network snapshot: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load network snapshot")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, snapshot); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
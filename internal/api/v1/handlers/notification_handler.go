@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/directions"
+	"github.com/Marwan051/final_project_backend/internal/service/notification_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+type NotificationHandler struct {
+	notificationService *notification_service.Service
+}
+
+func NewNotificationHandler(notificationService *notification_service.Service) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+type VAPIDKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// VAPIDKey returns the key browsers pass to PushManager.subscribe
+func (h *NotificationHandler) VAPIDKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.notificationService.VAPIDPublicKey()
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, VAPIDKeyResponse{PublicKey: key}); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NotificationHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req notification_service.SubscriptionParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	sub, err := h.notificationService.Subscribe(r.Context(), principal.UserID, req)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, sub); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// ConfirmSubscription is the link mailed to new email targets
+func (h *NotificationHandler) ConfirmSubscription(w http.ResponseWriter, r *http.Request) {
+	if err := h.notificationService.ConfirmSubscription(r.Context(), r.URL.Query().Get("token")); err != nil {
+		if errors.Is(err, notification_service.ErrNotFound) {
+			utils.WriteJSONError(w, http.StatusNotFound, "Confirmation link is invalid or was already used")
+			return
+		}
+		log.Printf("Error confirming subscription: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to confirm subscription")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	subs, err := h.notificationService.ListSubscriptions(r.Context(), principal.UserID)
+	if err != nil {
+		log.Printf("Error listing subscriptions: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list subscriptions")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, subs); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NotificationHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.notificationService.Unsubscribe(r.Context(), principal.UserID, id); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req notification_service.ReminderParams
+	if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	reminder, err := h.notificationService.CreateReminder(r.Context(), principal.UserID, req, directions.FromRequest(r))
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, reminder); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NotificationHandler) ListReminders(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	reminders, err := h.notificationService.ListReminders(r.Context(), principal.UserID)
+	if err != nil {
+		log.Printf("Error listing reminders: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list reminders")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, reminders); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *NotificationHandler) DeleteReminder(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.notificationService.DeleteReminder(r.Context(), principal.UserID, id); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeNotificationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, notification_service.ErrNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, notification_service.ErrInvalidSubscription), errors.Is(err, notification_service.ErrInvalidReminder):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, notification_service.ErrTooManyUnconfirmed):
+		utils.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		log.Printf("Error saving notification settings: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save notification settings")
+	}
+}
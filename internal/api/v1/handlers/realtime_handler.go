@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/realtime"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+// maxPushedFeedSize caps feeds pushed by producers
+const maxPushedFeedSize = 32 * 1024 * 1024
+
+type RealtimeHandler struct {
+	state *realtime.State
+	crowd *realtime.Crowd
+}
+
+func NewRealtimeHandler(state *realtime.State, crowd *realtime.Crowd) *RealtimeHandler {
+	return &RealtimeHandler{
+		state: state,
+		crowd: crowd,
+	}
+}
+
+// Status summarizes the realtime data currently held in memory
+func (h *RealtimeHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if err := utils.WriteJSONResponse(w, http.StatusOK, h.state.Status()); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// PushFeed accepts a GTFS-RT feed in the request body, protobuf by default
+// or JSON when sent with a JSON content type. Producers pushing several feeds
+// name each with the source query parameter, a full dataset only replaces
+// what the same source pushed before
+func (h *RealtimeHandler) PushFeed(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPushedFeedSize))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	feed, err := realtime.Decode(data, strings.Contains(r.Header.Get("Content-Type"), "json"))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.state.Apply("push:"+r.URL.Query().Get("source"), feed)
+
+	if err := utils.WriteJSONResponse(w, http.StatusAccepted, h.state.Status()); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// ReportPosition records the GPS position of a rider on a trip
+func (h *RealtimeHandler) ReportPosition(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var report realtime.PositionReport
+	if err := utils.DecodeJSONBody(r, &report); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	report.TripID = r.PathValue("trip_id")
+	report.Reporter = fmt.Sprintf("user:%d", principal.UserID)
+
+	if err := h.crowd.Report(report); err != nil {
+		if errors.Is(err, realtime.ErrInvalidReport) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error recording position: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to record position")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// TripVehicles returns the estimated live vehicles and headway of a trip
+func (h *RealtimeHandler) TripVehicles(w http.ResponseWriter, r *http.Request) {
+	if err := utils.WriteJSONResponse(w, http.StatusOK, h.crowd.Trip(r.PathValue("trip_id"))); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -1,21 +1,31 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/directions"
+	"github.com/Marwan051/final_project_backend/internal/ranking"
+	"github.com/Marwan051/final_project_backend/internal/service/favorite_service"
+	"github.com/Marwan051/final_project_backend/internal/service/history_service"
 	"github.com/Marwan051/final_project_backend/internal/service/route_service"
 	"github.com/Marwan051/final_project_backend/internal/utils"
 )
 
 type RoutingHandler struct {
-	routerService route_service.Router
+	routerService   route_service.Router
+	favoriteService *favorite_service.Service
+	historyService  *history_service.Service
 }
 
 // Constructor accepts the interface
-func NewRoutingHandler(router route_service.Router) *RoutingHandler {
+func NewRoutingHandler(router route_service.Router, favoriteService *favorite_service.Service, historyService *history_service.Service) *RoutingHandler {
 	return &RoutingHandler{
-		routerService: router,
+		routerService:   router,
+		favoriteService: favoriteService,
+		historyService:  historyService,
 	}
 }
 
@@ -27,11 +37,37 @@ func (h *RoutingHandler) FindRoute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	signedIn := ok && !principal.IsAPIKey()
+
+	// Resolve saved places and profiles of the signed-in user
+	if req.UsesFavorites() {
+		if !signedIn {
+			utils.WriteJSONError(w, http.StatusUnauthorized, "Sign in to use saved places and profiles")
+			return
+		}
+
+		err := h.favoriteService.Resolve(r.Context(), principal.UserID, &req)
+		switch {
+		case errors.Is(err, favorite_service.ErrPlaceNotFound), errors.Is(err, favorite_service.ErrProfileNotFound):
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		case err != nil:
+			log.Printf("Error resolving favorites: %v", err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load saved places")
+			return
+		}
+	}
+
 	// Validate required fields
 	if req.StartLat == 0 || req.StartLon == 0 || req.EndLat == 0 || req.EndLon == 0 {
 		utils.WriteJSONError(w, http.StatusBadRequest, "Missing required coordinates")
 		return
 	}
+	if err := req.Validate(); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Call the routing service
 	resp, err := h.routerService.FindRoute(r.Context(), req)
@@ -40,6 +76,22 @@ func (h *RoutingHandler) FindRoute(w http.ResponseWriter, r *http.Request) {
 		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to find route")
 		return
 	}
+	// Compare journeys only once fares, emissions and walks are final
+	ranking.Prune(&resp, req.Debug)
+
+	// Personalize the ranking for signed-in users, the request's weights still apply on failure
+	if signedIn {
+		if err := h.historyService.Rerank(r.Context(), principal.UserID, req, &resp); err != nil {
+			log.Printf("Error re-ranking journeys: %v", err)
+		}
+	}
+	if !resp.Personalized {
+		ranking.Order(&resp, ranking.RequestWeights(req))
+	}
+
+	loc := directions.FromRequest(r)
+	directions.Localize(resp.Journeys, loc)
+	w.Header().Set("Content-Language", loc.Tag())
 
 	// Return JSON response
 	if err := utils.WriteJSONResponse(w, http.StatusOK, resp); err != nil {
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/directions"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/service/share_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+const (
+	// maxShareBody bounds a shared journey, leg paths included
+	maxShareBody = 64 * 1024
+	// maxShareLegs bounds the legs of a shared journey
+	maxShareLegs = 30
+)
+
+//go:embed templates/shared_journey.html
+var templatesFS embed.FS
+
+var sharedJourneyTmpl = template.Must(template.ParseFS(templatesFS, "templates/shared_journey.html"))
+
+type ShareHandler struct {
+	shareService *share_service.Service
+	baseURL      string
+}
+
+func NewShareHandler(shareService *share_service.Service, baseURL string) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+type ShareResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateShare persists a journey chosen by the client and returns its short link
+func (h *ShareHandler) CreateShare(w http.ResponseWriter, r *http.Request) {
+	var journey route_service.Journey
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxShareBody)
+	if err := utils.DecodeJSONBody(r, &journey); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	if len(journey.Legs) == 0 || len(journey.Legs) > maxShareLegs {
+		utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Journey must have between 1 and %d legs", maxShareLegs))
+		return
+	}
+
+	shared, err := h.shareService.Save(r.Context(), journey)
+	if err != nil {
+		log.Printf("Error sharing journey: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to share journey")
+		return
+	}
+
+	resp := ShareResponse{
+		ID:        shared.ID,
+		URL:       h.link(shared.ID),
+		ExpiresAt: shared.ExpiresAt,
+	}
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, resp); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// GetShare serves a shared journey as JSON, GeoJSON or an HTML preview.
+// The format is taken from ?format= and falls back to the Accept header,
+// then to JSON
+func (h *ShareHandler) GetShare(w http.ResponseWriter, r *http.Request) {
+	h.serveShare(w, r, "json")
+}
+
+// GetLink serves the short link like GetShare but falls back to the HTML
+// preview, link preview crawlers send no Accept header or */*
+func (h *ShareHandler) GetLink(w http.ResponseWriter, r *http.Request) {
+	h.serveShare(w, r, "html")
+}
+
+func (h *ShareHandler) serveShare(w http.ResponseWriter, r *http.Request, fallback string) {
+	shared, err := h.shareService.Get(r.Context(), r.PathValue("id"))
+	if errors.Is(err, share_service.ErrNotFound) {
+		utils.WriteJSONError(w, http.StatusNotFound, "Journey not found or expired")
+		return
+	}
+	if err != nil {
+		log.Printf("Error loading shared journey: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to load journey")
+		return
+	}
+
+	switch negotiateFormat(r, fallback) {
+	case "geojson":
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(shared.Journey.GeoJSON()); err != nil {
+			log.Printf("Error encoding response: %v", err)
+		}
+	case "html":
+		h.writePreview(w, r, shared)
+	default:
+		if err := utils.WriteJSONResponse(w, http.StatusOK, shared); err != nil {
+			log.Printf("Error encoding response: %v", err)
+		}
+	}
+}
+
+func (h *ShareHandler) link(id string) string {
+	return h.baseURL + "/j/" + id
+}
+
+func (h *ShareHandler) writePreview(w http.ResponseWriter, r *http.Request, shared share_service.SharedJourney) {
+	loc := directions.FromRequest(r)
+	preview := directions.SharePreview(&shared.Journey, shared.ExpiresAt, loc)
+
+	data := struct {
+		directions.Preview
+		URL string
+	}{
+		Preview: preview,
+		URL:     h.link(shared.ID),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Language", preview.Lang)
+	w.WriteHeader(http.StatusOK)
+	if err := sharedJourneyTmpl.Execute(w, data); err != nil {
+		log.Printf("Error rendering preview: %v", err)
+	}
+}
+
+func negotiateFormat(r *http.Request, fallback string) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/geo+json"):
+		return "geojson"
+	case strings.Contains(accept, "text/html"):
+		return "html"
+	case strings.Contains(accept, "application/json"):
+		return "json"
+	default:
+		return fallback
+	}
+}
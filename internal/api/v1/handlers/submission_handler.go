@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/service/submission_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
+)
+
+// maxGPXSize caps raw GPX uploads
+const maxGPXSize = 10 * 1024 * 1024
+
+type SubmissionHandler struct {
+	submissionService *submission_service.Service
+}
+
+func NewSubmissionHandler(submissionService *submission_service.Service) *SubmissionHandler {
+	return &SubmissionHandler{
+		submissionService: submissionService,
+	}
+}
+
+// Submit accepts a JSON body with gpx or points, or a raw GPX body with the
+// route details in the query string
+func (h *SubmissionHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req submission_service.SubmissionParams
+	if strings.Contains(r.Header.Get("Content-Type"), "xml") {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGPXSize))
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+
+		q := r.URL.Query()
+		req = submission_service.SubmissionParams{
+			Name:                q.Get("name"),
+			Mode:                q.Get("mode"),
+			OriginTerminal:      q.Get("origin_terminal"),
+			DestinationTerminal: q.Get("destination_terminal"),
+			GPX:                 string(data),
+		}
+		if v := q.Get("fare"); v != "" {
+			fare, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, "fare must be a number")
+				return
+			}
+			req.Fare = &fare
+		}
+	} else if err := utils.DecodeJSONBody(r, &req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	submission, err := h.submissionService.Submit(r.Context(), principal.UserID, req)
+	if err != nil {
+		writeSubmissionError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusCreated, submission); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// Get returns a submission to its author or an admin
+func (h *SubmissionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	id, err := utils.PathInt64(r, "id")
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	submission, err := h.submissionService.Get(r.Context(), id)
+	if err == nil && submission.SubmittedBy != principal.UserID && principal.Role != auth.RoleAdmin {
+		err = submission_service.ErrNotFound
+	}
+	if err != nil {
+		writeSubmissionError(w, err)
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, submission); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func (h *SubmissionHandler) ListMine(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	submissions, err := h.submissionService.ListByUser(r.Context(), principal.UserID)
+	if err != nil {
+		log.Printf("Error listing route submissions: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to list route submissions")
+		return
+	}
+
+	if err := utils.WriteJSONResponse(w, http.StatusOK, submissions); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+func writeSubmissionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, submission_service.ErrNotFound):
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, submission_service.ErrInvalidSubmission):
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Error saving route submission: %v", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save route submission")
+	}
+}
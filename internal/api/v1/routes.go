@@ -5,22 +5,164 @@ import (
 	"time"
 
 	"github.com/Marwan051/final_project_backend/internal/api/v1/handlers"
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/ratelimit"
+	"github.com/Marwan051/final_project_backend/internal/realtime"
+	"github.com/Marwan051/final_project_backend/internal/service/alert_service"
+	"github.com/Marwan051/final_project_backend/internal/service/apikey_service"
+	"github.com/Marwan051/final_project_backend/internal/service/favorite_service"
+	"github.com/Marwan051/final_project_backend/internal/service/history_service"
+	"github.com/Marwan051/final_project_backend/internal/service/itinerary_service"
+	"github.com/Marwan051/final_project_backend/internal/service/moderation_service"
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/service/notification_service"
 	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/service/share_service"
+	"github.com/Marwan051/final_project_backend/internal/service/submission_service"
+	"github.com/Marwan051/final_project_backend/internal/service/user_service"
 	"github.com/Marwan051/final_project_backend/internal/utils"
 )
 
+// Dependencies holds the services injected into the v1 handlers
+type Dependencies struct {
+	RoutingService      route_service.Router
+	ShareService        *share_service.Service
+	UserService         *user_service.Service
+	APIKeyService       *apikey_service.Service
+	FavoriteService     *favorite_service.Service
+	HistoryService      *history_service.Service
+	Tokens              *auth.TokenManager
+	RateLimiter         *ratelimit.Limiter
+	RealtimeState       *realtime.State
+	Crowd               *realtime.Crowd
+	SubmissionService   *submission_service.Service
+	ModerationService   *moderation_service.Service
+	NetworkService      *network_service.Service
+	AlertService        *alert_service.Service
+	ItineraryService    *itinerary_service.Service
+	NotificationService *notification_service.Service
+	SnapshotToken       string
+	PublicBaseURL       string
+	TrustProxy          bool
+}
+
 // NewRouter returns a new router with all v1 API routes
-func NewRouter(routingService route_service.Router) *http.ServeMux {
+func NewRouter(deps Dependencies) *http.ServeMux {
 	mux := http.NewServeMux()
 
-	// Create handler with the injected service
-	routingHandler := handlers.NewRoutingHandler(routingService)
+	// Create handlers with the injected services
+	routingHandler := handlers.NewRoutingHandler(deps.RoutingService, deps.FavoriteService, deps.HistoryService)
+	shareHandler := handlers.NewShareHandler(deps.ShareService, deps.PublicBaseURL)
+	authHandler := handlers.NewAuthHandler(deps.UserService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(deps.APIKeyService)
+	favoriteHandler := handlers.NewFavoriteHandler(deps.FavoriteService)
+	historyHandler := handlers.NewHistoryHandler(deps.HistoryService)
+	realtimeHandler := handlers.NewRealtimeHandler(deps.RealtimeState, deps.Crowd)
+	alertHandler := handlers.NewAlertHandler(deps.AlertService)
+	submissionHandler := handlers.NewSubmissionHandler(deps.SubmissionService)
+	moderationHandler := handlers.NewModerationHandler(deps.ModerationService)
+	networkHandler := handlers.NewNetworkHandler(deps.NetworkService)
+	itineraryHandler := handlers.NewItineraryHandler(deps.ItineraryService)
+	notificationHandler := handlers.NewNotificationHandler(deps.NotificationService)
 
 	// Health check
 	mux.HandleFunc("GET /health", HealthHandler)
 
 	// Routing endpoint
-	mux.HandleFunc("POST /route", routingHandler.FindRoute)
+	mux.HandleFunc("POST /route", auth.RequireScope(auth.ScopeRoute, routingHandler.FindRoute))
+
+	// Multi-stop itineraries
+	mux.HandleFunc("POST /itinerary", auth.RequireScope(auth.ScopeRoute, itineraryHandler.Plan))
+
+	// Shared journeys
+	mux.HandleFunc("POST /journeys/share", auth.RequireScope(auth.ScopeShare, shareHandler.CreateShare))
+	mux.HandleFunc("GET /journeys/share/{id}", auth.RequireScope(auth.ScopeShare, shareHandler.GetShare))
+	mux.HandleFunc("GET /j/{id}", shareHandler.GetLink)
+
+	// Accounts
+	mux.HandleFunc("POST /auth/register", authHandler.Register)
+	mux.HandleFunc("POST /auth/login", authHandler.Login)
+	mux.HandleFunc("POST /auth/refresh", authHandler.Refresh)
+	mux.HandleFunc("POST /auth/logout", authHandler.Logout)
+	mux.HandleFunc("GET /me", auth.RequireAuth(authHandler.Me))
+
+	// Favorite places and commute profiles
+	mux.HandleFunc("GET /me/places", auth.RequireAuth(favoriteHandler.ListPlaces))
+	mux.HandleFunc("POST /me/places", auth.RequireAuth(favoriteHandler.CreatePlace))
+	mux.HandleFunc("PUT /me/places/{id}", auth.RequireAuth(favoriteHandler.UpdatePlace))
+	mux.HandleFunc("DELETE /me/places/{id}", auth.RequireAuth(favoriteHandler.DeletePlace))
+	mux.HandleFunc("GET /me/profiles", auth.RequireAuth(favoriteHandler.ListProfiles))
+	mux.HandleFunc("POST /me/profiles", auth.RequireAuth(favoriteHandler.CreateProfile))
+	mux.HandleFunc("PUT /me/profiles/{id}", auth.RequireAuth(favoriteHandler.UpdateProfile))
+	mux.HandleFunc("DELETE /me/profiles/{id}", auth.RequireAuth(favoriteHandler.DeleteProfile))
+
+	// Trip history and learned ranking weights
+	mux.HandleFunc("GET /me/history", auth.RequireAuth(historyHandler.List))
+	mux.HandleFunc("POST /me/history", auth.RequireAuth(historyHandler.Record))
+	mux.HandleFunc("GET /me/ranking-weights", auth.RequireAuth(historyHandler.Weights))
+
+	// Notification subscriptions and departure reminders
+	mux.HandleFunc("GET /notifications/vapid-key", notificationHandler.VAPIDKey)
+	mux.HandleFunc("GET /notifications/confirm", notificationHandler.ConfirmSubscription)
+	mux.HandleFunc("GET /me/notification-subscriptions", auth.RequireAuth(notificationHandler.ListSubscriptions))
+	mux.HandleFunc("POST /me/notification-subscriptions", auth.RequireAuth(notificationHandler.Subscribe))
+	mux.HandleFunc("DELETE /me/notification-subscriptions/{id}", auth.RequireAuth(notificationHandler.Unsubscribe))
+	mux.HandleFunc("GET /me/reminders", auth.RequireAuth(notificationHandler.ListReminders))
+	mux.HandleFunc("POST /me/reminders", auth.RequireAuth(notificationHandler.CreateReminder))
+	mux.HandleFunc("DELETE /me/reminders/{id}", auth.RequireAuth(notificationHandler.DeleteReminder))
+
+	// Realtime feeds
+	mux.HandleFunc("GET /realtime/status", realtimeHandler.Status)
+	mux.HandleFunc("POST /admin/realtime/feed", auth.RequireRole(auth.RoleAdmin, realtimeHandler.PushFeed))
+
+	// Crowdsourced vehicle positions
+	mux.HandleFunc("POST /trips/{trip_id}/positions", auth.RequireAuth(realtimeHandler.ReportPosition))
+	mux.HandleFunc("GET /trips/{trip_id}/vehicles", realtimeHandler.TripVehicles)
+
+	// Crowdsourced route submissions
+	mux.HandleFunc("POST /route-submissions", auth.RequireAuth(submissionHandler.Submit))
+	mux.HandleFunc("GET /route-submissions/{id}", auth.RequireAuth(submissionHandler.Get))
+	mux.HandleFunc("GET /me/route-submissions", auth.RequireAuth(submissionHandler.ListMine))
+
+	// Network edits and their moderation
+	mux.HandleFunc("POST /network/changes", auth.RequireAuth(moderationHandler.Propose))
+	mux.HandleFunc("GET /admin/changes", auth.RequireRole(auth.RoleAdmin, moderationHandler.Queue))
+	mux.HandleFunc("GET /admin/changes/{id}", auth.RequireRole(auth.RoleAdmin, moderationHandler.Get))
+	mux.HandleFunc("POST /admin/changes/{id}/approve", auth.RequireRole(auth.RoleAdmin, moderationHandler.Approve))
+	mux.HandleFunc("POST /admin/changes/{id}/reject", auth.RequireRole(auth.RoleAdmin, moderationHandler.Reject))
+	mux.HandleFunc("POST /admin/changes/{id}/comments", auth.RequireRole(auth.RoleAdmin, moderationHandler.Comment))
+	mux.HandleFunc("GET /admin/audit", auth.RequireRole(auth.RoleAdmin, moderationHandler.Audit))
+
+	// Network versions
+	mux.HandleFunc("GET /network/version", networkHandler.Version)
+	mux.HandleFunc("GET /network/snapshot", auth.RequireSecret(auth.SnapshotTokenHeader, deps.SnapshotToken, networkHandler.Snapshot))
+
+	// Admin: direct network edits
+	for path, entity := range map[string]string{
+		"stops":         network_service.EntityStop,
+		"routes":        network_service.EntityRoute,
+		"trip-patterns": network_service.EntityTripPattern,
+		"fares":         network_service.EntityFare,
+	} {
+		mux.HandleFunc("GET /admin/"+path, auth.RequireRole(auth.RoleAdmin, networkHandler.List(entity)))
+		mux.HandleFunc("POST /admin/"+path, auth.RequireRole(auth.RoleAdmin, networkHandler.Create(entity)))
+		mux.HandleFunc("PUT /admin/"+path+"/{id}", auth.RequireRole(auth.RoleAdmin, networkHandler.Update(entity)))
+		mux.HandleFunc("DELETE /admin/"+path+"/{id}", auth.RequireRole(auth.RoleAdmin, networkHandler.Delete(entity)))
+	}
+	mux.HandleFunc("POST /admin/stops/{id}/merge", auth.RequireRole(auth.RoleAdmin, networkHandler.MergeStops))
+
+	// Service alerts
+	mux.HandleFunc("GET /alerts", alertHandler.Active)
+	mux.HandleFunc("GET /admin/alerts", auth.RequireRole(auth.RoleAdmin, alertHandler.List))
+	mux.HandleFunc("POST /admin/alerts", auth.RequireRole(auth.RoleAdmin, alertHandler.Create))
+	mux.HandleFunc("PUT /admin/alerts/{id}", auth.RequireRole(auth.RoleAdmin, alertHandler.Update))
+	mux.HandleFunc("DELETE /admin/alerts/{id}", auth.RequireRole(auth.RoleAdmin, alertHandler.Delete))
+
+	// Admin: API keys
+	mux.HandleFunc("GET /admin/api-keys", auth.RequireRole(auth.RoleAdmin, apiKeyHandler.List))
+	mux.HandleFunc("POST /admin/api-keys", auth.RequireRole(auth.RoleAdmin, apiKeyHandler.Issue))
+	mux.HandleFunc("POST /admin/api-keys/{id}/rotate", auth.RequireRole(auth.RoleAdmin, apiKeyHandler.Rotate))
+	mux.HandleFunc("DELETE /admin/api-keys/{id}", auth.RequireRole(auth.RoleAdmin, apiKeyHandler.Revoke))
 
 	return mux
 }
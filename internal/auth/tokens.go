@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+	issuer           = "routing_app_backend"
+)
+
+// MinSecretLength is the shortest signing secret accepted, 32 bytes match
+// the output size of HS256
+const MinSecretLength = 32
+
+var (
+	ErrInvalidToken = errors.New("invalid or expired token")
+	ErrWeakSecret   = errors.New("signing secret is too weak")
+)
+
+// placeholderSecrets are sample values that must never sign real tokens
+var placeholderSecrets = []string{"change-me", "changeme", "secret"}
+
+// CheckSecret rejects signing secrets that are short or left at a sample value
+func CheckSecret(secret string) error {
+	if slices.Contains(placeholderSecrets, strings.ToLower(secret)) {
+		return fmt.Errorf("%w: the sample value must be replaced", ErrWeakSecret)
+	}
+	if len(secret) < MinSecretLength {
+		return fmt.Errorf("%w: it must be at least %d bytes, generate one with 'openssl rand -hex 32'", ErrWeakSecret, MinSecretLength)
+	}
+	return nil
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Type  string `json:"typ"`
+	Email string `json:"email,omitempty"`
+	Role  string `json:"role,omitempty"`
+}
+
+// RefreshToken is a signed refresh token and the ID it is tracked under in the store
+type RefreshToken struct {
+	ID        string
+	Token     string
+	UserID    int64
+	ExpiresAt time.Time
+}
+
+// TokenManager issues and verifies HS256 signed access and refresh tokens
+type TokenManager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenManager(secret string, accessTTL, refreshTTL time.Duration) *TokenManager {
+	if accessTTL == 0 {
+		accessTTL = DefaultAccessTTL
+	}
+	if refreshTTL == 0 {
+		refreshTTL = DefaultRefreshTTL
+	}
+	return &TokenManager{
+		secret:     []byte(secret),
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// IssueAccessToken returns a short lived token carrying the principal
+func (m *TokenManager) IssueAccessToken(p Principal) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.accessTTL)
+
+	token, err := m.sign(claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   strconv.FormatInt(p.UserID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Type:  tokenTypeAccess,
+		Email: p.Email,
+		Role:  p.Role,
+	})
+	return token, expiresAt, err
+}
+
+// IssueRefreshToken returns a long lived token, callers must persist its ID
+// so it can be rotated and revoked
+func (m *TokenManager) IssueRefreshToken(userID int64) (RefreshToken, error) {
+	id, err := randomID()
+	if err != nil {
+		return RefreshToken{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(m.refreshTTL)
+
+	token, err := m.sign(claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Issuer:    issuer,
+			Subject:   strconv.FormatInt(userID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Type: tokenTypeRefresh,
+	})
+	if err != nil {
+		return RefreshToken{}, err
+	}
+
+	return RefreshToken{
+		ID:        id,
+		Token:     token,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ParseAccessToken verifies an access token and returns its principal
+func (m *TokenManager) ParseAccessToken(token string) (Principal, error) {
+	c, err := m.parse(token, tokenTypeAccess)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	userID, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil {
+		return Principal{}, ErrInvalidToken
+	}
+
+	return Principal{
+		UserID: userID,
+		Email:  c.Email,
+		Role:   c.Role,
+	}, nil
+}
+
+// ParseRefreshToken verifies a refresh token, the caller must still check
+// that its ID has not been revoked
+func (m *TokenManager) ParseRefreshToken(token string) (RefreshToken, error) {
+	c, err := m.parse(token, tokenTypeRefresh)
+	if err != nil {
+		return RefreshToken{}, err
+	}
+
+	userID, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || c.ID == "" {
+		return RefreshToken{}, ErrInvalidToken
+	}
+
+	return RefreshToken{
+		ID:        c.ID,
+		Token:     token,
+		UserID:    userID,
+		ExpiresAt: c.ExpiresAt.Time,
+	}, nil
+}
+
+func (m *TokenManager) sign(c claims) (string, error) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(m.secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return token, nil
+}
+
+func (m *TokenManager) parse(token, tokenType string) (*claims, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) {
+		return m.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || c.Type != tokenType {
+		return nil, ErrInvalidToken
+	}
+	return &c, nil
+}
+
+func randomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
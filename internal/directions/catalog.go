@@ -0,0 +1,157 @@
+package directions
+
+import "github.com/Marwan051/final_project_backend/internal/service/route_service"
+
+// plural holds the forms of a counted phrase, %s is the number. Forms left
+// empty fall back to other
+type plural struct {
+	zero, one, two, few, many, other string
+}
+
+// catalog is the text of one language
+type catalog struct {
+	rtl bool
+
+	walk     string
+	trip     string
+	transfer string
+	ride     string
+	summary  string
+	// overview and fare describe a shared journey, expires says until when
+	// its link works with the date in the date layout
+	overview string
+	fare     string
+	expires  string
+	date     string
+	// leave, leaveIn and leaveNow remind riders to set off, affects names
+	// the route an alert applies to
+	leave    string
+	leaveIn  string
+	leaveNow string
+	affects  string
+
+	// sep joins the parts of a summary, arrow joins its rides
+	sep   string
+	arrow string
+
+	minutes   plural
+	transfers plural
+	meters    string
+	km        string
+	currency  string
+
+	modes map[string]string
+}
+
+var catalogs = map[string]*catalog{
+	English: {
+		walk:     "Walk %[1]s (%[2]s)",
+		trip:     "Take %[1]s %[2]s towards %[3]s from %[4]s to %[5]s (%[6]s)",
+		transfer: "Transfer from %[1]s to %[2]s, walk %[3]s (%[4]s)",
+		ride:     "Take a %[1]s for %[2]s (%[3]s, %[4]s)",
+		summary:  "%[1]s, %[2]s, %[3]s, %[4]s",
+		overview: "%[1]s, %[2]s, %[3]s walking",
+		fare:     "fare %s",
+		expires:  "This link expires on %s.",
+		date:     "2 Jan 2006",
+		leave:    "Time to leave",
+		leaveIn:  "Leave in %s",
+		leaveNow: "Leave now",
+		affects:  "This affects route %s on your trip",
+		sep:      ", ",
+		arrow:    " → ",
+		minutes:  plural{one: "%s min", other: "%s min"},
+		transfers: plural{
+			zero:  "no transfers",
+			one:   "%s transfer",
+			other: "%s transfers",
+		},
+		meters:   "%s m",
+		km:       "%s km",
+		currency: "EGP %s",
+		modes: map[string]string{
+			"bus":                     "bus",
+			"microbus":                "microbus",
+			"minibus":                 "minibus",
+			"metro":                   "metro",
+			"tram":                    "tram",
+			"train":                   "train",
+			"ferry":                   "ferry",
+			route_service.LegWalk:     "walk",
+			route_service.LegBike:     "bike",
+			route_service.LegScooter:  "scooter",
+			route_service.LegTaxi:     "taxi",
+			route_service.LegRideHail: "ride-hail car",
+		},
+	},
+	Arabic: {
+		rtl:      true,
+		walk:     "امشِ %[1]s (%[2]s)",
+		trip:     "اركب %[1]s %[2]s باتجاه %[3]s من %[4]s إلى %[5]s (%[6]s)",
+		transfer: "بدّل من %[1]s إلى %[2]s، امشِ %[3]s (%[4]s)",
+		ride:     "اركب %[1]s لمسافة %[2]s (%[3]s، %[4]s)",
+		summary:  "%[1]s، %[2]s، %[3]s، %[4]s",
+		overview: "%[1]s، %[2]s، مشي %[3]s",
+		fare:     "الأجرة %s",
+		expires:  "تنتهي صلاحية هذا الرابط في %s.",
+		date:     "2/1/2006",
+		leave:    "حان وقت الخروج",
+		leaveIn:  "اخرج خلال %s",
+		leaveNow: "اخرج الآن",
+		affects:  "يؤثر هذا على الخط %s في رحلتك",
+		sep:      "، ",
+		arrow:    " ← ",
+		minutes: plural{
+			one:   "دقيقة واحدة",
+			two:   "دقيقتان",
+			few:   "%s دقائق",
+			other: "%s دقيقة",
+		},
+		transfers: plural{
+			zero:  "بدون تبديل",
+			one:   "تبديل واحد",
+			two:   "تبديلان",
+			few:   "%s تبديلات",
+			other: "%s تبديل",
+		},
+		meters:   "%s م",
+		km:       "%s كم",
+		currency: "%s ج.م",
+		modes: map[string]string{
+			"bus":                     "الأتوبيس",
+			"microbus":                "الميكروباص",
+			"minibus":                 "الميني باص",
+			"metro":                   "المترو",
+			"tram":                    "الترام",
+			"train":                   "القطار",
+			"ferry":                   "العبّارة",
+			route_service.LegWalk:     "المشي",
+			route_service.LegBike:     "دراجة",
+			route_service.LegScooter:  "سكوتر",
+			route_service.LegTaxi:     "تاكسي",
+			route_service.LegRideHail: "سيارة أجرة بالتطبيق",
+		},
+	},
+}
+
+// form picks the plural form of n using the Arabic categories, which reduce
+// to one and other for English
+func (p plural) form(n int) string {
+	var f string
+	switch {
+	case n == 0:
+		f = p.zero
+	case n == 1:
+		f = p.one
+	case n == 2:
+		f = p.two
+	case n%100 >= 3 && n%100 <= 10:
+		f = p.few
+	case n%100 >= 11:
+		f = p.many
+	}
+	if f == "" {
+		return p.other
+	}
+	return f
+}
@@ -0,0 +1,253 @@
+package directions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Unicode first strong isolate and pop directional isolate, they keep Latin
+// names and numbers from reordering the Arabic text around them
+const (
+	fsi = "\u2068"
+	pdi = "\u2069"
+)
+
+var easternDigits = strings.NewReplacer(
+	"0", "٠", "1", "١", "2", "٢", "3", "٣", "4", "٤",
+	"5", "٥", "6", "٦", "7", "٧", "8", "٨", "9", "٩",
+)
+
+// Localize replaces the text summaries of journeys and sets the instruction
+// of every leg in the locale's language
+func Localize(journeys []route_service.Journey, loc Locale) {
+	for i := range journeys {
+		LocalizeJourney(&journeys[i], loc)
+	}
+}
+
+// LocalizeJourney is Localize for a single journey
+func LocalizeJourney(j *route_service.Journey, loc Locale) {
+	w := newWriter(loc)
+	for k := range j.Legs {
+		j.Legs[k].Instruction = w.instruction(j.Legs[k])
+	}
+	j.TextSummary = w.summary(j)
+}
+
+type writer struct {
+	loc Locale
+	c   *catalog
+}
+
+func newWriter(loc Locale) writer {
+	c, ok := catalogs[loc.Lang]
+	if !ok {
+		loc, c = DefaultLocale, catalogs[DefaultLocale.Lang]
+	}
+	return writer{loc: loc, c: c}
+}
+
+func (w writer) instruction(leg route_service.Leg) string {
+	c := w.c
+	switch {
+	case leg.Walk != nil:
+		return fmt.Sprintf(c.walk, w.distance(leg.Walk.DistanceMeters), w.minutes(leg.Walk.DurationMinutes))
+	case leg.Trip != nil:
+		t := leg.Trip
+		return fmt.Sprintf(c.trip, w.mode(t.Mode), w.name(t.RouteShortName), w.name(t.Headsign),
+			w.name(t.From.Name), w.name(t.To.Name), w.minutes(t.DurationMinutes))
+	case leg.Transfer != nil:
+		t := leg.Transfer
+		return fmt.Sprintf(c.transfer, w.name(t.FromTripName), w.name(t.ToTripName),
+			w.distance(t.WalkingDistanceMeters), w.minutes(t.DurationMinutes))
+	case leg.Ride != nil:
+		r := leg.Ride
+		return fmt.Sprintf(c.ride, w.mode(r.Mode), w.distance(r.DistanceMeters), w.minutes(r.DurationMinutes), w.money(r.Cost))
+	}
+	return ""
+}
+
+// summary names the rides of a journey with its duration, transfers and cost
+func (w writer) summary(j *route_service.Journey) string {
+	var rides []string
+	for _, leg := range j.Legs {
+		switch {
+		case leg.Trip != nil:
+			rides = append(rides, w.mode(leg.Trip.Mode)+" "+w.name(leg.Trip.RouteShortName))
+		case leg.Ride != nil:
+			rides = append(rides, w.mode(leg.Ride.Mode))
+		}
+	}
+	if len(rides) == 0 {
+		rides = []string{w.mode(route_service.LegWalk)}
+	}
+
+	s := j.Summary
+	text := fmt.Sprintf(w.c.summary, strings.Join(rides, w.c.arrow), w.minutes(s.TotalTimeMinutes),
+		w.count(w.c.transfers, s.Transfers), w.money(s.Cost))
+	if w.c.rtl {
+		return text
+	}
+	return capitalize(text)
+}
+
+// Preview is the text of the page a shared journey link opens
+type Preview struct {
+	Lang        string
+	Dir         string
+	Title       string
+	Description string
+	Steps       []string
+	Expires     string
+}
+
+// SharePreview describes a shared journey in the locale's language, the
+// journey is localized in place
+func SharePreview(j *route_service.Journey, expiresAt time.Time, loc Locale) Preview {
+	LocalizeJourney(j, loc)
+	w := newWriter(loc)
+
+	s := j.Summary
+	description := fmt.Sprintf(w.c.overview, w.minutes(s.TotalTimeMinutes),
+		w.count(w.c.transfers, s.Transfers), w.distance(s.WalkingDistanceMeters))
+	if s.Cost > 0 {
+		description += w.c.sep + fmt.Sprintf(w.c.fare, w.money(s.Cost))
+	}
+
+	p := Preview{
+		Lang:        w.loc.Tag(),
+		Dir:         "ltr",
+		Title:       j.TextSummary,
+		Description: description,
+		Steps:       make([]string, len(j.Legs)),
+		Expires:     fmt.Sprintf(w.c.expires, w.number(expiresAt.UTC().Format(w.c.date))),
+	}
+	if w.c.rtl {
+		p.Dir = "rtl"
+	}
+	for i, leg := range j.Legs {
+		p.Steps[i] = leg.Instruction
+	}
+	return p
+}
+
+// Notice is the text of a notification
+type Notice struct {
+	Title string
+	Body  string
+}
+
+// LeaveNotice tells the rider to set off for the journey in minutes, or now
+// when at most one is left. The journey is localized in place
+func LeaveNotice(j *route_service.Journey, minutes int, loc Locale) Notice {
+	LocalizeJourney(j, loc)
+	w := newWriter(loc)
+
+	body := fmt.Sprintf(w.c.leaveIn, w.minutes(minutes))
+	if minutes <= 1 {
+		body = w.c.leaveNow
+	}
+	if j.TextSummary != "" {
+		body += ": " + j.TextSummary
+	}
+	return Notice{Title: w.c.leave, Body: body}
+}
+
+// AlertNotice is the text of an alert affecting trip, in the alert's own
+// wording when it has a translation
+func AlertNotice(a route_service.Alert, trip *route_service.TripLeg, loc Locale) Notice {
+	w := newWriter(loc)
+
+	body := Translation(a.Description, loc)
+	if body == "" {
+		body = fmt.Sprintf(w.c.affects, w.name(trip.RouteShortName))
+	}
+	return Notice{Title: Translation(a.Header, loc), Body: body}
+}
+
+// Translation picks the text of a translated field in the locale's
+// language, else in English, else in any language. Keys are language tags
+// such as "ar" or "ar-EG"
+func Translation(texts map[string]string, loc Locale) string {
+	for _, want := range []string{loc.Lang, English} {
+		best := ""
+		for tag := range texts {
+			lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+			// Prefer the plain tag and pick deterministically among regions
+			if lang == want && (best == "" || len(tag) < len(best) || len(tag) == len(best) && tag < best) {
+				best = tag
+			}
+		}
+		if best != "" {
+			return texts[best]
+		}
+	}
+
+	best := ""
+	for tag := range texts {
+		if best == "" || tag < best {
+			best = tag
+		}
+	}
+	return texts[best]
+}
+
+// capitalize upper-cases the first letter of s, which may be multibyte
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+func (w writer) mode(mode string) string {
+	if name, ok := w.c.modes[mode]; ok {
+		return name
+	}
+	return w.name(mode)
+}
+
+// name isolates text that may run in the other direction
+func (w writer) name(s string) string {
+	if !w.c.rtl || s == "" {
+		return s
+	}
+	return fsi + s + pdi
+}
+
+func (w writer) number(s string) string {
+	if w.loc.ArabicDigits {
+		return easternDigits.Replace(strings.Replace(s, ".", "٫", 1))
+	}
+	return w.name(s)
+}
+
+func (w writer) count(p plural, n int) string {
+	f := p.form(n)
+	if !strings.Contains(f, "%s") {
+		return f
+	}
+	return fmt.Sprintf(f, w.number(strconv.Itoa(n)))
+}
+
+func (w writer) minutes(n int) string {
+	return w.count(w.c.minutes, n)
+}
+
+func (w writer) distance(meters int) string {
+	if meters < 1000 {
+		return fmt.Sprintf(w.c.meters, w.number(strconv.Itoa(meters)))
+	}
+	return fmt.Sprintf(w.c.km, w.number(strconv.FormatFloat(float64(meters)/1000, 'f', 1, 64)))
+}
+
+func (w writer) money(v float64) string {
+	return fmt.Sprintf(w.c.currency, w.number(strconv.FormatFloat(v, 'f', 2, 64)))
+}
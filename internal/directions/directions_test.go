@@ -0,0 +1,103 @@
+package directions
+
+import "testing"
+
+func TestPlurals(t *testing.T) {
+	tests := []struct {
+		loc       Locale
+		n         int
+		minutes   string
+		transfers string
+	}{
+		{Locale{Lang: English}, 0, "0 min", "no transfers"},
+		{Locale{Lang: English}, 1, "1 min", "1 transfer"},
+		{Locale{Lang: English}, 2, "2 min", "2 transfers"},
+		{Locale{Lang: English}, 11, "11 min", "11 transfers"},
+		{Locale{Lang: Arabic}, 0, fsi + "0" + pdi + " دقيقة", "بدون تبديل"},
+		{Locale{Lang: Arabic}, 1, "دقيقة واحدة", "تبديل واحد"},
+		{Locale{Lang: Arabic}, 2, "دقيقتان", "تبديلان"},
+		{Locale{Lang: Arabic}, 3, fsi + "3" + pdi + " دقائق", fsi + "3" + pdi + " تبديلات"},
+		{Locale{Lang: Arabic}, 10, fsi + "10" + pdi + " دقائق", fsi + "10" + pdi + " تبديلات"},
+		{Locale{Lang: Arabic}, 11, fsi + "11" + pdi + " دقيقة", fsi + "11" + pdi + " تبديل"},
+		{Locale{Lang: Arabic}, 100, fsi + "100" + pdi + " دقيقة", fsi + "100" + pdi + " تبديل"},
+		{Locale{Lang: Arabic}, 103, fsi + "103" + pdi + " دقائق", fsi + "103" + pdi + " تبديلات"},
+		{Locale{Lang: Arabic, ArabicDigits: true}, 5, "٥ دقائق", "٥ تبديلات"},
+	}
+
+	for _, tt := range tests {
+		w := newWriter(tt.loc)
+		if got := w.minutes(tt.n); got != tt.minutes {
+			t.Errorf("%s minutes(%d) = %q, want %q", tt.loc.Lang, tt.n, got, tt.minutes)
+		}
+		if got := w.count(w.c.transfers, tt.n); got != tt.transfers {
+			t.Errorf("%s transfers(%d) = %q, want %q", tt.loc.Lang, tt.n, got, tt.transfers)
+		}
+	}
+}
+
+func TestCapitalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"bus 12", "Bus 12"},
+		{"élan", "Élan"},
+		{"ñandú", "Ñandú"},
+		{"12 min", "12 min"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := capitalize(tt.in); got != tt.want {
+			t.Errorf("capitalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Locale
+	}{
+		{"", DefaultLocale},
+		{"ar", Locale{Lang: Arabic}},
+		{"ar-EG-u-nu-arab", Locale{Lang: Arabic, ArabicDigits: true}},
+		{"fr, ar;q=0.5, en;q=0.8", Locale{Lang: English}},
+		{"en;q=0.2, ar-EG;q=0.9", Locale{Lang: Arabic}},
+		{"en;level=1;q=0.2, ar;q=0.9", Locale{Lang: Arabic}},
+		{"ar;q=0, en", Locale{Lang: English}},
+		{"ar;q=high, en", Locale{Lang: English}},
+		{"de", DefaultLocale},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); got != tt.want {
+			t.Errorf("ParseAcceptLanguage(%q) = %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestTranslation(t *testing.T) {
+	tests := []struct {
+		texts map[string]string
+		loc   Locale
+		want  string
+	}{
+		{map[string]string{"en": "Closed", "ar": "مغلق"}, Locale{Lang: Arabic}, "مغلق"},
+		{map[string]string{"en": "Closed", "ar-EG": "مغلق"}, Locale{Lang: Arabic}, "مغلق"},
+		{map[string]string{"en": "Closed", "fr": "Fermé"}, Locale{Lang: Arabic}, "Closed"},
+		{map[string]string{"EN-gb": "Closed", "fr": "Fermé"}, Locale{Lang: English}, "Closed"},
+		{map[string]string{"fr": "Fermé", "de": "Geschlossen"}, Locale{Lang: Arabic}, "Geschlossen"},
+		{nil, Locale{Lang: English}, ""},
+	}
+	for _, tt := range tests {
+		if got := Translation(tt.texts, tt.loc); got != tt.want {
+			t.Errorf("Translation(%v, %s) = %q, want %q", tt.texts, tt.loc, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleString(t *testing.T) {
+	for _, loc := range []Locale{{Lang: English}, {Lang: Arabic}, {Lang: Arabic, ArabicDigits: true}} {
+		if got := ParseAcceptLanguage(loc.String()); got != loc {
+			t.Errorf("ParseAcceptLanguage(%q) = %+v, want %+v", loc.String(), got, loc)
+		}
+	}
+}
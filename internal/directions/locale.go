@@ -0,0 +1,87 @@
+package directions
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages
+const (
+	English = "en"
+	Arabic  = "ar"
+)
+
+// Locale selects the language and digits of generated text
+type Locale struct {
+	Lang string
+	// ArabicDigits writes numbers with Eastern Arabic digits
+	ArabicDigits bool
+}
+
+// DefaultLocale is used when the client accepts no supported language
+var DefaultLocale = Locale{Lang: English}
+
+// FromRequest picks the locale from the Accept-Language header. Eastern
+// Arabic digits are requested with the Unicode numbering extension, as in
+// "ar-EG-u-nu-arab"
+func FromRequest(r *http.Request) Locale {
+	return ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// ParseAcceptLanguage returns the supported locale with the highest quality
+func ParseAcceptLanguage(header string) Locale {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q, ok := quality(params)
+		if ok && tag != "" && q > 0 {
+			candidates = append(candidates, candidate{tag: strings.ToLower(tag), q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].q > candidates[b].q })
+
+	for _, c := range candidates {
+		lang, _, _ := strings.Cut(c.tag, "-")
+		switch lang {
+		case Arabic:
+			return Locale{Lang: Arabic, ArabicDigits: strings.Contains(c.tag, "-u-nu-arab")}
+		case English:
+			return Locale{Lang: English}
+		}
+	}
+	return DefaultLocale
+}
+
+// quality reads the q parameter among the parameters of a language range,
+// 1 when it has none. It fails on a malformed q
+func quality(params string) (float64, bool) {
+	for param := range strings.SplitSeq(params, ";") {
+		name, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return q, err == nil
+	}
+	return 1, true
+}
+
+// Tag is the Content-Language value of the locale
+func (l Locale) Tag() string {
+	return l.Lang
+}
+
+// String is a language tag ParseAcceptLanguage reads back as l, used to
+// store the locale
+func (l Locale) String() string {
+	if l.ArabicDigits {
+		return l.Lang + "-u-nu-arab"
+	}
+	return l.Lang
+}
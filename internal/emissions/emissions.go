@@ -0,0 +1,108 @@
+package emissions
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Car is the mode journeys are compared against
+const Car = "car"
+
+// Factor is the CO2-equivalent a vehicle emits and how many people share it
+type Factor struct {
+	GramsPerKm float64
+	Occupancy  float64
+}
+
+// PerPassengerKm is each rider's share of the vehicle's emissions per km
+func (f Factor) PerPassengerKm() float64 {
+	return f.GramsPerKm / math.Max(f.Occupancy, 1)
+}
+
+// Factors holds an emission factor per mode
+type Factors map[string]Factor
+
+// ParseFactors reads "mode=gramsPerVehicleKm:occupancy,..." such as
+// "bus=1100:35,car=180:1.3". A car factor is needed for the comparison
+func ParseFactors(spec string) (Factors, error) {
+	factors := make(Factors)
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		mode, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid emission factor %q", entry)
+		}
+		grams, occupancy, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("emission factor %q needs grams:occupancy", entry)
+		}
+
+		g, err := strconv.ParseFloat(grams, 64)
+		if err != nil || g < 0 {
+			return nil, fmt.Errorf("invalid grams in emission factor %q", entry)
+		}
+		o, err := strconv.ParseFloat(occupancy, 64)
+		if err != nil || o <= 0 {
+			return nil, fmt.Errorf("invalid occupancy in emission factor %q", entry)
+		}
+		factors[strings.TrimSpace(mode)] = Factor{GramsPerKm: g, Occupancy: o}
+	}
+
+	if _, ok := factors[Car]; !ok {
+		return nil, fmt.Errorf("emission factors need a %s factor", Car)
+	}
+	return factors, nil
+}
+
+// Estimate sets the emissions of every trip and ride leg and the journey
+// total, with what driving the journey's distance would emit. Legs whose
+// mode has no factor are left unset and so is the total, a journey with
+// unknown emissions is never the greenest
+func (f Factors) Estimate(j *route_service.Journey) {
+	total, known := 0, true
+	for _, leg := range j.Legs {
+		var grams *int
+		switch {
+		case leg.Trip != nil:
+			grams = f.grams(leg.Trip.Mode, leg.Trip.RideMeters())
+			leg.Trip.CO2Grams = grams
+		case leg.Ride != nil:
+			grams = f.grams(leg.Ride.Mode, float64(leg.Ride.DistanceMeters))
+			leg.Ride.CO2Grams = grams
+		default:
+			continue
+		}
+		if grams == nil {
+			known = false
+			continue
+		}
+		total += *grams
+	}
+
+	j.Summary.CO2Grams = nil
+	if known {
+		j.Summary.CO2Grams = &total
+	}
+	if driving := f.grams(Car, float64(j.Summary.TotalDistanceMeters)); driving != nil {
+		j.Summary.DrivingCO2Grams = *driving
+	}
+}
+
+// grams is a passenger's share of the emissions over meters, nil when the
+// mode has no factor
+func (f Factors) grams(mode string, meters float64) *int {
+	factor, ok := f[mode]
+	if !ok {
+		return nil
+	}
+	g := int(math.Round(factor.PerPassengerKm() * meters / 1000))
+	return &g
+}
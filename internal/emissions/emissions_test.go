@@ -0,0 +1,134 @@
+package emissions
+
+import (
+	"testing"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+func TestParseFactors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Factors
+		wantErr bool
+	}{
+		{
+			spec: "bus=1100:35, car=180:1.3",
+			want: Factors{"bus": {GramsPerKm: 1100, Occupancy: 35}, "car": {GramsPerKm: 180, Occupancy: 1.3}},
+		},
+		{
+			spec: "car=180:1.3,metro=0:100,",
+			want: Factors{"car": {GramsPerKm: 180, Occupancy: 1.3}, "metro": {GramsPerKm: 0, Occupancy: 100}},
+		},
+		{spec: "bus=1100:35", wantErr: true},
+		{spec: "car", wantErr: true},
+		{spec: "car=180", wantErr: true},
+		{spec: "car=x:1", wantErr: true},
+		{spec: "car=-1:1", wantErr: true},
+		{spec: "car=180:0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFactors(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseFactors(%q) = %v, want an error", tt.spec, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseFactors(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("ParseFactors(%q) = %v, want %v", tt.spec, got, tt.want)
+			continue
+		}
+		for mode, f := range tt.want {
+			if got[mode] != f {
+				t.Errorf("ParseFactors(%q)[%s] = %v, want %v", tt.spec, mode, got[mode], f)
+			}
+		}
+	}
+}
+
+func TestGrams(t *testing.T) {
+	factors := Factors{
+		"bus": {GramsPerKm: 1100, Occupancy: 20},
+		"car": {GramsPerKm: 180, Occupancy: 0.5},
+	}
+
+	tests := []struct {
+		mode   string
+		meters float64
+		want   *int
+	}{
+		{"bus", 1000, ptr(55)},
+		{"bus", 2500, ptr(138)},
+		{"bus", 0, ptr(0)},
+		// Occupancy below one still charges the whole vehicle to the rider
+		{"car", 1000, ptr(180)},
+		{"ferry", 1000, nil},
+	}
+
+	for _, tt := range tests {
+		got := factors.grams(tt.mode, tt.meters)
+		switch {
+		case got == nil && tt.want == nil:
+		case got == nil || tt.want == nil || *got != *tt.want:
+			t.Errorf("grams(%s, %v) = %v, want %v", tt.mode, tt.meters, deref(got), deref(tt.want))
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	factors := Factors{
+		"bus": {GramsPerKm: 1100, Occupancy: 20},
+		"car": {GramsPerKm: 180, Occupancy: 1},
+	}
+	trip := func(mode string) route_service.Leg {
+		return route_service.Leg{Trip: &route_service.TripLeg{
+			Mode: mode,
+			From: route_service.Stop{Coord: route_service.Coordinate{Lon: 31.2, Lat: 30.0}},
+			To:   route_service.Stop{Coord: route_service.Coordinate{Lon: 31.2, Lat: 30.01}},
+		}}
+	}
+
+	tests := []struct {
+		name string
+		legs []route_service.Leg
+		want *int
+	}{
+		{name: "walk only", legs: []route_service.Leg{{Walk: &route_service.WalkLeg{DistanceMeters: 300}}}, want: ptr(0)},
+		{name: "known modes add up", legs: []route_service.Leg{trip("bus"), trip("bus")}, want: ptr(122)},
+		{name: "unknown mode leaves the total unset", legs: []route_service.Leg{trip("bus"), trip("ferry")}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := route_service.Journey{Legs: tt.legs, Summary: route_service.JourneySummary{TotalDistanceMeters: 2000}}
+			factors.Estimate(&j)
+
+			got := j.Summary.CO2Grams
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("CO2 = %v, want %v", deref(got), deref(tt.want))
+			}
+			if j.Summary.DrivingCO2Grams != 360 {
+				t.Errorf("driving CO2 = %d, want 360", j.Summary.DrivingCO2Grams)
+			}
+		})
+	}
+}
+
+func deref(v *int) any {
+	if v == nil {
+		return "unknown"
+	}
+	return *v
+}
+
+func ptr(v int) *int {
+	return &v
+}
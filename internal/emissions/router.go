@@ -0,0 +1,32 @@
+package emissions
+
+import (
+	"context"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Router decorates another Router, estimating the emissions of each journey
+type Router struct {
+	route_service.Router
+	factors Factors
+}
+
+func NewRouter(inner route_service.Router, factors Factors) *Router {
+	return &Router{
+		Router:  inner,
+		factors: factors,
+	}
+}
+
+func (r *Router) FindRoute(ctx context.Context, req route_service.RouteRequest) (route_service.RouteResponse, error) {
+	resp, err := r.Router.FindRoute(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	for i := range resp.Journeys {
+		r.factors.Estimate(&resp.Journeys[i])
+	}
+	return resp, nil
+}
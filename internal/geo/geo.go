@@ -0,0 +1,186 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusMeters is the mean Earth radius used for distances
+const earthRadiusMeters = 6371008.8
+
+// Point is a WGS84 position in degrees
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// Valid reports whether p is within the WGS84 coordinate range
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// Distance returns the great-circle distance between a and b in meters
+func Distance(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLon := radians(b.Lon - a.Lon)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Centroid returns the mean of points, good enough over a few hundred meters
+func Centroid(points []Point) Point {
+	var c Point
+	for _, p := range points {
+		c.Lat += p.Lat
+		c.Lon += p.Lon
+	}
+	if n := float64(len(points)); n > 0 {
+		c.Lat /= n
+		c.Lon /= n
+	}
+	return c
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Length returns the length of a polyline in meters
+func Length(points []Point) float64 {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += Distance(points[i-1], points[i])
+	}
+	return total
+}
+
+// Simplify reduces a polyline with the Douglas-Peucker algorithm, dropping
+// points closer than toleranceMeters to the simplified line
+func Simplify(points []Point, toleranceMeters float64) []Point {
+	if len(points) < 3 {
+		return points
+	}
+
+	keep := make([]bool, len(points))
+	keep[0], keep[len(points)-1] = true, true
+	simplify(points, 0, len(points)-1, toleranceMeters, keep)
+
+	simplified := make([]Point, 0, len(points))
+	for i, p := range points {
+		if keep[i] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
+
+func simplify(points []Point, first, last int, tolerance float64, keep []bool) {
+	farthest, maxDist := -1, tolerance
+	for i := first + 1; i < last; i++ {
+		if d := SegmentDistance(points[i], points[first], points[last]); d > maxDist {
+			farthest, maxDist = i, d
+		}
+	}
+	if farthest < 0 {
+		return
+	}
+
+	keep[farthest] = true
+	simplify(points, first, farthest, tolerance, keep)
+	simplify(points, farthest, last, tolerance, keep)
+}
+
+// SegmentDistance returns the distance in meters from p to the segment a-b,
+// using a local flat projection that holds over city distances
+func SegmentDistance(p, a, b Point) float64 {
+	px, py := project(p, a)
+	bx, by := project(b, a)
+
+	t := 0.0
+	if lenSq := bx*bx + by*by; lenSq > 0 {
+		t = math.Max(0, math.Min(1, (px*bx+py*by)/lenSq))
+	}
+	return math.Hypot(px-t*bx, py-t*by)
+}
+
+// project maps p to meters east and north of origin
+func project(p, origin Point) (float64, float64) {
+	x := radians(p.Lon-origin.Lon) * math.Cos(radians(origin.Lat)) * earthRadiusMeters
+	y := radians(p.Lat-origin.Lat) * earthRadiusMeters
+	return x, y
+}
+
+// BBox is a rectangular area, the zero value contains every point
+type BBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
+}
+
+// ParseBBox reads "minLat,minLon,maxLat,maxLon", an empty string yields the
+// zero BBox
+func ParseBBox(s string) (BBox, error) {
+	if strings.TrimSpace(s) == "" {
+		return BBox{}, nil
+	}
+
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return BBox{}, fmt.Errorf("expected minLat,minLon,maxLat,maxLon, got '%s'", s)
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return BBox{}, fmt.Errorf("invalid coordinate '%s'", p)
+		}
+		v[i] = f
+	}
+
+	b := BBox{MinLat: v[0], MinLon: v[1], MaxLat: v[2], MaxLon: v[3]}
+	if b.MinLat >= b.MaxLat || b.MinLon >= b.MaxLon {
+		return BBox{}, fmt.Errorf("bounding box '%s' is empty", s)
+	}
+	return b, nil
+}
+
+// IsZero reports whether b is unrestricted
+func (b BBox) IsZero() bool {
+	return b == BBox{}
+}
+
+// Contains reports whether p lies inside b
+func (b BBox) Contains(p Point) bool {
+	if b.IsZero() {
+		return true
+	}
+	return p.Lat >= b.MinLat && p.Lat <= b.MaxLat && p.Lon >= b.MinLon && p.Lon <= b.MaxLon
+}
+
+// Around returns the box extending radiusMeters around p
+func Around(p Point, radiusMeters float64) BBox {
+	dLat := radiusMeters / earthRadiusMeters * 180 / math.Pi
+	dLon := dLat / math.Max(math.Cos(radians(p.Lat)), 1e-6)
+	return BBox{MinLat: p.Lat - dLat, MinLon: p.Lon - dLon, MaxLat: p.Lat + dLat, MaxLon: p.Lon + dLon}
+}
+
+// Polygon is a ring of points, closing back to the first one is optional
+type Polygon []Point
+
+// Contains reports whether p lies inside the polygon, by ray casting
+func (poly Polygon) Contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Delivery is a message the fake transport was asked to send
+type Delivery struct {
+	Subscription Subscription
+	Message      Message
+	SentAt       time.Time
+}
+
+// Fake keeps messages in memory and logs them instead of sending, for local
+// development and tests
+type Fake struct {
+	mu   sync.Mutex
+	sent []Delivery
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Send(ctx context.Context, sub Subscription, msg Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	log.Printf("Notification to %s:%s: %s - %s", sub.Channel, sub.Target, msg.Title, msg.Body)
+	f.sent = append(f.sent, Delivery{Subscription: sub, Message: msg, SentAt: time.Now()})
+	return nil
+}
+
+// Sent returns the messages delivered so far
+func (f *Fake) Sent() []Delivery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Delivery(nil), f.sent...)
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// Delivery channels
+const (
+	ChannelWebPush = "webpush"
+	ChannelFCM     = "fcm"
+	ChannelEmail   = "email"
+	ChannelFake    = "fake"
+)
+
+// ErrGone is returned when the push service no longer accepts a subscription,
+// the caller should forget it
+var ErrGone = errors.New("subscription is no longer valid")
+
+// Message is a notification to deliver
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Data is handed to the client app, such as the reminder it is about
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// Subscription is one place a user receives notifications
+type Subscription struct {
+	Channel string
+	// Target is the push endpoint, the FCM registration token or the email address
+	Target string
+	// P256dh and Auth are the web push encryption keys of the browser
+	P256dh string
+	Auth   string
+}
+
+// Transport delivers messages over one channel
+type Transport interface {
+	Send(ctx context.Context, sub Subscription, msg Message) error
+}
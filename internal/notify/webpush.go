@@ -0,0 +1,230 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// webPushTTL is how long push services keep an undelivered message
+	webPushTTL = 24 * time.Hour
+	// recordSize is the aes128gcm record size, messages fit in one record
+	recordSize = 4096
+)
+
+// pushHosts are the push services of the major browsers, subscriptions must
+// point at one of them or at a subdomain of one starting with a dot
+var pushHosts = []string{
+	"fcm.googleapis.com",
+	"updates.push.services.mozilla.com",
+	".push.services.mozilla.com",
+	".notify.windows.com",
+	"web.push.apple.com",
+	".push.apple.com",
+}
+
+// ValidPushEndpoint reports whether endpoint is an https URL on a known push
+// service, anything else would let users make the server post to arbitrary hosts
+func ValidPushEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil || (u.Port() != "" && u.Port() != "443") {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return slices.ContainsFunc(pushHosts, func(h string) bool {
+		if strings.HasPrefix(h, ".") {
+			return strings.HasSuffix(host, h)
+		}
+		return host == h
+	})
+}
+
+// WebPush sends encrypted notifications to browser push endpoints, signing
+// requests with a VAPID key
+type WebPush struct {
+	key     *ecdsa.PrivateKey
+	public  []byte
+	subject string
+	client  *http.Client
+}
+
+// NewWebPush takes the VAPID private key as an unpadded base64url P-256
+// scalar and the contact the push services may reach, such as a mailto: URL
+func NewWebPush(privateKey, subject string) (*WebPush, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	return &WebPush{
+		key:     key,
+		public:  public,
+		subject: subject,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// Push services answer directly, following a redirect could leave them
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// PublicKey is the application server key browsers subscribe with
+func (p *WebPush) PublicKey() string {
+	return base64.RawURLEncoding.EncodeToString(p.public)
+}
+
+func (p *WebPush) Send(ctx context.Context, sub Subscription, msg Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode push message: %w", err)
+	}
+	body, err := encrypt(payload, sub.P256dh, sub.Auth)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(sub.Target)
+	if err != nil || !ValidPushEndpoint(sub.Target) {
+		return fmt.Errorf("invalid push endpoint '%s'", sub.Target)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": p.subject,
+	}).SignedString(p.key)
+	if err != nil {
+		return fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "vapid t="+token+", k="+p.PublicKey())
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprint(int(webPushTTL.Seconds())))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach push service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("push service returned %s", resp.Status)
+	}
+	return nil
+}
+
+// encrypt seals payload for a browser as described in RFC 8291
+func encrypt(payload []byte, p256dh, authSecret string) ([]byte, error) {
+	uaPublic, err := base64.RawURLEncoding.DecodeString(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	auth, err := base64.RawURLEncoding.DecodeString(authSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return seal(payload, uaKey, auth, asKey, salt)
+}
+
+// seal encrypts payload with the given server key and salt, a fresh pair
+// must be used for every message
+func seal(payload []byte, uaKey *ecdh.PublicKey, auth []byte, asKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaPublic, asPublic := uaKey.Bytes(), asKey.PublicKey().Bytes()
+	shared, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, err
+	}
+
+	prkKey, err := hkdf.Extract(sha256.New, shared, auth)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload)+1+gcm.Overhead() > recordSize {
+		return nil, fmt.Errorf("push message of %d bytes is too large", len(payload))
+	}
+
+	// The header carries the salt, the record size and the server's public key
+	header := make([]byte, 0, 21+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 marks the last and only record
+	return gcm.Seal(header, nonce, append(payload, 0x02), nil), nil
+}
@@ -0,0 +1,98 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+)
+
+// The example of RFC 8291 section 5
+const (
+	rfcPlaintext = "When I grow up, I want to be a watermelon"
+	rfcASPublic  = "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8"
+	rfcASPrivate = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfcUAPublic  = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfcUAPrivate = "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"
+	rfcAuth      = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfcSalt      = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfcSecret    = "kyrL1jIIOHEzg3sM2ZWRHDRB62YACZhhSlknJ672kSs"
+	rfcMessage   = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid test vector %q: %v", s, err)
+	}
+	return b
+}
+
+func TestSealRFC8291(t *testing.T) {
+	asKey, err := ecdh.P256().NewPrivateKey(decode(t, rfcASPrivate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uaKey, err := ecdh.P256().NewPrivateKey(decode(t, rfcUAPrivate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(asKey.PublicKey().Bytes(), decode(t, rfcASPublic)) || !bytes.Equal(uaKey.PublicKey().Bytes(), decode(t, rfcUAPublic)) {
+		t.Fatal("test vector public keys do not match the private keys")
+	}
+	if secret, _ := asKey.ECDH(uaKey.PublicKey()); !bytes.Equal(secret, decode(t, rfcSecret)) {
+		t.Fatal("test vector shared secret does not match the keys")
+	}
+
+	got, err := seal([]byte(rfcPlaintext), uaKey.PublicKey(), decode(t, rfcAuth), asKey, decode(t, rfcSalt))
+	if err != nil {
+		t.Fatalf("seal failed: %v", err)
+	}
+	if want := decode(t, rfcMessage); !bytes.Equal(got, want) {
+		t.Errorf("seal = %s, want %s", base64.RawURLEncoding.EncodeToString(got), rfcMessage)
+	}
+}
+
+func TestEncryptRejectsBadKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		p256dh string
+		auth   string
+	}{
+		{name: "not base64", p256dh: "not base64!", auth: rfcAuth},
+		{name: "not a curve point", p256dh: base64.RawURLEncoding.EncodeToString(make([]byte, 65)), auth: rfcAuth},
+		{name: "bad auth", p256dh: rfcUAPublic, auth: "%%%"},
+	}
+	for _, tt := range tests {
+		if _, err := encrypt([]byte(rfcPlaintext), tt.p256dh, tt.auth); err == nil {
+			t.Errorf("%s: encrypt succeeded, want an error", tt.name)
+		}
+	}
+}
+
+func TestValidPushEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     bool
+	}{
+		{"https://fcm.googleapis.com/fcm/send/abc", true},
+		{"https://updates.push.services.mozilla.com/wpush/v2/abc", true},
+		{"https://wns2-par02p.notify.windows.com/w/?token=abc", true},
+		{"https://web.push.apple.com/abc", true},
+		{"https://FCM.googleapis.com:443/fcm/send/abc", true},
+		{"http://fcm.googleapis.com/fcm/send/abc", false},
+		{"https://fcm.googleapis.com:8443/fcm/send/abc", false},
+		{"https://user@fcm.googleapis.com/fcm/send/abc", false},
+		{"https://evilnotify.windows.com/abc", false},
+		{"https://fcm.googleapis.com.evil.example/abc", false},
+		{"https://169.254.169.254/latest/meta-data", false},
+		{"https://localhost/abc", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		if got := ValidPushEndpoint(tt.endpoint); got != tt.want {
+			t.Errorf("ValidPushEndpoint(%q) = %v, want %v", tt.endpoint, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package ranking
+
+import (
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+const (
+	learningRate = 0.1
+	minWeight    = 0.01
+)
+
+// Learn updates w from one observed choice with a pairwise perceptron step:
+// every alternative the current weights score at least as well as the chosen
+// journey pulls weight towards the criteria on which the chosen one was better
+func Learn(w route_service.RoutingWeights, chosen Features, alternatives []Features) route_service.RoutingWeights {
+	if len(alternatives) == 0 {
+		return w
+	}
+
+	norm := NewNormalization(append([]Features{chosen}, alternatives...))
+	c := norm.Apply(chosen)
+	chosenScore := Score(c, w)
+
+	for _, alt := range alternatives {
+		a := norm.Apply(alt)
+		if Score(a, w) > chosenScore {
+			// Already ranked below the chosen journey, nothing to learn
+			continue
+		}
+		w.Time += learningRate * (a.Time - c.Time)
+		w.Cost += learningRate * (a.Cost - c.Cost)
+		w.Walk += learningRate * (a.Walk - c.Walk)
+		w.Transfer += learningRate * (a.Transfer - c.Transfer)
+	}
+
+	w.Time = max(w.Time, minWeight)
+	w.Cost = max(w.Cost, minWeight)
+	w.Walk = max(w.Walk, minWeight)
+	w.Transfer = max(w.Transfer, minWeight)
+	return Normalize(w)
+}
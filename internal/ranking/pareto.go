@@ -0,0 +1,172 @@
+package ranking
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Labels given to the best journeys on a single criterion
+const (
+	LabelFastest      = "fastest"
+	LabelCheapest     = "cheapest"
+	LabelLeastWalking = "least_walking"
+	LabelGreenest     = "greenest"
+)
+
+// Prune filters and labels the journeys of resp. It runs once every router
+// decorator has set fares, emissions and walks, so journeys are compared on
+// the values the client sees
+func Prune(resp *route_service.RouteResponse, debug bool) {
+	journeys, dropped := Filter(resp.Journeys)
+	resp.SetJourneys(journeys)
+	if debug {
+		resp.Dropped = append(resp.Dropped, dropped...)
+	}
+	Label(resp.Journeys)
+}
+
+// Filter merges journeys that ride the same sequence of lines and differ only
+// in where they transfer, keeping the better one, then drops every journey
+// another one matches or beats on all criteria. Survivors keep their order,
+// the removed ones are returned with the reason. Reasons name survivors by
+// their position from 1, the ID SetJourneys gives them
+func Filter(journeys []route_service.Journey) ([]route_service.Journey, []route_service.DroppedJourney) {
+	merged := make([]route_service.Journey, 0, len(journeys))
+	var losers []route_service.Journey
+	var winners []int
+	seen := make(map[string]int, len(journeys))
+	for _, j := range journeys {
+		sig := signature(j)
+		if i, ok := seen[sig]; ok {
+			if better(j, merged[i]) {
+				j, merged[i] = merged[i], j
+			}
+			losers, winners = append(losers, j), append(winners, i)
+			continue
+		}
+		seen[sig] = len(merged)
+		merged = append(merged, j)
+	}
+
+	features := make([]Features, len(merged))
+	for i, j := range merged {
+		features[i] = FeaturesOf(j.Summary)
+	}
+	dominated := make([]bool, len(merged))
+	for i := range merged {
+		for k := range merged {
+			if k != i && Dominates(features[k], features[i]) {
+				dominated[i] = true
+				break
+			}
+		}
+	}
+
+	kept := make([]route_service.Journey, 0, len(merged))
+	positions := make([]int, len(merged))
+	for i, j := range merged {
+		if !dominated[i] {
+			kept = append(kept, j)
+			positions[i] = len(kept)
+		}
+	}
+
+	var dropped []route_service.DroppedJourney
+	for n, j := range losers {
+		w := winners[n]
+		reason := fmt.Sprintf("same lines as journey %d with a worse transfer", positions[w])
+		if dominated[w] {
+			reason = fmt.Sprintf("same lines as dropped journey %d with a worse transfer", merged[w].ID)
+		}
+		dropped = append(dropped, route_service.Dropped(j, reason))
+	}
+	// Dominance is transitive, so every dominated journey is dominated by a survivor
+	for i, j := range merged {
+		if !dominated[i] {
+			continue
+		}
+		for k := range merged {
+			if !dominated[k] && Dominates(features[k], features[i]) {
+				dropped = append(dropped, route_service.Dropped(j, fmt.Sprintf("dominated by journey %d", positions[k])))
+				break
+			}
+		}
+	}
+	return kept, dropped
+}
+
+// Dominates reports whether a is no worse than b on every criterion and
+// better on at least one. Emissions are compared when both are known, a
+// journey with unknown emissions neither dominates nor is dominated by one
+// with an estimate
+func Dominates(a, b Features) bool {
+	if a.Time > b.Time || a.Cost > b.Cost || a.Walk > b.Walk || a.Transfer > b.Transfer {
+		return false
+	}
+	if a.UnknownCO2 != b.UnknownCO2 || a.CO2 > b.CO2 {
+		return false
+	}
+	return a != b
+}
+
+// Label tags the journeys that are best on time, cost, walking and
+// emissions, ties share the label
+func Label(journeys []route_service.Journey) {
+	if len(journeys) == 0 {
+		return
+	}
+
+	features := make([]Features, len(journeys))
+	for i, j := range journeys {
+		features[i] = FeaturesOf(j.Summary)
+	}
+	// Only journeys with an emission estimate can be the greenest
+	best := NewNormalization(features).Min
+	hasCO2 := slices.ContainsFunc(features, func(f Features) bool { return !f.UnknownCO2 })
+
+	for i := range journeys {
+		f := features[i]
+		journeys[i].Labels = nil
+		if f.Time == best.Time {
+			journeys[i].Labels = append(journeys[i].Labels, LabelFastest)
+		}
+		if f.Cost == best.Cost {
+			journeys[i].Labels = append(journeys[i].Labels, LabelCheapest)
+		}
+		if f.Walk == best.Walk {
+			journeys[i].Labels = append(journeys[i].Labels, LabelLeastWalking)
+		}
+		if hasCO2 && !f.UnknownCO2 && f.CO2 == best.CO2 {
+			journeys[i].Labels = append(journeys[i].Labels, LabelGreenest)
+		}
+	}
+}
+
+// signature identifies the lines and ride modes a journey uses, in order
+func signature(j route_service.Journey) string {
+	var parts []string
+	for _, leg := range j.Legs {
+		switch {
+		case leg.Trip != nil:
+			parts = append(parts, leg.Trip.Mode+":"+leg.Trip.RouteShortName+":"+leg.Trip.Headsign)
+		case leg.Ride != nil:
+			parts = append(parts, leg.Ride.Mode)
+		}
+	}
+	return strings.Join(parts, ">")
+}
+
+// better prefers less time, then less walking, then a lower cost
+func better(a, b route_service.Journey) bool {
+	fa, fb := FeaturesOf(a.Summary), FeaturesOf(b.Summary)
+	if fa.Time != fb.Time {
+		return fa.Time < fb.Time
+	}
+	if fa.Walk != fb.Walk {
+		return fa.Walk < fb.Walk
+	}
+	return fa.Cost < fb.Cost
+}
@@ -0,0 +1,203 @@
+package ranking
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+func journey(id int, minutes int, cost float64, walk int, lines ...string) route_service.Journey {
+	j := route_service.Journey{
+		ID: id,
+		Summary: route_service.JourneySummary{
+			TotalTimeMinutes:      minutes,
+			Cost:                  cost,
+			WalkingDistanceMeters: walk,
+			Transfers:             max(0, len(lines)-1),
+		},
+	}
+	for _, line := range lines {
+		j.Legs = append(j.Legs, route_service.Leg{Trip: &route_service.TripLeg{Mode: "bus", RouteShortName: line}})
+	}
+	return j
+}
+
+func ids(journeys []route_service.Journey) []int {
+	out := make([]int, len(journeys))
+	for i, j := range journeys {
+		out[i] = j.ID
+	}
+	return out
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		journeys    []route_service.Journey
+		wantKept    []int
+		wantReasons []string
+	}{
+		{
+			name:     "trade-offs are kept",
+			journeys: []route_service.Journey{journey(1, 30, 10, 200, "A"), journey(2, 40, 5, 200, "B")},
+			wantKept: []int{1, 2},
+		},
+		{
+			name:        "dominated journey is dropped",
+			journeys:    []route_service.Journey{journey(1, 30, 10, 200, "A"), journey(2, 40, 10, 300, "B")},
+			wantKept:    []int{1},
+			wantReasons: []string{"dominated by journey 1"},
+		},
+		{
+			name:        "equal journeys are both kept",
+			journeys:    []route_service.Journey{journey(1, 30, 10, 200, "A"), journey(2, 30, 10, 200, "B")},
+			wantKept:    []int{1, 2},
+			wantReasons: nil,
+		},
+		{
+			name:        "same lines keep the faster transfer",
+			journeys:    []route_service.Journey{journey(1, 45, 10, 100, "A", "B"), journey(2, 40, 10, 300, "A", "B")},
+			wantKept:    []int{2},
+			wantReasons: []string{"same lines as journey 1 with a worse transfer"},
+		},
+		{
+			name: "reasons name survivors by their new position",
+			journeys: []route_service.Journey{
+				journey(1, 50, 20, 500, "C"),
+				journey(2, 30, 10, 200, "A"),
+				journey(3, 40, 15, 400, "B"),
+			},
+			wantKept:    []int{2},
+			wantReasons: []string{"dominated by journey 1", "dominated by journey 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, dropped := Filter(tt.journeys)
+			if got := ids(kept); !slices.Equal(got, tt.wantKept) {
+				t.Errorf("kept = %v, want %v", got, tt.wantKept)
+			}
+			var reasons []string
+			for _, d := range dropped {
+				reasons = append(reasons, d.Reason)
+			}
+			if !slices.Equal(reasons, tt.wantReasons) {
+				t.Errorf("reasons = %q, want %q", reasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	resp := route_service.RouteResponse{
+		Journeys: []route_service.Journey{
+			journey(1, 50, 20, 500, "C"),
+			journey(2, 30, 10, 200, "A"),
+			journey(3, 25, 15, 400, "B"),
+		},
+		NumJourneys: 3,
+	}
+
+	Prune(&resp, true)
+
+	if got := ids(resp.Journeys); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("journey IDs = %v, want [1 2]", got)
+	}
+	if resp.NumJourneys != 2 {
+		t.Errorf("NumJourneys = %d, want 2", resp.NumJourneys)
+	}
+	if len(resp.Dropped) != 1 || resp.Dropped[0].ID != 1 {
+		t.Errorf("dropped = %+v, want journey 1", resp.Dropped)
+	}
+	if got := resp.Journeys[0].Labels; !slices.Equal(got, []string{LabelCheapest, LabelLeastWalking}) {
+		t.Errorf("labels of journey 1 = %v", got)
+	}
+	if got := resp.Journeys[1].Labels; !slices.Equal(got, []string{LabelFastest}) {
+		t.Errorf("labels of journey 2 = %v", got)
+	}
+}
+
+func TestOrder(t *testing.T) {
+	journeys := func() []route_service.Journey {
+		return []route_service.Journey{
+			journey(1, 60, 5, 100, "A"),
+			journey(2, 30, 20, 100, "B"),
+			journey(3, 45, 10, 900, "C"),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		weights route_service.RoutingWeights
+		want    []int
+	}{
+		{name: "time", weights: route_service.RoutingWeights{Time: 1}, want: []int{2, 3, 1}},
+		{name: "cost", weights: route_service.RoutingWeights{Cost: 1}, want: []int{1, 3, 2}},
+		{name: "walk keeps ties in order", weights: route_service.RoutingWeights{Walk: 1}, want: []int{1, 2, 3}},
+		{name: "mixed", weights: route_service.RoutingWeights{Time: 0.5, Walk: 0.5}, want: []int{2, 1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := route_service.RouteResponse{Journeys: journeys()}
+			Order(&resp, tt.weights)
+
+			if got := ids(resp.Journeys); !slices.Equal(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+			for i := 1; i < len(resp.Journeys); i++ {
+				if resp.Journeys[i].Score.Total < resp.Journeys[i-1].Score.Total {
+					t.Errorf("scores are not ascending: %v after %v", resp.Journeys[i].Score.Total, resp.Journeys[i-1].Score.Total)
+				}
+			}
+			if resp.Ranking == nil || resp.Ranking.Weights != tt.weights {
+				t.Errorf("ranking weights = %+v, want %+v", resp.Ranking, tt.weights)
+			}
+		})
+	}
+}
+
+func TestDominatesCO2(t *testing.T) {
+	co2 := func(g float64) Features { return Features{Time: 30, Cost: 10, CO2: g} }
+	unknown := Features{Time: 30, Cost: 10, UnknownCO2: true}
+
+	tests := []struct {
+		name string
+		a, b Features
+		want bool
+	}{
+		{name: "greener wins on an otherwise equal journey", a: co2(100), b: co2(400), want: true},
+		{name: "dirtier never dominates", a: co2(400), b: co2(100), want: false},
+		{name: "equal journeys do not dominate", a: co2(100), b: co2(100), want: false},
+		{name: "unknown emissions do not dominate an estimate", a: unknown, b: co2(400), want: false},
+		{name: "an estimate does not dominate unknown emissions", a: co2(100), b: unknown, want: false},
+		{name: "two unknowns compare on the other criteria", a: unknown, b: Features{Time: 40, Cost: 10, UnknownCO2: true}, want: true},
+	}
+	for _, tt := range tests {
+		if got := Dominates(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: Dominates = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLabelGreenest(t *testing.T) {
+	withCO2 := func(j route_service.Journey, grams int) route_service.Journey {
+		j.Summary.CO2Grams = &grams
+		return j
+	}
+	journeys := []route_service.Journey{
+		journey(1, 30, 10, 200, "A"),
+		withCO2(journey(2, 40, 10, 200, "B"), 300),
+		withCO2(journey(3, 50, 10, 200, "C"), 150),
+	}
+
+	Label(journeys)
+
+	for i, want := range []bool{false, false, true} {
+		if got := slices.Contains(journeys[i].Labels, LabelGreenest); got != want {
+			t.Errorf("journey %d greenest = %v, want %v", journeys[i].ID, got, want)
+		}
+	}
+}
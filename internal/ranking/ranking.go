@@ -0,0 +1,209 @@
+package ranking
+
+import (
+	"sort"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// DefaultWeights are used when a request does not specify any
+var DefaultWeights = route_service.RoutingWeights{Time: 0.25, Cost: 0.25, Walk: 0.25, Transfer: 0.25}
+
+// Features are the raw criteria a journey is ranked on, lower is better
+type Features struct {
+	Time     float64 `json:"time"`
+	Cost     float64 `json:"cost"`
+	Walk     float64 `json:"walk"`
+	Transfer float64 `json:"transfer"`
+	CO2      float64 `json:"co2"`
+	// UnknownCO2 is set when the journey has no emission estimate, CO2 is
+	// then 0 and never compared
+	UnknownCO2 bool `json:"-"`
+}
+
+// FeaturesOf extracts the ranking criteria from a journey summary
+func FeaturesOf(s route_service.JourneySummary) Features {
+	f := Features{
+		Time:     float64(s.TotalTimeMinutes),
+		Cost:     s.Cost,
+		Walk:     float64(s.WalkingDistanceMeters),
+		Transfer: float64(s.Transfers),
+	}
+	if s.CO2Grams == nil {
+		f.UnknownCO2 = true
+	} else {
+		f.CO2 = float64(*s.CO2Grams)
+	}
+	return f
+}
+
+// criteria drops the emission flag for the response
+func (f Features) criteria() route_service.Criteria {
+	return route_service.Criteria{Time: f.Time, Cost: f.Cost, Walk: f.Walk, Transfer: f.Transfer, CO2: f.CO2}
+}
+
+// Normalization maps each feature onto [0, 1] using the range seen across
+// the candidate journeys, so weights compare minutes with meters fairly
+type Normalization struct {
+	Min Features `json:"min"`
+	Max Features `json:"max"`
+}
+
+// NewNormalization computes the feature ranges of a candidate set, the CO2
+// range only covers journeys with an emission estimate
+func NewNormalization(features []Features) Normalization {
+	if len(features) == 0 {
+		return Normalization{}
+	}
+	n := Normalization{Min: features[0], Max: features[0]}
+	hasCO2 := !features[0].UnknownCO2
+	for _, f := range features[1:] {
+		n.Min.Time, n.Max.Time = min(n.Min.Time, f.Time), max(n.Max.Time, f.Time)
+		n.Min.Cost, n.Max.Cost = min(n.Min.Cost, f.Cost), max(n.Max.Cost, f.Cost)
+		n.Min.Walk, n.Max.Walk = min(n.Min.Walk, f.Walk), max(n.Max.Walk, f.Walk)
+		n.Min.Transfer, n.Max.Transfer = min(n.Min.Transfer, f.Transfer), max(n.Max.Transfer, f.Transfer)
+		switch {
+		case f.UnknownCO2:
+		case !hasCO2:
+			n.Min.CO2, n.Max.CO2, hasCO2 = f.CO2, f.CO2, true
+		default:
+			n.Min.CO2, n.Max.CO2 = min(n.Min.CO2, f.CO2), max(n.Max.CO2, f.CO2)
+		}
+	}
+	n.Min.UnknownCO2, n.Max.UnknownCO2 = false, false
+	return n
+}
+
+// Apply normalizes f, a feature that is equal across all candidates maps to 0.
+// Unknown emissions count as the worst
+func (n Normalization) Apply(f Features) Features {
+	co2 := 1.0
+	if !f.UnknownCO2 {
+		co2 = scale(f.CO2, n.Min.CO2, n.Max.CO2)
+	}
+	return Features{
+		Time:     scale(f.Time, n.Min.Time, n.Max.Time),
+		Cost:     scale(f.Cost, n.Min.Cost, n.Max.Cost),
+		Walk:     scale(f.Walk, n.Min.Walk, n.Max.Walk),
+		Transfer: scale(f.Transfer, n.Min.Transfer, n.Max.Transfer),
+		CO2:      co2,
+	}
+}
+
+// Score is the weighted sum of normalized features, lower is better
+func Score(normalized Features, w route_service.RoutingWeights) float64 {
+	return w.Time*normalized.Time + w.Cost*normalized.Cost + w.Walk*normalized.Walk + w.Transfer*normalized.Transfer + w.CO2*normalized.CO2
+}
+
+// Rank sorts journeys by score under w, ties keep their original order
+func Rank(journeys []route_service.Journey, w route_service.RoutingWeights) {
+	features := make([]Features, len(journeys))
+	for i, j := range journeys {
+		features[i] = FeaturesOf(j.Summary)
+	}
+	norm := NewNormalization(features)
+
+	type scored struct {
+		journey route_service.Journey
+		score   float64
+	}
+	ranked := make([]scored, len(journeys))
+	for i, j := range journeys {
+		ranked[i] = scored{journey: j, score: Score(norm.Apply(features[i]), w)}
+	}
+
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return ranked[a].score < ranked[b].score
+	})
+	for i, r := range ranked {
+		journeys[i] = r.journey
+	}
+}
+
+// Order ranks the journeys of resp under w and explains each score, so the
+// order the client sees is the one the scores describe
+func Order(resp *route_service.RouteResponse, w route_service.RoutingWeights) {
+	Rank(resp.Journeys, w)
+	Explain(resp, w)
+}
+
+// Explain sets the score breakdown of every journey under w
+func Explain(resp *route_service.RouteResponse, w route_service.RoutingWeights) {
+	features := make([]Features, len(resp.Journeys))
+	for i, j := range resp.Journeys {
+		features[i] = FeaturesOf(j.Summary)
+	}
+	norm := NewNormalization(features)
+
+	resp.Ranking = &route_service.RankingInfo{
+		Weights: w,
+		Min:     norm.Min.criteria(),
+		Max:     norm.Max.criteria(),
+	}
+	for i := range resp.Journeys {
+		n := norm.Apply(features[i])
+		resp.Journeys[i].Score = &route_service.ScoreBreakdown{
+			Total:      Score(n, w),
+			Normalized: n.criteria(),
+			Weighted: route_service.Criteria{
+				Time:     w.Time * n.Time,
+				Cost:     w.Cost * n.Cost,
+				Walk:     w.Walk * n.Walk,
+				Transfer: w.Transfer * n.Transfer,
+				CO2:      w.CO2 * n.CO2,
+			},
+		}
+	}
+}
+
+// RequestWeights are the normalized weights of a request, DefaultWeights
+// when it has none
+func RequestWeights(req route_service.RouteRequest) route_service.RoutingWeights {
+	if req.Weights == nil {
+		return DefaultWeights
+	}
+	return Normalize(*req.Weights)
+}
+
+// Normalize scales weights to sum to 1, all-zero weights become DefaultWeights
+func Normalize(w route_service.RoutingWeights) route_service.RoutingWeights {
+	sum := w.Time + w.Cost + w.Walk + w.Transfer + w.CO2
+	if sum <= 0 {
+		return DefaultWeights
+	}
+	return route_service.RoutingWeights{Time: w.Time / sum, Cost: w.Cost / sum, Walk: w.Walk / sum, Transfer: w.Transfer / sum, CO2: w.CO2 / sum}
+}
+
+// WithCO2 sets the CO2 share of normalized weights to co2 and scales the
+// other criteria to fill the rest
+func WithCO2(w route_service.RoutingWeights, co2 float64) route_service.RoutingWeights {
+	co2 = min(1, max(0, co2))
+	w.CO2 = 0
+	rest := Normalize(w)
+	return route_service.RoutingWeights{
+		Time:     (1 - co2) * rest.Time,
+		Cost:     (1 - co2) * rest.Cost,
+		Walk:     (1 - co2) * rest.Walk,
+		Transfer: (1 - co2) * rest.Transfer,
+		CO2:      co2,
+	}
+}
+
+// Blend mixes a and b, alpha is the share of b in [0, 1]
+func Blend(a, b route_service.RoutingWeights, alpha float64) route_service.RoutingWeights {
+	alpha = min(1, max(0, alpha))
+	return route_service.RoutingWeights{
+		Time:     (1-alpha)*a.Time + alpha*b.Time,
+		Cost:     (1-alpha)*a.Cost + alpha*b.Cost,
+		Walk:     (1-alpha)*a.Walk + alpha*b.Walk,
+		Transfer: (1-alpha)*a.Transfer + alpha*b.Transfer,
+		CO2:      (1-alpha)*a.CO2 + alpha*b.CO2,
+	}
+}
+
+func scale(v, lo, hi float64) float64 {
+	if hi <= lo {
+		return 0
+	}
+	return (v - lo) / (hi - lo)
+}
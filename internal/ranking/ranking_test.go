@@ -0,0 +1,54 @@
+package ranking
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+func TestWithCO2(t *testing.T) {
+	tests := []struct {
+		name string
+		w    route_service.RoutingWeights
+		co2  float64
+		want route_service.RoutingWeights
+	}{
+		{
+			name: "blended weights without CO2 make room for it",
+			w:    route_service.RoutingWeights{Time: 0.5, Cost: 0.5},
+			co2:  0.5,
+			want: route_service.RoutingWeights{Time: 0.25, Cost: 0.25, CO2: 0.5},
+		},
+		{
+			name: "a diluted CO2 share is restored",
+			w:    route_service.RoutingWeights{Time: 0.6, Walk: 0.2, CO2: 0.2},
+			co2:  0.4,
+			want: route_service.RoutingWeights{Time: 0.45, Walk: 0.15, CO2: 0.4},
+		},
+		{
+			name: "no CO2 weight",
+			w:    route_service.RoutingWeights{Time: 0.6, Walk: 0.2, CO2: 0.2},
+			co2:  0,
+			want: route_service.RoutingWeights{Time: 0.75, Walk: 0.25},
+		},
+		{
+			name: "only CO2",
+			w:    route_service.RoutingWeights{Time: 1},
+			co2:  1,
+			want: route_service.RoutingWeights{CO2: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		got := WithCO2(tt.w, tt.co2)
+		if !near(got.Time, tt.want.Time) || !near(got.Cost, tt.want.Cost) || !near(got.Walk, tt.want.Walk) ||
+			!near(got.Transfer, tt.want.Transfer) || !near(got.CO2, tt.want.CO2) {
+			t.Errorf("%s: WithCO2 = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
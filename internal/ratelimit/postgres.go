@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PostgresStore keeps buckets in the database so limits are shared by all instances
+type PostgresStore struct {
+	store *postgres.Store
+}
+
+func NewPostgresStore(store *postgres.Store) *PostgresStore {
+	return &PostgresStore{
+		store: store,
+	}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	row, err := s.store.TakeRateLimitToken(ctx, database.TakeRateLimitTokenParams{
+		Key:   key,
+		Burst: float64(limit.Burst),
+		Rate:  limit.Rate,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return result(row.Allowed, row.Tokens, limit), nil
+}
+
+func (s *PostgresStore) Peek(ctx context.Context, key string, limit Limit) (Result, error) {
+	tokens, err := s.store.PeekRateLimitTokens(ctx, database.PeekRateLimitTokensParams{
+		Key:   key,
+		Burst: float64(limit.Burst),
+		Rate:  limit.Rate,
+	})
+	if postgres.IsNotFound(err) {
+		return result(true, float64(limit.Burst), limit), nil
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return result(tokens >= 1, tokens, limit), nil
+}
+
+func (s *PostgresStore) Purge(ctx context.Context, idleSince time.Time) error {
+	return s.store.DeleteIdleRateLimitBuckets(ctx, pgtype.Timestamptz{Time: idleSince, Valid: true})
+}
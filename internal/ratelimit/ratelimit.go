@@ -0,0 +1,217 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+)
+
+var ErrUnknownBackend = errors.New("unknown rate limit backend")
+
+// Limit is a token bucket holding up to Burst tokens refilled at Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute returns a limit of n requests per minute that allows bursts of n
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+// Window is the time it takes to refill an empty bucket
+func (l Limit) Window() time.Duration {
+	if l.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next token is available
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Store keeps token buckets, implementations must be safe for concurrent use
+type Store interface {
+	// Take refills the bucket for key and takes one token if available
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Peek reports whether Take would be allowed without taking a token
+	Peek(ctx context.Context, key string, limit Limit) (Result, error)
+	// Purge deletes buckets not used since idleSince
+	Purge(ctx context.Context, idleSince time.Time) error
+}
+
+// NewStore returns the store for backend, either "memory" or "postgres"
+func NewStore(backend string, store *postgres.Store) (Store, error) {
+	switch backend {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		return NewPostgresStore(store), nil
+	default:
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownBackend, backend)
+	}
+}
+
+// Rule applies a limit to every path starting with Prefix
+type Rule struct {
+	Prefix string
+	Limit  Limit
+}
+
+// Limiter picks the rule for a request path and takes tokens from the store
+type Limiter struct {
+	store    Store
+	rules    []Rule
+	fallback Limit
+}
+
+// NewLimiter creates a limiter, rules are matched by longest prefix and
+// fallback applies when none matches
+func NewLimiter(store Store, rules []Rule, fallback Limit) *Limiter {
+	sorted := make([]Rule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+	return &Limiter{
+		store:    store,
+		rules:    sorted,
+		fallback: fallback,
+	}
+}
+
+// Rule returns the rule matching path. Prefixes match whole path segments,
+// /api/v1/route covers /api/v1/route/... but not /api/v1/route-submissions
+func (l *Limiter) Rule(path string) Rule {
+	for _, rule := range l.rules {
+		prefix := strings.TrimSuffix(rule.Prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return rule
+		}
+	}
+	return Rule{Prefix: "*", Limit: l.fallback}
+}
+
+// Take takes a token from the bucket of identity for the given rule
+func (l *Limiter) Take(ctx context.Context, identity string, rule Rule) (Result, error) {
+	if rule.Limit.Burst <= 0 {
+		return Result{Allowed: true}, nil
+	}
+	return l.store.Take(ctx, rule.Prefix+"|"+identity, rule.Limit)
+}
+
+// Peek reports whether identity has a token left for the rule without taking it
+func (l *Limiter) Peek(ctx context.Context, identity string, rule Rule) (Result, error) {
+	if rule.Limit.Burst <= 0 {
+		return Result{Allowed: true}, nil
+	}
+	return l.store.Peek(ctx, rule.Prefix+"|"+identity, rule.Limit)
+}
+
+// PurgeIdle periodically drops buckets that have been full for a while
+// until ctx is done
+func (l *Limiter) PurgeIdle(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.store.Purge(ctx, time.Now().Add(-l.maxWindow())); err != nil {
+				log.Printf("Error purging rate limit buckets: %v", err)
+			}
+		}
+	}
+}
+
+// maxWindow is the longest refill time across rules, a bucket idle for
+// longer than that is full and can be dropped
+func (l *Limiter) maxWindow() time.Duration {
+	longest := l.fallback.Window()
+	for _, rule := range l.rules {
+		longest = max(longest, rule.Limit.Window())
+	}
+	return max(longest, time.Minute)
+}
+
+// ParseRules parses a comma separated list of prefix=requests_per_minute
+// entries, with an optional :burst suffix of at least 1. A rate of 0 turns
+// limiting off for the prefix. The prefix "*" sets the fallback,
+// e.g. "/api/v1/route=30,/api/v1/health=600:100,*=300"
+func ParseRules(spec string) ([]Rule, Limit, error) {
+	var rules []Rule
+	var fallback Limit
+
+	for entry := range strings.SplitSeq(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		prefix, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, Limit{}, fmt.Errorf("invalid rate limit rule %q", entry)
+		}
+
+		perMinute, burstStr, hasBurst := strings.Cut(value, ":")
+		n, err := strconv.Atoi(perMinute)
+		if err != nil || n < 0 {
+			return nil, Limit{}, fmt.Errorf("invalid rate in rule %q", entry)
+		}
+
+		limit := PerMinute(n)
+		if hasBurst {
+			burst, err := strconv.Atoi(burstStr)
+			if err != nil || burst < 1 {
+				return nil, Limit{}, fmt.Errorf("invalid burst in rule %q", entry)
+			}
+			limit.Burst = burst
+		}
+
+		if prefix == "*" {
+			fallback = limit
+			continue
+		}
+		rules = append(rules, Rule{Prefix: prefix, Limit: limit})
+	}
+
+	return rules, fallback, nil
+}
+
+// result builds a Result from the tokens left after a take
+func result(allowed bool, tokens float64, limit Limit) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: max(0, int(math.Floor(tokens))),
+	}
+	if limit.Rate > 0 {
+		res.ResetAfter = secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate)
+		if !allowed {
+			res.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+		}
+	}
+	return res
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Max(0, s) * float64(time.Second))
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLimiterRule(t *testing.T) {
+	rules, fallback, err := ParseRules("/api/v1/route=30,/api/v1/route-submissions=10,/api/v1/itinerary/=5,*=300")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLimiter(NewMemoryStore(), rules, fallback)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/route", "/api/v1/route"},
+		{"/api/v1/route/", "/api/v1/route"},
+		{"/api/v1/route/batch", "/api/v1/route"},
+		{"/api/v1/route-submissions", "/api/v1/route-submissions"},
+		{"/api/v1/route-submissions/7/approve", "/api/v1/route-submissions"},
+		{"/api/v1/routes", "*"},
+		{"/api/v1/itinerary", "/api/v1/itinerary/"},
+		{"/api/v1/itinerary/plan", "/api/v1/itinerary/"},
+		{"/api/v1/health", "*"},
+	}
+	for _, tt := range tests {
+		if got := l.Rule(tt.path).Prefix; got != tt.want {
+			t.Errorf("Rule(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStorePeek(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	limit := Limit{Rate: 0, Burst: 2}
+
+	for i, want := range []bool{true, true, false} {
+		peek, _ := s.Peek(ctx, "k", limit)
+		if peek.Allowed != want {
+			t.Errorf("peek %d allowed = %v, want %v", i, peek.Allowed, want)
+		}
+		take, _ := s.Take(ctx, "k", limit)
+		if take.Allowed != want {
+			t.Errorf("take %d allowed = %v, want %v", i, take.Allowed, want)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+const (
+	SourceCrowd = "crowd"
+
+	// clusterRadiusMeters groups rider reports into a single vehicle
+	clusterRadiusMeters = 150
+	// maxAccuracyMeters rejects fixes too coarse to place a vehicle
+	maxAccuracyMeters = 100
+	// maxSpeedMps rejects jumps no road vehicle can make between two reports
+	maxSpeedMps = 40
+	// minReportInterval drops reports sent faster than this by one rider
+	minReportInterval = 5 * time.Second
+	// headwayWindow is how far back vehicle sightings feed the headway estimate
+	headwayWindow = 2 * time.Hour
+)
+
+var ErrInvalidReport = errors.New("invalid position report")
+
+// PositionReport is a GPS fix sent by a rider on a trip
+type PositionReport struct {
+	TripID         string                   `json:"-"`
+	Reporter       string                   `json:"-"`
+	Coord          route_service.Coordinate `json:"coord"`
+	AccuracyMeters float64                  `json:"accuracy_meters,omitempty"`
+	Bearing        float64                  `json:"bearing,omitempty"`
+	SpeedMps       float64                  `json:"speed_mps,omitempty"`
+	Timestamp      time.Time                `json:"timestamp,omitzero"`
+}
+
+// CrowdTrip is the crowdsourced view of a trip
+type CrowdTrip struct {
+	TripID         string                          `json:"trip_id"`
+	Vehicles       []route_service.VehiclePosition `json:"vehicles"`
+	HeadwaySeconds int                             `json:"headway_seconds,omitempty"`
+}
+
+// Crowd aggregates rider position reports into estimated vehicle positions
+// and headways, safe for concurrent use
+type Crowd struct {
+	maxAge time.Duration
+
+	mu        sync.RWMutex
+	reports   map[string]map[string]PositionReport
+	sightings map[string][]time.Time
+}
+
+// NewCrowd creates an empty aggregator, reports older than maxAge are ignored
+func NewCrowd(maxAge time.Duration) *Crowd {
+	return &Crowd{
+		maxAge:    maxAge,
+		reports:   make(map[string]map[string]PositionReport),
+		sightings: make(map[string][]time.Time),
+	}
+}
+
+// Report validates and records a rider position report
+func (c *Crowd) Report(report PositionReport) error {
+	now := time.Now()
+	if report.Timestamp.IsZero() || report.Timestamp.After(now) {
+		report.Timestamp = now
+	}
+
+	point := toPoint(report.Coord)
+	switch {
+	case report.TripID == "":
+		return fmt.Errorf("%w: trip_id is required", ErrInvalidReport)
+	case !point.Valid() || (point.Lat == 0 && point.Lon == 0):
+		return fmt.Errorf("%w: coordinates out of range", ErrInvalidReport)
+	case report.AccuracyMeters > maxAccuracyMeters:
+		return fmt.Errorf("%w: accuracy must be %d meters or better", ErrInvalidReport, maxAccuracyMeters)
+	case now.Sub(report.Timestamp) > c.maxAge:
+		return fmt.Errorf("%w: report is too old", ErrInvalidReport)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	trip := c.reports[report.TripID]
+	if trip == nil {
+		trip = make(map[string]PositionReport)
+		c.reports[report.TripID] = trip
+	}
+
+	prev, seen := trip[report.Reporter]
+	if seen && now.Sub(prev.Timestamp) <= c.maxAge {
+		elapsed := report.Timestamp.Sub(prev.Timestamp)
+		if elapsed < minReportInterval {
+			// Too frequent, keep the previous fix
+			return nil
+		}
+		if geo.Distance(toPoint(prev.Coord), point)/elapsed.Seconds() > maxSpeedMps {
+			return fmt.Errorf("%w: implausible jump since the last report", ErrInvalidReport)
+		}
+	} else if !c.nearVehicle(trip, report.Reporter, point, now) {
+		// A rider who is not on a vehicle we already know about marks a new
+		// vehicle on the trip, the gaps between those are the headway
+		c.sightings[report.TripID] = append(c.sightings[report.TripID], report.Timestamp)
+	}
+
+	trip[report.Reporter] = report
+	return nil
+}
+
+// Trip returns the estimated vehicles and headway for a trip
+func (c *Crowd) Trip(tripID string) CrowdTrip {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	return CrowdTrip{
+		TripID:         tripID,
+		Vehicles:       c.vehicles(tripID, now),
+		HeadwaySeconds: c.headway(tripID, now),
+	}
+}
+
+// Purge drops expired reports and sightings every interval until ctx is done
+func (c *Crowd) Purge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.purge(time.Now())
+		}
+	}
+}
+
+func (c *Crowd) purge(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for tripID, trip := range c.reports {
+		for reporter, r := range trip {
+			if now.Sub(r.Timestamp) > c.maxAge {
+				delete(trip, reporter)
+			}
+		}
+		if len(trip) == 0 {
+			delete(c.reports, tripID)
+		}
+	}
+	for tripID, seen := range c.sightings {
+		seen = slices.DeleteFunc(seen, func(t time.Time) bool { return now.Sub(t) > headwayWindow })
+		if len(seen) == 0 {
+			delete(c.sightings, tripID)
+		} else {
+			c.sightings[tripID] = seen
+		}
+	}
+}
+
+// nearVehicle reports whether another rider recently reported a position
+// close to point
+func (c *Crowd) nearVehicle(trip map[string]PositionReport, reporter string, point geo.Point, now time.Time) bool {
+	for other, r := range trip {
+		if other != reporter && now.Sub(r.Timestamp) <= c.maxAge && geo.Distance(toPoint(r.Coord), point) <= clusterRadiusMeters {
+			return true
+		}
+	}
+	return false
+}
+
+// vehicles clusters the live reports of a trip, each cluster being one vehicle
+func (c *Crowd) vehicles(tripID string, now time.Time) []route_service.VehiclePosition {
+	var live []PositionReport
+	for _, r := range c.reports[tripID] {
+		if now.Sub(r.Timestamp) <= c.maxAge {
+			live = append(live, r)
+		}
+	}
+	// Freshest first so each cluster is anchored on its latest report
+	sort.Slice(live, func(i, j int) bool { return live[i].Timestamp.After(live[j].Timestamp) })
+
+	var clusters [][]PositionReport
+	for _, r := range live {
+		placed := false
+		for i, cluster := range clusters {
+			if geo.Distance(toPoint(cluster[0].Coord), toPoint(r.Coord)) <= clusterRadiusMeters {
+				clusters[i] = append(cluster, r)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			clusters = append(clusters, []PositionReport{r})
+		}
+	}
+
+	vehicles := make([]route_service.VehiclePosition, len(clusters))
+	for i, cluster := range clusters {
+		points := make([]geo.Point, len(cluster))
+		var speed float64
+		for k, r := range cluster {
+			points[k] = toPoint(r.Coord)
+			speed += r.SpeedMps
+		}
+		center := geo.Centroid(points)
+
+		vehicles[i] = route_service.VehiclePosition{
+			VehicleID: fmt.Sprintf("%s:%s:%d", SourceCrowd, tripID, i+1),
+			Coord:     route_service.Coordinate{Lat: center.Lat, Lon: center.Lon},
+			Bearing:   cluster[0].Bearing,
+			SpeedMps:  speed / float64(len(cluster)),
+			Timestamp: cluster[0].Timestamp,
+			Source:    SourceCrowd,
+			Reporters: len(cluster),
+		}
+	}
+	return vehicles
+}
+
+// headway is the median gap between vehicle sightings, or 0 when there are
+// too few to tell
+func (c *Crowd) headway(tripID string, now time.Time) int {
+	var seen []time.Time
+	for _, t := range c.sightings[tripID] {
+		if now.Sub(t) <= headwayWindow {
+			seen = append(seen, t)
+		}
+	}
+	if len(seen) < 3 {
+		return 0
+	}
+
+	slices.SortFunc(seen, func(a, b time.Time) int { return a.Compare(b) })
+	gaps := make([]float64, len(seen)-1)
+	for i := range gaps {
+		gaps[i] = seen[i+1].Sub(seen[i]).Seconds()
+	}
+	slices.Sort(gaps)
+	return int(gaps[len(gaps)/2])
+}
+
+func toPoint(c route_service.Coordinate) geo.Point {
+	return geo.Point{Lat: c.Lat, Lon: c.Lon}
+}
@@ -0,0 +1,91 @@
+package realtime
+
+import (
+	"context"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Router decorates another Router and annotates trip legs with live delays
+// and vehicle positions from the realtime state and rider reports
+type Router struct {
+	route_service.Router
+	state *State
+	crowd *Crowd
+}
+
+func NewRouter(inner route_service.Router, state *State, crowd *Crowd) *Router {
+	return &Router{
+		Router: inner,
+		state:  state,
+		crowd:  crowd,
+	}
+}
+
+func (r *Router) FindRoute(ctx context.Context, req route_service.RouteRequest) (route_service.RouteResponse, error) {
+	resp, err := r.Router.FindRoute(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, j := range resp.Journeys {
+		for _, leg := range j.Legs {
+			if leg.Trip != nil {
+				r.annotate(leg.Trip)
+			}
+		}
+	}
+	return resp, nil
+}
+
+func (r *Router) annotate(trip *route_service.TripLeg) {
+	delay, hasDelay := r.state.TripDelay(trip.TripID)
+	vehicle, hasVehicle := r.state.Vehicle(trip.TripID)
+	crowd := r.crowd.Trip(trip.TripID)
+	if !hasDelay && !hasVehicle && len(crowd.Vehicles) == 0 && crowd.HeadwaySeconds == 0 {
+		return
+	}
+
+	info := &route_service.RealtimeInfo{}
+	if hasDelay {
+		info.DelaySeconds = delay.DelaySeconds
+		// The delay at the boarding stop is what matters to the rider
+		if d, ok := delay.StopDelays[stopKey(trip.From.StopID)]; ok {
+			info.DelaySeconds = d
+		}
+		info.UpdatedAt = delay.Timestamp
+	}
+	if hasVehicle {
+		info.Vehicle = &vehicle
+		if vehicle.Timestamp.After(info.UpdatedAt) {
+			info.UpdatedAt = vehicle.Timestamp
+		}
+	}
+
+	info.Vehicles = crowd.Vehicles
+	info.HeadwaySeconds = crowd.HeadwaySeconds
+	if nearest := nearestVehicle(crowd.Vehicles, trip.From.Coord); !hasVehicle && nearest != nil {
+		// Without tracking hardware the closest rider-reported vehicle to the
+		// boarding stop is the best guess for the one the rider will take
+		info.Vehicle = nearest
+	}
+	for _, v := range crowd.Vehicles {
+		if v.Timestamp.After(info.UpdatedAt) {
+			info.UpdatedAt = v.Timestamp
+		}
+	}
+	trip.Realtime = info
+}
+
+func nearestVehicle(vehicles []route_service.VehiclePosition, stop route_service.Coordinate) *route_service.VehiclePosition {
+	var nearest *route_service.VehiclePosition
+	best := 0.0
+	for i := range vehicles {
+		d := geo.Distance(toPoint(vehicles[i].Coord), toPoint(stop))
+		if nearest == nil || d < best {
+			nearest, best = &vehicles[i], d
+		}
+	}
+	return nearest
+}
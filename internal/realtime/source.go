@@ -0,0 +1,106 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxFeedSize caps feeds read from disk, URLs or pushed over HTTP
+const maxFeedSize = 32 * 1024 * 1024
+
+// Decode parses a GTFS-RT feed, JSON feeds are accepted as well as the
+// standard protobuf encoding
+func Decode(data []byte, isJSON bool) (*gtfs.FeedMessage, error) {
+	var feed gtfs.FeedMessage
+	var err error
+	if isJSON {
+		err = protojson.Unmarshal(data, &feed)
+	} else {
+		err = proto.Unmarshal(data, &feed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode feed: %w", err)
+	}
+	return &feed, nil
+}
+
+// Poller periodically loads feeds from URLs or local files into a State
+type Poller struct {
+	state    *State
+	sources  []string
+	interval time.Duration
+	client   *http.Client
+}
+
+func NewPoller(state *State, sources []string, interval time.Duration) *Poller {
+	return &Poller{
+		state:    state,
+		sources:  sources,
+		interval: interval,
+		client:   &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// Run polls every source until ctx is done
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, source := range p.sources {
+			feed, err := p.load(ctx, source)
+			if err != nil {
+				log.Printf("Error loading realtime feed %s: %v", source, err)
+				continue
+			}
+			p.state.Apply(source, feed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Poller) load(ctx context.Context, source string) (*gtfs.FeedMessage, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		path := strings.TrimPrefix(source, "file://")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return Decode(data, strings.HasSuffix(path, ".json"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data, strings.Contains(resp.Header.Get("Content-Type"), "json"))
+}
@@ -0,0 +1,347 @@
+package realtime
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+)
+
+const SourceGTFSRealtime = "gtfs-rt"
+
+// TripDelay is the latest trip update for a trip
+type TripDelay struct {
+	DelaySeconds int
+	// StopDelays holds departure (or arrival) delays keyed by GTFS stop_id
+	StopDelays map[string]int
+	Timestamp  time.Time
+}
+
+// Status summarizes the realtime state
+type Status struct {
+	TripUpdates      int       `json:"trip_updates"`
+	VehiclePositions int       `json:"vehicle_positions"`
+	Alerts           int       `json:"alerts"`
+	LastUpdate       time.Time `json:"last_update,omitzero"`
+}
+
+// State keeps the latest realtime data in memory, safe for concurrent use.
+// Data is kept per source, producers usually publish trip updates, vehicle
+// positions and alerts as separate feeds
+type State struct {
+	maxAge time.Duration
+
+	mu         sync.RWMutex
+	feeds      map[string]*feedState
+	lastUpdate time.Time
+}
+
+// feedState is what one source has sent
+type feedState struct {
+	delays   map[string]TripDelay
+	vehicles map[string]route_service.VehiclePosition
+	alerts   map[string]feedAlert
+	// entities maps feed entity IDs to the trips they updated, so deletions
+	// that only carry the entity ID can be applied
+	entities map[string]entityRef
+}
+
+func newFeedState() *feedState {
+	return &feedState{
+		delays:   make(map[string]TripDelay),
+		vehicles: make(map[string]route_service.VehiclePosition),
+		alerts:   make(map[string]feedAlert),
+		entities: make(map[string]entityRef),
+	}
+}
+
+// feedAlert is an alert and when the feed last sent it
+type feedAlert struct {
+	alert    route_service.Alert
+	received time.Time
+}
+
+// entityRef is what a feed entity updated, empty trip IDs were not updated
+type entityRef struct {
+	delayTrip   string
+	vehicleTrip string
+}
+
+// NewState creates an empty state, entries older than maxAge are ignored
+// and dropped on the next feed
+func NewState(maxAge time.Duration) *State {
+	return &State{
+		maxAge: maxAge,
+		feeds:  make(map[string]*feedState),
+	}
+}
+
+// Apply merges a feed from source into the state. A FULL_DATASET feed
+// replaces everything the source sent before, even when it is empty, and
+// leaves other sources alone. A DIFFERENTIAL one upserts its entities and
+// removes the deleted ones
+func (s *State) Apply(source string, feed *gtfs.FeedMessage) {
+	s.apply(source, feed, time.Now())
+}
+
+func (s *State) apply(source string, feed *gtfs.FeedMessage, received time.Time) {
+	feedTime := received
+	if ts := feed.GetHeader().GetTimestamp(); ts > 0 {
+		feedTime = time.Unix(int64(ts), 0)
+	}
+	full := feed.GetHeader().GetIncrementality() == gtfs.FeedHeader_FULL_DATASET
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, ok := s.feeds[source]
+	if !ok || full {
+		f = newFeedState()
+		s.feeds[source] = f
+	}
+
+	for _, e := range feed.GetEntity() {
+		if e.GetIsDeleted() {
+			f.delete(e)
+			continue
+		}
+
+		ref := f.entities[e.GetId()]
+		if tu := e.GetTripUpdate(); tu != nil && tu.GetTrip().GetTripId() != "" {
+			ref.delayTrip = tu.GetTrip().GetTripId()
+			f.delays[ref.delayTrip] = toTripDelay(tu, feedTime)
+		}
+		if vp := e.GetVehicle(); vp != nil && vp.GetTrip().GetTripId() != "" && vp.GetPosition() != nil {
+			ref.vehicleTrip = vp.GetTrip().GetTripId()
+			f.vehicles[ref.vehicleTrip] = toVehiclePosition(vp, feedTime)
+		}
+		if a := e.GetAlert(); a != nil {
+			f.alerts[e.GetId()] = feedAlert{alert: toAlert(e.GetId(), a), received: feedTime}
+		}
+		if ref != (entityRef{}) {
+			f.entities[e.GetId()] = ref
+		}
+	}
+
+	if s.maxAge > 0 {
+		for _, f := range s.feeds {
+			f.evict(received.Add(-s.maxAge))
+		}
+	}
+	s.lastUpdate = received
+}
+
+// delete removes what a deleted entity refers to, either by the trip it
+// carries or by what the entity ID last updated
+func (s *feedState) delete(e *gtfs.FeedEntity) {
+	ref := s.entities[e.GetId()]
+	if tripID := e.GetTripUpdate().GetTrip().GetTripId(); tripID != "" {
+		ref.delayTrip = tripID
+	}
+	if tripID := e.GetVehicle().GetTrip().GetTripId(); tripID != "" {
+		ref.vehicleTrip = tripID
+	}
+
+	if ref.delayTrip != "" {
+		delete(s.delays, ref.delayTrip)
+	}
+	if ref.vehicleTrip != "" {
+		delete(s.vehicles, ref.vehicleTrip)
+	}
+	delete(s.alerts, e.GetId())
+	delete(s.entities, e.GetId())
+}
+
+// evict drops entries from before cutoff so the maps do not grow forever
+func (s *feedState) evict(cutoff time.Time) {
+	for id, d := range s.delays {
+		if d.Timestamp.Before(cutoff) {
+			delete(s.delays, id)
+		}
+	}
+	for id, v := range s.vehicles {
+		if v.Timestamp.Before(cutoff) {
+			delete(s.vehicles, id)
+		}
+	}
+	for id, a := range s.alerts {
+		if a.received.Before(cutoff) {
+			delete(s.alerts, id)
+		}
+	}
+	for id, ref := range s.entities {
+		_, hasDelay := s.delays[ref.delayTrip]
+		_, hasVehicle := s.vehicles[ref.vehicleTrip]
+		if !hasDelay && !hasVehicle {
+			delete(s.entities, id)
+		}
+	}
+}
+
+// TripDelay returns the latest fresh trip update for tripID from any source
+func (s *State) TripDelay(tripID string) (TripDelay, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest TripDelay
+	found := false
+	for _, f := range s.feeds {
+		d, ok := f.delays[tripID]
+		if ok && !s.stale(d.Timestamp) && (!found || d.Timestamp.After(latest.Timestamp)) {
+			latest, found = d, true
+		}
+	}
+	return latest, found
+}
+
+// Vehicle returns the latest fresh vehicle position for tripID from any source
+func (s *State) Vehicle(tripID string) (route_service.VehiclePosition, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest route_service.VehiclePosition
+	found := false
+	for _, f := range s.feeds {
+		v, ok := f.vehicles[tripID]
+		if ok && !s.stale(v.Timestamp) && (!found || v.Timestamp.After(latest.Timestamp)) {
+			latest, found = v, true
+		}
+	}
+	return latest, found
+}
+
+// Alerts returns the alerts active at t
+func (s *State) Alerts(t time.Time) []route_service.Alert {
+	return s.AlertsBetween(t, t)
+}
+
+// AlertsBetween returns the alerts active at any time from from to to
+func (s *State) AlertsBetween(from, to time.Time) []route_service.Alert {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []route_service.Alert
+	for _, f := range s.feeds {
+		for _, a := range f.alerts {
+			if a.alert.ActiveDuring(from, to) && !s.stale(a.received) {
+				result = append(result, a.alert)
+			}
+		}
+	}
+	return result
+}
+
+func (s *State) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{LastUpdate: s.lastUpdate}
+	for _, f := range s.feeds {
+		status.TripUpdates += len(f.delays)
+		status.VehiclePositions += len(f.vehicles)
+		status.Alerts += len(f.alerts)
+	}
+	return status
+}
+
+func (s *State) stale(t time.Time) bool {
+	return s.maxAge > 0 && time.Since(t) > s.maxAge
+}
+
+func toTripDelay(tu *gtfs.TripUpdate, feedTime time.Time) TripDelay {
+	d := TripDelay{
+		DelaySeconds: int(tu.GetDelay()),
+		StopDelays:   make(map[string]int),
+		Timestamp:    timestampOr(tu.GetTimestamp(), feedTime),
+	}
+	for _, stu := range tu.GetStopTimeUpdate() {
+		event := stu.GetDeparture()
+		if event == nil {
+			event = stu.GetArrival()
+		}
+		if event == nil || stu.GetStopId() == "" {
+			continue
+		}
+		d.StopDelays[stu.GetStopId()] = int(event.GetDelay())
+	}
+	return d
+}
+
+func toVehiclePosition(vp *gtfs.VehiclePosition, feedTime time.Time) route_service.VehiclePosition {
+	pos := vp.GetPosition()
+	return route_service.VehiclePosition{
+		VehicleID: vp.GetVehicle().GetId(),
+		Coord: route_service.Coordinate{
+			Lat: float64(pos.GetLatitude()),
+			Lon: float64(pos.GetLongitude()),
+		},
+		Bearing:   float64(pos.GetBearing()),
+		SpeedMps:  float64(pos.GetSpeed()),
+		Timestamp: timestampOr(vp.GetTimestamp(), feedTime),
+		Source:    SourceGTFSRealtime,
+	}
+}
+
+// toAlert converts a feed alert, non-numeric stop_ids are dropped
+func toAlert(id string, a *gtfs.Alert) route_service.Alert {
+	alert := route_service.Alert{
+		ID:          id,
+		Source:      SourceGTFSRealtime,
+		Cause:       a.GetCause().String(),
+		Effect:      a.GetEffect().String(),
+		Severity:    a.GetSeverityLevel().String(),
+		Header:      translations(a.GetHeaderText()),
+		Description: translations(a.GetDescriptionText()),
+	}
+	for _, e := range a.GetInformedEntity() {
+		if e.GetRouteId() != "" {
+			alert.Routes = append(alert.Routes, e.GetRouteId())
+		}
+		if e.GetTrip().GetTripId() != "" {
+			alert.TripIDs = append(alert.TripIDs, e.GetTrip().GetTripId())
+		}
+		if stopID, err := strconv.Atoi(e.GetStopId()); err == nil {
+			alert.StopIDs = append(alert.StopIDs, stopID)
+		}
+	}
+	for _, p := range a.GetActivePeriod() {
+		var period route_service.AlertPeriod
+		if p.GetStart() > 0 {
+			period.Start = time.Unix(int64(p.GetStart()), 0)
+		}
+		if p.GetEnd() > 0 {
+			period.End = time.Unix(int64(p.GetEnd()), 0)
+		}
+		alert.ActivePeriods = append(alert.ActivePeriods, period)
+	}
+	return alert
+}
+
+func translations(ts *gtfs.TranslatedString) map[string]string {
+	if ts == nil {
+		return nil
+	}
+	result := make(map[string]string)
+	for _, t := range ts.GetTranslation() {
+		lang := t.GetLanguage()
+		if lang == "" {
+			lang = "und"
+		}
+		result[lang] = t.GetText()
+	}
+	return result
+}
+
+func timestampOr(ts uint64, fallback time.Time) time.Time {
+	if ts == 0 {
+		return fallback
+	}
+	return time.Unix(int64(ts), 0)
+}
+
+// stopKey converts a domain stop ID to the GTFS stop_id used in feeds
+func stopKey(id int) string {
+	return strconv.Itoa(id)
+}
@@ -0,0 +1,160 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"google.golang.org/protobuf/proto"
+)
+
+func feed(incrementality gtfs.FeedHeader_Incrementality, at time.Time, entities ...*gtfs.FeedEntity) *gtfs.FeedMessage {
+	return &gtfs.FeedMessage{
+		Header: &gtfs.FeedHeader{
+			GtfsRealtimeVersion: proto.String("2.0"),
+			Incrementality:      incrementality.Enum(),
+			Timestamp:           proto.Uint64(uint64(at.Unix())),
+		},
+		Entity: entities,
+	}
+}
+
+func tripUpdate(id, tripID string) *gtfs.FeedEntity {
+	return &gtfs.FeedEntity{
+		Id: proto.String(id),
+		TripUpdate: &gtfs.TripUpdate{
+			Trip:  &gtfs.TripDescriptor{TripId: proto.String(tripID)},
+			Delay: proto.Int32(60),
+		},
+	}
+}
+
+func vehicle(id, tripID string) *gtfs.FeedEntity {
+	return &gtfs.FeedEntity{
+		Id: proto.String(id),
+		Vehicle: &gtfs.VehiclePosition{
+			Trip:     &gtfs.TripDescriptor{TripId: proto.String(tripID)},
+			Position: &gtfs.Position{Latitude: proto.Float32(30), Longitude: proto.Float32(31)},
+		},
+	}
+}
+
+func alert(id string) *gtfs.FeedEntity {
+	return &gtfs.FeedEntity{
+		Id: proto.String(id),
+		Alert: &gtfs.Alert{
+			InformedEntity: []*gtfs.EntitySelector{{RouteId: proto.String("R1")}},
+		},
+	}
+}
+
+func deleted(id string) *gtfs.FeedEntity {
+	return &gtfs.FeedEntity{Id: proto.String(id), IsDeleted: proto.Bool(true)}
+}
+
+// update is a feed applied from a source
+type update struct {
+	source string
+	feed   *gtfs.FeedMessage
+}
+
+func TestStateApply(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	full := gtfs.FeedHeader_FULL_DATASET
+	diff := gtfs.FeedHeader_DIFFERENTIAL
+
+	tests := []struct {
+		name    string
+		updates []update
+		want    Status
+	}{
+		{
+			name: "full dataset loads every entity type",
+			updates: []update{
+				{"a", feed(full, start, tripUpdate("1", "T1"), vehicle("2", "T1"), alert("3"))},
+			},
+			want: Status{TripUpdates: 1, VehiclePositions: 1, Alerts: 1},
+		},
+		{
+			name: "empty full dataset clears its source",
+			updates: []update{
+				{"a", feed(full, start, tripUpdate("1", "T1"), vehicle("2", "T1"), alert("3"))},
+				{"a", feed(full, start.Add(time.Minute))},
+			},
+			want: Status{},
+		},
+		{
+			name: "full dataset replaces what its source sent before",
+			updates: []update{
+				{"a", feed(full, start, tripUpdate("1", "T1"), vehicle("2", "T1"), alert("3"))},
+				{"a", feed(full, start.Add(time.Minute), tripUpdate("1", "T2"))},
+			},
+			want: Status{TripUpdates: 1},
+		},
+		{
+			name: "full datasets from separate sources are kept side by side",
+			updates: []update{
+				{"trips", feed(full, start, tripUpdate("1", "T1"), tripUpdate("2", "T2"))},
+				{"vehicles", feed(full, start, vehicle("1", "T1"))},
+				{"alerts", feed(full, start, alert("1"))},
+				{"trips", feed(full, start.Add(time.Minute), tripUpdate("1", "T1"))},
+			},
+			want: Status{TripUpdates: 1, VehiclePositions: 1, Alerts: 1},
+		},
+		{
+			name: "differential upserts",
+			updates: []update{
+				{"a", feed(full, start, tripUpdate("1", "T1"))},
+				{"a", feed(diff, start.Add(time.Minute), tripUpdate("2", "T2"), alert("3"))},
+			},
+			want: Status{TripUpdates: 2, Alerts: 1},
+		},
+		{
+			name: "differential deletes every entity type by entity ID",
+			updates: []update{
+				{"a", feed(full, start, tripUpdate("1", "T1"), vehicle("2", "T2"), alert("3"), tripUpdate("4", "T4"))},
+				{"a", feed(diff, start.Add(time.Minute), deleted("1"), deleted("2"), deleted("3"))},
+			},
+			want: Status{TripUpdates: 1},
+		},
+		{
+			name: "entries older than the max age are evicted from every source",
+			updates: []update{
+				{"a", feed(full, start, tripUpdate("1", "T1"), vehicle("2", "T1"), alert("3"))},
+				{"b", feed(diff, start.Add(15*time.Minute), tripUpdate("4", "T4"))},
+			},
+			want: Status{TripUpdates: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewState(10 * time.Minute)
+			var last time.Time
+			for _, u := range tt.updates {
+				last = time.Unix(int64(u.feed.GetHeader().GetTimestamp()), 0)
+				s.apply(u.source, u.feed, last)
+			}
+
+			got := s.Status()
+			got.LastUpdate = time.Time{}
+			if got != tt.want {
+				t.Errorf("status = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateTripDelayLatestSource(t *testing.T) {
+	start := time.Now().Add(-time.Minute).Truncate(time.Second)
+	full := gtfs.FeedHeader_FULL_DATASET
+
+	s := NewState(10 * time.Minute)
+	s.apply("a", feed(full, start.Add(30*time.Second), tripUpdate("1", "T1")), start)
+	s.apply("b", feed(full, start, tripUpdate("1", "T1")), start)
+
+	d, ok := s.TripDelay("T1")
+	if !ok || !d.Timestamp.Equal(start.Add(30*time.Second)) {
+		t.Errorf("TripDelay = %+v, %v, want the update from source a", d, ok)
+	}
+}
@@ -1,10 +1,21 @@
 package server
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/ratelimit"
+	"github.com/Marwan051/final_project_backend/internal/service/apikey_service"
+	"github.com/Marwan051/final_project_backend/internal/utils"
 )
 
 type Middleware func(http.Handler) http.Handler
@@ -58,7 +69,8 @@ func Headers(next http.Handler) http.Handler {
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*") // TODO: configure allowed origins
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+		w.Header().Set("Access-Control-Expose-Headers", "RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight requests
@@ -70,3 +82,182 @@ func Headers(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Authenticate populates the request context with the principal from a
+// Bearer token. Requests without a token pass through anonymously, requests
+// with an invalid one are rejected
+func Authenticate(tokens *auth.TokenManager) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				utils.WriteJSONError(w, http.StatusUnauthorized, "Authorization header must use the Bearer scheme")
+				return
+			}
+
+			principal, err := tokens.ParseAccessToken(token)
+			if err != nil {
+				utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// invalidKeyRule throttles clients sending keys that do not authenticate, so
+// guessing keys cannot hammer the key lookups
+var invalidKeyRule = ratelimit.Rule{Prefix: "invalid-api-key", Limit: ratelimit.PerMinute(10)}
+
+// APIKeys authenticates integrators sending an X-API-Key header, applies the
+// key's own per-minute limit and then its daily quota, so throttled calls do
+// not use up the quota. Requests without the header pass through as the
+// anonymous tier, RateLimit throttles them per client IP. Failed lookups
+// take from a per-IP bucket of their own and are refused once it is empty
+func APIKeys(keys *apikey_service.Service, limiter *ratelimit.Limiter, trustProxy bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := r.Header.Get("X-API-Key")
+			if raw == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ip := "ip:" + clientIP(r, trustProxy)
+			if res, err := limiter.Peek(r.Context(), ip, invalidKeyRule); err == nil && !res.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+				utils.WriteJSONError(w, http.StatusTooManyRequests, "Too many invalid API keys")
+				return
+			}
+
+			principal, err := keys.Authenticate(r.Context(), raw)
+			switch {
+			case errors.Is(err, apikey_service.ErrInvalidKey):
+				if take(w, r, limiter, ip, invalidKeyRule) {
+					utils.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+				}
+				return
+			case err != nil:
+				log.Printf("Error authenticating api key: %v", err)
+				utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to authenticate API key")
+				return
+			}
+
+			if principal.RateLimitPerMinute > 0 {
+				// A key's own limit is shared across every route
+				rule := ratelimit.Rule{Prefix: "*", Limit: ratelimit.PerMinute(principal.RateLimitPerMinute)}
+				if !take(w, r, limiter, "key:"+strconv.FormatInt(principal.APIKeyID, 10), rule) {
+					return
+				}
+			}
+
+			err = keys.RecordUsage(r.Context(), principal)
+			switch {
+			case errors.Is(err, apikey_service.ErrQuotaExceeded):
+				w.Header().Set("Retry-After", strconv.Itoa(secondsUntilMidnightUTC()))
+				utils.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+				return
+			case err != nil:
+				log.Printf("Error recording api key usage: %v", err)
+				utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to authenticate API key")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RateLimit applies the limiter's per-route token buckets, keyed by the
+// signed-in user or else the client IP. Requests with an X-API-Key header
+// are left to APIKeys, which applies the key's own limit
+func RateLimit(limiter *ratelimit.Limiter, trustProxy bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-API-Key") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity := "ip:" + clientIP(r, trustProxy)
+			if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+				identity = "user:" + strconv.FormatInt(principal.UserID, 10)
+			}
+
+			if take(w, r, limiter, identity, limiter.Rule(r.URL.Path)) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// take takes a token for identity and sets the rate limit headers. It writes
+// the 429 response and returns false when the request has to be rejected
+func take(w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter, identity string, rule ratelimit.Rule) bool {
+	res, err := limiter.Take(r.Context(), identity, rule)
+	if err != nil {
+		// Fail open, an unavailable limiter backend must not take the API down
+		log.Printf("Error checking rate limit: %v", err)
+		return true
+	}
+
+	if res.Limit > 0 {
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+		w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", res.Limit, ceilSeconds(rule.Limit.Window())))
+	}
+
+	if !res.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(res.RetryAfter))))
+		utils.WriteJSONError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+		return false
+	}
+	return true
+}
+
+// clientIP returns the caller's address. Behind a trusted proxy it walks
+// X-Forwarded-For from the right, skipping private and loopback hops added
+// by the proxies, and returns the first public one. Entries further left
+// are set by the client and can not be trusted
+func clientIP(r *http.Request, trustProxy bool) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !trustProxy {
+		return host
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		ip := net.ParseIP(hop)
+		if ip == nil {
+			// A malformed entry means the rest of the header can not be trusted
+			break
+		}
+		host = hop
+		if !ip.IsPrivate() && !ip.IsLoopback() {
+			break
+		}
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func secondsUntilMidnightUTC() int {
+	now := time.Now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return int(midnight.Sub(now).Seconds()) + 1
+}
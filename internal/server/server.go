@@ -4,23 +4,27 @@ import (
 	"net/http"
 
 	v1 "github.com/Marwan051/final_project_backend/internal/api/v1"
-	"github.com/Marwan051/final_project_backend/internal/service/route_service"
 )
 
 // NewHandler creates the application's HTTP handler with middleware
-func NewHandler(routingService route_service.Router) http.Handler {
+func NewHandler(deps v1.Dependencies) http.Handler {
 	// Create v1 router with dependencies
-	v1Router := v1.NewRouter(routingService)
+	v1Router := v1.NewRouter(deps)
 
-	// Main router
+	// Main router. Short links live outside the versioned API so they stay
+	// short, the v1 router serves them unprefixed
 	mux := http.NewServeMux()
 	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", v1Router))
+	mux.Handle("GET /j/{id}", v1Router)
 
 	// Apply middleware
 	handler := ChainMiddleware(mux,
 		Headers,
 		PanicRecover,
 		Logging,
+		Authenticate(deps.Tokens),
+		RateLimit(deps.RateLimiter, deps.TrustProxy),
+		APIKeys(deps.APIKeyService, deps.RateLimiter, deps.TrustProxy),
 	)
 
 	return handler
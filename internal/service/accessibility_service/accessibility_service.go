@@ -0,0 +1,120 @@
+package accessibility_service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// cacheTTL bounds how long accessibility data is served from memory after an edit
+const cacheTTL = 30 * time.Second
+
+// Service checks journeys against the accessibility attributes of the
+// stops and routes they use
+type Service struct {
+	indexes *network_service.Cache[*index]
+}
+
+func NewService(network *network_service.Service) *Service {
+	return &Service{
+		indexes: network_service.NewCache(network, cacheTTL, newIndex),
+	}
+}
+
+type index struct {
+	stops  map[int64]network_service.Stop
+	routes map[string]network_service.Route
+}
+
+// Filter drops the journeys that break the options and returns them with
+// the reason. Journeys relying on stops or routes without accessibility data
+// are kept but flagged
+func (s *Service) Filter(ctx context.Context, journeys []route_service.Journey, opts route_service.AccessibilityOptions) ([]route_service.Journey, []route_service.DroppedJourney, error) {
+	idx, err := s.indexes.Get(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load accessibility data: %w", err)
+	}
+
+	var dropped []route_service.DroppedJourney
+	kept := journeys[:0]
+	for _, j := range journeys {
+		reason, unverified := idx.check(j, opts)
+		if reason != "" {
+			dropped = append(dropped, route_service.Dropped(j, reason))
+			continue
+		}
+		j.AccessibilityUnverified = unverified
+		kept = append(kept, j)
+	}
+	return kept, dropped, nil
+}
+
+// check returns why a journey breaks the options, empty when it does not
+func (idx *index) check(j route_service.Journey, opts route_service.AccessibilityOptions) (reason string, unverified bool) {
+	avoidStairs := opts.AvoidStairs || opts.StepFreeOnly
+	avoidOverpasses := opts.AvoidOverpasses || opts.StepFreeOnly
+
+	if opts.MaxWalkMeters > 0 && float64(j.Summary.WalkingDistanceMeters) > opts.MaxWalkMeters {
+		return fmt.Sprintf("walks %d m, more than %.0f m", j.Summary.WalkingDistanceMeters, opts.MaxWalkMeters), false
+	}
+
+	for _, leg := range j.Legs {
+		trip := leg.Trip
+		if trip == nil {
+			continue
+		}
+
+		if opts.AccessibleVehiclesOnly || opts.StepFreeOnly {
+			route, found := idx.routes[trip.RouteShortName]
+			switch {
+			case !found || route.WheelchairAccessible == route_service.WheelchairUnknown:
+				unverified = true
+			case route.WheelchairAccessible == route_service.WheelchairInaccessible:
+				return fmt.Sprintf("route %s is not wheelchair accessible", trip.RouteShortName), false
+			}
+		}
+
+		for _, s := range []route_service.Stop{trip.From, trip.To} {
+			stop, found := idx.stops[int64(s.StopID)]
+			if !found {
+				unverified = true
+				continue
+			}
+			switch {
+			case avoidStairs && stop.HasStairs:
+				return fmt.Sprintf("stop %s has stairs", stop.Name), false
+			case avoidOverpasses && stop.HasOverpass:
+				return fmt.Sprintf("stop %s is reached by an overpass", stop.Name), false
+			}
+			if opts.StepFreeOnly {
+				switch stop.WheelchairBoarding {
+				case route_service.WheelchairUnknown:
+					unverified = true
+				case route_service.WheelchairInaccessible:
+					return fmt.Sprintf("stop %s has no wheelchair boarding", stop.Name), false
+				}
+			}
+		}
+	}
+	return "", unverified
+}
+
+func newIndex(snap network_service.Snapshot) *index {
+	idx := &index{
+		stops:  make(map[int64]network_service.Stop, len(snap.Stops)),
+		routes: make(map[string]network_service.Route, len(snap.Routes)),
+	}
+	for _, st := range snap.Stops {
+		idx.stops[st.ID] = st
+	}
+	for _, r := range snap.Routes {
+		if _, ok := idx.routes[r.ShortName]; !ok {
+			idx.routes[r.ShortName] = r
+		}
+	}
+
+	return idx
+}
@@ -0,0 +1,40 @@
+package accessibility_service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Router decorates another Router, removing journeys that do not meet the
+// request's accessibility options
+type Router struct {
+	route_service.Router
+	accessibility *Service
+}
+
+func NewRouter(inner route_service.Router, accessibility *Service) *Router {
+	return &Router{
+		Router:        inner,
+		accessibility: accessibility,
+	}
+}
+
+func (r *Router) FindRoute(ctx context.Context, req route_service.RouteRequest) (route_service.RouteResponse, error) {
+	resp, err := r.Router.FindRoute(ctx, req)
+	if err != nil || req.Accessibility == nil {
+		return resp, err
+	}
+
+	// Returning journeys a rider cannot take is worse than failing
+	journeys, dropped, err := r.accessibility.Filter(ctx, resp.Journeys, *req.Accessibility)
+	if err != nil {
+		return resp, fmt.Errorf("failed to check accessibility: %w", err)
+	}
+	resp.SetJourneys(journeys)
+	if req.Debug {
+		resp.Dropped = append(resp.Dropped, dropped...)
+	}
+	return resp, nil
+}
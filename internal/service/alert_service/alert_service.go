@@ -0,0 +1,326 @@
+package alert_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/realtime"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/MobilityData/gtfs-realtime-bindings/golang/gtfs"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	SourceAdmin = "admin"
+
+	// cacheTTL bounds how long published alerts are served from memory
+	cacheTTL = 30 * time.Second
+)
+
+var (
+	ErrNotFound     = errors.New("alert not found")
+	ErrInvalidAlert = errors.New("invalid alert")
+)
+
+// AlertParams is the editable part of an alert. Cause, effect and severity
+// use the GTFS-Realtime enum names, routes are GTFS route_ids
+type AlertParams struct {
+	Cause         string                      `json:"cause,omitempty"`
+	Effect        string                      `json:"effect,omitempty"`
+	Severity      string                      `json:"severity,omitempty"`
+	Header        map[string]string           `json:"header"`
+	Description   map[string]string           `json:"description,omitempty"`
+	Routes        []string                    `json:"routes,omitempty"`
+	TripIDs       []string                    `json:"trip_ids,omitempty"`
+	StopIDs       []int                       `json:"stop_ids,omitempty"`
+	ActivePeriods []route_service.AlertPeriod `json:"active_periods,omitempty"`
+}
+
+type Service struct {
+	store    *postgres.Store
+	realtime *realtime.State
+
+	mu       sync.Mutex
+	cached   []route_service.Alert
+	cachedAt time.Time
+}
+
+func NewService(store *postgres.Store, realtimeState *realtime.State) *Service {
+	return &Service{
+		store:    store,
+		realtime: realtimeState,
+	}
+}
+
+// List returns every published alert, including inactive ones
+func (s *Service) List(ctx context.Context) ([]route_service.Alert, error) {
+	rows, err := s.store.ListServiceAlerts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+
+	alerts := make([]route_service.Alert, len(rows))
+	for i, row := range rows {
+		if alerts[i], err = toAlert(row); err != nil {
+			return nil, err
+		}
+	}
+	return alerts, nil
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (route_service.Alert, error) {
+	row, err := s.store.GetServiceAlert(ctx, id)
+	if postgres.IsNotFound(err) {
+		return route_service.Alert{}, ErrNotFound
+	}
+	if err != nil {
+		return route_service.Alert{}, fmt.Errorf("failed to load alert: %w", err)
+	}
+	return toAlert(row)
+}
+
+func (s *Service) Create(ctx context.Context, createdBy int64, params AlertParams) (route_service.Alert, error) {
+	enc, err := encode(&params)
+	if err != nil {
+		return route_service.Alert{}, err
+	}
+
+	row, err := s.store.CreateServiceAlert(ctx, database.CreateServiceAlertParams{
+		Cause:         params.Cause,
+		Effect:        params.Effect,
+		Severity:      params.Severity,
+		Header:        enc.header,
+		Description:   enc.description,
+		Routes:        params.Routes,
+		TripIds:       params.TripIDs,
+		StopIds:       enc.stopIDs,
+		ActivePeriods: enc.periods,
+		CreatedBy:     pgtype.Int8{Int64: createdBy, Valid: createdBy != 0},
+	})
+	if err != nil {
+		return route_service.Alert{}, fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	s.invalidate()
+	return toAlert(row)
+}
+
+func (s *Service) Update(ctx context.Context, id int64, params AlertParams) (route_service.Alert, error) {
+	enc, err := encode(&params)
+	if err != nil {
+		return route_service.Alert{}, err
+	}
+
+	row, err := s.store.UpdateServiceAlert(ctx, database.UpdateServiceAlertParams{
+		ID:            id,
+		Cause:         params.Cause,
+		Effect:        params.Effect,
+		Severity:      params.Severity,
+		Header:        enc.header,
+		Description:   enc.description,
+		Routes:        params.Routes,
+		TripIds:       params.TripIDs,
+		StopIds:       enc.stopIDs,
+		ActivePeriods: enc.periods,
+	})
+	if postgres.IsNotFound(err) {
+		return route_service.Alert{}, ErrNotFound
+	}
+	if err != nil {
+		return route_service.Alert{}, fmt.Errorf("failed to update alert: %w", err)
+	}
+
+	s.invalidate()
+	return toAlert(row)
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	n, err := s.store.DeleteServiceAlert(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// Active returns published and realtime feed alerts active at t
+func (s *Service) Active(ctx context.Context, t time.Time) ([]route_service.Alert, error) {
+	return s.ActiveBetween(ctx, t, t)
+}
+
+// ActiveBetween returns published and realtime feed alerts active at any
+// time from from to to
+func (s *Service) ActiveBetween(ctx context.Context, from, to time.Time) ([]route_service.Alert, error) {
+	published, err := s.published(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []route_service.Alert
+	for _, a := range published {
+		if a.ActiveDuring(from, to) {
+			active = append(active, a)
+		}
+	}
+	if s.realtime != nil {
+		active = append(active, s.realtime.AlertsBetween(from, to)...)
+	}
+	return active, nil
+}
+
+// published returns the stored alerts, served from a short lived cache so
+// route requests do not hit the database every time
+func (s *Service) published(ctx context.Context) ([]route_service.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Since(s.cachedAt) < cacheTTL {
+		return s.cached, nil
+	}
+
+	rows, err := s.store.ListServiceAlerts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alerts: %w", err)
+	}
+
+	alerts := make([]route_service.Alert, 0, len(rows))
+	for _, row := range rows {
+		a, err := toAlert(row)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+
+	s.cached, s.cachedAt = alerts, time.Now()
+	return alerts, nil
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cached = nil
+}
+
+type encodedAlert struct {
+	header      []byte
+	description []byte
+	stopIDs     []int32
+	periods     []byte
+}
+
+// encode validates params, fills in defaults and encodes the JSON columns
+func encode(params *AlertParams) (encodedAlert, error) {
+	if params.Cause == "" {
+		params.Cause = gtfs.Alert_UNKNOWN_CAUSE.String()
+	}
+	if params.Effect == "" {
+		params.Effect = gtfs.Alert_UNKNOWN_EFFECT.String()
+	}
+	if params.Severity == "" {
+		params.Severity = gtfs.Alert_UNKNOWN_SEVERITY.String()
+	}
+
+	if _, ok := gtfs.Alert_Cause_value[params.Cause]; !ok {
+		return encodedAlert{}, fmt.Errorf("%w: unknown cause '%s'", ErrInvalidAlert, params.Cause)
+	}
+	if _, ok := gtfs.Alert_Effect_value[params.Effect]; !ok {
+		return encodedAlert{}, fmt.Errorf("%w: unknown effect '%s'", ErrInvalidAlert, params.Effect)
+	}
+	if _, ok := gtfs.Alert_SeverityLevel_value[params.Severity]; !ok {
+		return encodedAlert{}, fmt.Errorf("%w: unknown severity '%s'", ErrInvalidAlert, params.Severity)
+	}
+	if len(params.Header) == 0 {
+		return encodedAlert{}, fmt.Errorf("%w: header needs at least one translation", ErrInvalidAlert)
+	}
+	if len(params.Routes) == 0 && len(params.TripIDs) == 0 && len(params.StopIDs) == 0 {
+		return encodedAlert{}, fmt.Errorf("%w: at least one route, trip or stop must be affected", ErrInvalidAlert)
+	}
+	// An empty ID would match every trip the router sent without one
+	if slices.Contains(params.Routes, "") {
+		return encodedAlert{}, fmt.Errorf("%w: route IDs must not be empty", ErrInvalidAlert)
+	}
+	if slices.Contains(params.TripIDs, "") {
+		return encodedAlert{}, fmt.Errorf("%w: trip IDs must not be empty", ErrInvalidAlert)
+	}
+	for _, id := range params.StopIDs {
+		if id <= 0 || id > math.MaxInt32 {
+			return encodedAlert{}, fmt.Errorf("%w: invalid stop ID %d", ErrInvalidAlert, id)
+		}
+	}
+	for _, p := range params.ActivePeriods {
+		if !p.Start.IsZero() && !p.End.IsZero() && !p.End.After(p.Start) {
+			return encodedAlert{}, fmt.Errorf("%w: active period ends before it starts", ErrInvalidAlert)
+		}
+	}
+
+	if params.Description == nil {
+		params.Description = map[string]string{}
+	}
+	if params.Routes == nil {
+		params.Routes = []string{}
+	}
+	if params.TripIDs == nil {
+		params.TripIDs = []string{}
+	}
+	if params.ActivePeriods == nil {
+		params.ActivePeriods = []route_service.AlertPeriod{}
+	}
+
+	enc := encodedAlert{stopIDs: make([]int32, len(params.StopIDs))}
+	for i, id := range params.StopIDs {
+		enc.stopIDs[i] = int32(id)
+	}
+
+	var err error
+	if enc.header, err = json.Marshal(params.Header); err != nil {
+		return encodedAlert{}, err
+	}
+	if enc.description, err = json.Marshal(params.Description); err != nil {
+		return encodedAlert{}, err
+	}
+	if enc.periods, err = json.Marshal(params.ActivePeriods); err != nil {
+		return encodedAlert{}, err
+	}
+	return enc, nil
+}
+
+func toAlert(row database.ServiceAlert) (route_service.Alert, error) {
+	alert := route_service.Alert{
+		ID:       strconv.FormatInt(row.ID, 10),
+		Source:   SourceAdmin,
+		Cause:    row.Cause,
+		Effect:   row.Effect,
+		Severity: row.Severity,
+		Routes:   row.Routes,
+		TripIDs:  row.TripIds,
+		StopIDs:  make([]int, len(row.StopIds)),
+	}
+	for i, id := range row.StopIds {
+		alert.StopIDs[i] = int(id)
+	}
+
+	if err := json.Unmarshal(row.Header, &alert.Header); err != nil {
+		return route_service.Alert{}, fmt.Errorf("failed to decode alert header: %w", err)
+	}
+	if err := json.Unmarshal(row.Description, &alert.Description); err != nil {
+		return route_service.Alert{}, fmt.Errorf("failed to decode alert description: %w", err)
+	}
+	if err := json.Unmarshal(row.ActivePeriods, &alert.ActivePeriods); err != nil {
+		return route_service.Alert{}, fmt.Errorf("failed to decode alert periods: %w", err)
+	}
+	return alert, nil
+}
@@ -0,0 +1,84 @@
+package alert_service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Router decorates another Router, attaching active alerts to trip legs and
+// stops and optionally dropping journeys that use suspended routes or stops
+type Router struct {
+	route_service.Router
+	alerts *Service
+}
+
+func NewRouter(inner route_service.Router, alerts *Service) *Router {
+	return &Router{
+		Router: inner,
+		alerts: alerts,
+	}
+}
+
+func (r *Router) FindRoute(ctx context.Context, req route_service.RouteRequest) (route_service.RouteResponse, error) {
+	resp, err := r.Router.FindRoute(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	active, err := r.alerts.Active(ctx, time.Now())
+	if err != nil {
+		// Journeys without alerts are better than no journeys
+		log.Printf("Error loading active alerts: %v", err)
+		return resp, nil
+	}
+	if len(active) == 0 {
+		return resp, nil
+	}
+
+	kept := resp.Journeys[:0]
+	for _, j := range resp.Journeys {
+		suspended := ""
+		for _, leg := range j.Legs {
+			if leg.Trip != nil && annotate(leg.Trip, active) && suspended == "" {
+				suspended = leg.Trip.RouteShortName
+			}
+		}
+		if suspended != "" && req.ExcludeSuspendedRoutes {
+			if req.Debug {
+				resp.Dropped = append(resp.Dropped, route_service.Dropped(j, "route "+suspended+" has no service"))
+			}
+			continue
+		}
+		kept = append(kept, j)
+	}
+	resp.SetJourneys(kept)
+
+	return resp, nil
+}
+
+// annotate attaches matching alerts to the trip and its stops and reports
+// whether any of them suspends service on it
+func annotate(trip *route_service.TripLeg, active []route_service.Alert) bool {
+	suspended := false
+	for _, a := range active {
+		if !a.Affects(trip) {
+			continue
+		}
+		if a.OnTrip(trip) {
+			trip.Alerts = append(trip.Alerts, a)
+		}
+		if a.AtStop(trip.From.StopID) {
+			trip.From.Alerts = append(trip.From.Alerts, a)
+		}
+		if a.AtStop(trip.To.StopID) {
+			trip.To.Alerts = append(trip.To.Alerts, a)
+		}
+		if a.Effect == route_service.EffectNoService {
+			suspended = true
+		}
+	}
+	return suspended
+}
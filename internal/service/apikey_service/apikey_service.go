@@ -0,0 +1,247 @@
+package apikey_service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	keyPrefix    = "rk_"
+	prefixLength = len(keyPrefix) + 8
+)
+
+var (
+	ErrInvalidKey     = errors.New("invalid or revoked API key")
+	ErrNotFound       = errors.New("API key not found or already revoked")
+	ErrInvalidScope   = errors.New("unknown scope")
+	ErrQuotaExceeded  = errors.New("daily quota exceeded")
+	ErrInvalidRequest = errors.New("name is required and limits must be between 0 and 2147483647")
+	ErrUnknownOwner   = errors.New("owner does not exist")
+)
+
+// APIKey is the admin view of a key, the secret itself is only returned
+// once when it is issued or rotated
+type APIKey struct {
+	ID                 int64      `json:"id"`
+	Name               string     `json:"name"`
+	OwnerID            *int64     `json:"owner_id,omitempty"`
+	Prefix             string     `json:"prefix"`
+	Scopes             []string   `json:"scopes"`
+	DailyQuota         int        `json:"daily_quota"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	RequestsToday      int        `json:"requests_today"`
+	CreatedAt          time.Time  `json:"created_at"`
+	RotatedAt          *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty"`
+	Key                string     `json:"key,omitempty"`
+}
+
+// IssueParams describes a new key, zero limits mean unlimited
+type IssueParams struct {
+	Name               string   `json:"name"`
+	OwnerID            *int64   `json:"owner_id,omitempty"`
+	Scopes             []string `json:"scopes"`
+	DailyQuota         int      `json:"daily_quota"`
+	RateLimitPerMinute int      `json:"rate_limit_per_minute"`
+}
+
+type Service struct {
+	store *postgres.Store
+}
+
+func NewService(store *postgres.Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// Issue creates a key and returns it with its secret
+func (s *Service) Issue(ctx context.Context, params IssueParams) (APIKey, error) {
+	if params.Name == "" || params.DailyQuota < 0 || params.RateLimitPerMinute < 0 ||
+		params.DailyQuota > math.MaxInt32 || params.RateLimitPerMinute > math.MaxInt32 {
+		return APIKey{}, ErrInvalidRequest
+	}
+	for _, scope := range params.Scopes {
+		if !slices.Contains(auth.Scopes, scope) {
+			return APIKey{}, fmt.Errorf("%w '%s'", ErrInvalidScope, scope)
+		}
+	}
+	if params.Scopes == nil {
+		params.Scopes = []string{}
+	}
+
+	raw, err := newKey()
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	var ownerID pgtype.Int8
+	if params.OwnerID != nil {
+		ownerID = pgtype.Int8{Int64: *params.OwnerID, Valid: true}
+	}
+
+	row, err := s.store.CreateAPIKey(ctx, database.CreateAPIKeyParams{
+		Name:               params.Name,
+		OwnerID:            ownerID,
+		Prefix:             raw[:prefixLength],
+		KeyHash:            hashKey(raw),
+		Scopes:             params.Scopes,
+		DailyQuota:         int32(params.DailyQuota),
+		RateLimitPerMinute: int32(params.RateLimitPerMinute),
+	})
+	if postgres.IsForeignKeyViolation(err) {
+		return APIKey{}, ErrUnknownOwner
+	}
+	if err != nil {
+		return APIKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	key := toAPIKey(row, 0)
+	key.Key = raw
+	return key, nil
+}
+
+// List returns every key, including revoked ones
+func (s *Service) List(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.store.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys := make([]APIKey, len(rows))
+	for i, row := range rows {
+		keys[i] = toAPIKey(database.ApiKey{
+			ID:                 row.ID,
+			Name:               row.Name,
+			OwnerID:            row.OwnerID,
+			Prefix:             row.Prefix,
+			KeyHash:            row.KeyHash,
+			Scopes:             row.Scopes,
+			DailyQuota:         row.DailyQuota,
+			RateLimitPerMinute: row.RateLimitPerMinute,
+			CreatedAt:          row.CreatedAt,
+			RotatedAt:          row.RotatedAt,
+			RevokedAt:          row.RevokedAt,
+		}, int(row.RequestsToday))
+	}
+	return keys, nil
+}
+
+// Rotate replaces the secret of a key, the old secret stops working immediately
+func (s *Service) Rotate(ctx context.Context, id int64) (APIKey, error) {
+	raw, err := newKey()
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	row, err := s.store.RotateAPIKey(ctx, database.RotateAPIKeyParams{
+		ID:      id,
+		Prefix:  raw[:prefixLength],
+		KeyHash: hashKey(raw),
+	})
+	if postgres.IsNotFound(err) {
+		return APIKey{}, ErrNotFound
+	}
+	if err != nil {
+		return APIKey{}, fmt.Errorf("failed to rotate api key: %w", err)
+	}
+
+	key := toAPIKey(row, 0)
+	key.Key = raw
+	return key, nil
+}
+
+// Revoke permanently disables a key
+func (s *Service) Revoke(ctx context.Context, id int64) (APIKey, error) {
+	row, err := s.store.RevokeAPIKey(ctx, id)
+	if postgres.IsNotFound(err) {
+		return APIKey{}, ErrNotFound
+	}
+	if err != nil {
+		return APIKey{}, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	return toAPIKey(row, 0), nil
+}
+
+// Authenticate resolves a raw key into a principal. The key's limits are
+// carried on the principal, usage is recorded separately with RecordUsage
+func (s *Service) Authenticate(ctx context.Context, raw string) (auth.Principal, error) {
+	row, err := s.store.GetActiveAPIKeyByHash(ctx, hashKey(raw))
+	if postgres.IsNotFound(err) {
+		return auth.Principal{}, ErrInvalidKey
+	}
+	if err != nil {
+		return auth.Principal{}, fmt.Errorf("failed to load api key: %w", err)
+	}
+
+	return auth.Principal{
+		APIKeyID:           row.ID,
+		Scopes:             row.Scopes,
+		RateLimitPerMinute: int(row.RateLimitPerMinute),
+		DailyQuota:         int(row.DailyQuota),
+	}, nil
+}
+
+// RecordUsage counts a request against the key's quota for the current UTC
+// day. Requests over the quota are rejected and not counted
+func (s *Service) RecordUsage(ctx context.Context, principal auth.Principal) error {
+	_, err := s.store.IncrementAPIKeyUsage(ctx, database.IncrementAPIKeyUsageParams{
+		KeyID: principal.APIKeyID,
+		Quota: int32(principal.DailyQuota),
+	})
+	if postgres.IsNotFound(err) {
+		return ErrQuotaExceeded
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record api key usage: %w", err)
+	}
+	return nil
+}
+
+func toAPIKey(row database.ApiKey, requestsToday int) APIKey {
+	key := APIKey{
+		ID:                 row.ID,
+		Name:               row.Name,
+		Prefix:             row.Prefix,
+		Scopes:             row.Scopes,
+		DailyQuota:         int(row.DailyQuota),
+		RateLimitPerMinute: int(row.RateLimitPerMinute),
+		RequestsToday:      requestsToday,
+		CreatedAt:          row.CreatedAt.Time,
+	}
+	if row.OwnerID.Valid {
+		key.OwnerID = &row.OwnerID.Int64
+	}
+	if row.RotatedAt.Valid {
+		key.RotatedAt = &row.RotatedAt.Time
+	}
+	if row.RevokedAt.Valid {
+		key.RevokedAt = &row.RevokedAt.Time
+	}
+	return key
+}
+
+func newKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return keyPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,214 @@
+package fare_service
+
+import (
+	"math"
+	"slices"
+
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// baseKinds price a ride, in order of preference when equally specific
+var baseKinds = []string{network_service.FareZone, network_service.FareDistance, network_service.FareFlat}
+
+// Table is an index of the fare rules and the network data they refer to
+type Table struct {
+	routes    map[string]network_service.Route
+	stopZones map[int64]string
+	rules     map[string][]network_service.Fare
+}
+
+// NewTable indexes a network snapshot
+func NewTable(snap network_service.Snapshot) *Table {
+	t := &Table{
+		routes:    make(map[string]network_service.Route, len(snap.Routes)),
+		stopZones: make(map[int64]string, len(snap.Stops)),
+		rules:     make(map[string][]network_service.Fare),
+	}
+	for _, r := range snap.Routes {
+		if _, ok := t.routes[r.ShortName]; !ok {
+			t.routes[r.ShortName] = r
+		}
+	}
+	for _, s := range snap.Stops {
+		if s.Zone != "" {
+			t.stopZones[s.ID] = s.Zone
+		}
+	}
+	for _, f := range snap.Fares {
+		t.rules[f.Kind] = append(t.rules[f.Kind], f)
+	}
+	return t
+}
+
+// PriceJourney sets the fare of every trip leg the rules can price and
+// recomputes the journey cost. Legs no rule covers keep the fare the router
+// returned, legs covered by one of the rider's products are free and ride
+// legs add their own cost
+func (t *Table) PriceJourney(j *route_service.Journey, category string, products []route_service.FareProduct) {
+	var cost float64
+	var prev *route_service.TripLeg
+	elapsed := 0
+
+	for _, leg := range j.Legs {
+		trip := leg.Trip
+		if trip == nil {
+			if leg.Ride != nil {
+				cost += leg.Ride.Cost
+			}
+			elapsed += legMinutes(leg)
+			continue
+		}
+
+		if fare, ok := t.base(trip); ok {
+			if prev != nil {
+				fare = t.transfer(trip, fare, elapsed)
+			}
+			if category != "" {
+				fare = t.concession(trip, fare, category)
+			}
+			trip.Fare = round(fare)
+		}
+		if slices.ContainsFunc(products, func(p route_service.FareProduct) bool { return p.Covers(trip) }) {
+			trip.Fare = 0
+		}
+		cost += trip.Fare
+
+		prev = trip
+		elapsed = trip.DurationMinutes
+	}
+
+	j.Summary.Cost = round(cost)
+}
+
+// base finds the most specific flat, distance or zone rule for a ride
+func (t *Table) base(trip *route_service.TripLeg) (float64, bool) {
+	routeID := t.routes[trip.RouteShortName].ID
+
+	var best *network_service.Fare
+	bestScore := -1
+	for _, kind := range baseKinds {
+		for i := range t.rules[kind] {
+			rule := &t.rules[kind][i]
+			if kind == network_service.FareDistance && len(rule.Bands) == 0 {
+				continue
+			}
+			score, ok := specificity(rule, routeID, trip.Mode)
+			if !ok || score <= bestScore {
+				continue
+			}
+			if kind == network_service.FareZone && !t.zonesMatch(rule, trip) {
+				continue
+			}
+			best, bestScore = rule, score
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+
+	if best.Kind == network_service.FareDistance {
+		return band(best.Bands, trip.RideMeters()), true
+	}
+	return best.Amount, true
+}
+
+// transfer applies the most specific transfer discount for boarding trip
+// elapsed minutes after boarding the previous ride
+func (t *Table) transfer(trip *route_service.TripLeg, fare float64, elapsed int) float64 {
+	rule := t.best(network_service.FareTransfer, trip, func(r *network_service.Fare) bool {
+		return r.WithinMinutes == 0 || elapsed <= r.WithinMinutes
+	})
+	if rule == nil {
+		return fare
+	}
+	return discount(fare, rule)
+}
+
+func (t *Table) concession(trip *route_service.TripLeg, fare float64, category string) float64 {
+	rule := t.best(network_service.FareConcession, trip, func(r *network_service.Fare) bool {
+		return r.RiderCategory == category
+	})
+	if rule == nil {
+		return fare
+	}
+	return discount(fare, rule)
+}
+
+func (t *Table) best(kind string, trip *route_service.TripLeg, match func(*network_service.Fare) bool) *network_service.Fare {
+	routeID := t.routes[trip.RouteShortName].ID
+
+	var best *network_service.Fare
+	bestScore := -1
+	for i := range t.rules[kind] {
+		rule := &t.rules[kind][i]
+		if score, ok := specificity(rule, routeID, trip.Mode); ok && score > bestScore && match(rule) {
+			best, bestScore = rule, score
+		}
+	}
+	return best
+}
+
+// zonesMatch reports whether a zone rule covers the ride in either direction
+func (t *Table) zonesMatch(rule *network_service.Fare, trip *route_service.TripLeg) bool {
+	from, okFrom := t.stopZones[int64(trip.From.StopID)]
+	to, okTo := t.stopZones[int64(trip.To.StopID)]
+	if !okFrom || !okTo {
+		return false
+	}
+	return (rule.FromZone == from && rule.ToZone == to) || (rule.FromZone == to && rule.ToZone == from)
+}
+
+// specificity scores how closely a rule targets a ride, route rules beat
+// mode rules which beat network wide ones
+func specificity(rule *network_service.Fare, routeID int64, mode string) (int, bool) {
+	score := 0
+	if rule.RouteID != 0 {
+		if rule.RouteID != routeID {
+			return 0, false
+		}
+		score += 2
+	}
+	if rule.Mode != "" {
+		if rule.Mode != mode {
+			return 0, false
+		}
+		score++
+	}
+	return score, true
+}
+
+func band(bands []network_service.DistanceBand, meters float64) float64 {
+	if len(bands) == 0 {
+		return 0
+	}
+	for _, b := range bands {
+		if meters <= b.UpToMeters {
+			return b.Amount
+		}
+	}
+	// Longer rides pay the top band
+	return bands[len(bands)-1].Amount
+}
+
+func discount(fare float64, rule *network_service.Fare) float64 {
+	fare = fare*(1-rule.Percent/100) - rule.Amount
+	return math.Max(0, fare)
+}
+
+func legMinutes(leg route_service.Leg) int {
+	switch {
+	case leg.Walk != nil:
+		return leg.Walk.DurationMinutes
+	case leg.Transfer != nil:
+		return leg.Transfer.DurationMinutes
+	case leg.Ride != nil:
+		return leg.Ride.DurationMinutes
+	}
+	return 0
+}
+
+// round keeps fares to piasters
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
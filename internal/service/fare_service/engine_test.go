@@ -0,0 +1,208 @@
+package fare_service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Stops 1 and 2 are about 1.1 km apart, stop 3 about 11 km from stop 1
+var testStops = map[int]route_service.Coordinate{
+	1: {Lon: 31.2, Lat: 30.0},
+	2: {Lon: 31.2, Lat: 30.01},
+	3: {Lon: 31.2, Lat: 30.1},
+}
+
+func testSnapshot(fares ...network_service.FareParams) network_service.Snapshot {
+	snap := network_service.Snapshot{
+		Routes: []network_service.Route{
+			{ID: 1, ShortName: "A", Mode: "bus"},
+			{ID: 2, ShortName: "M1", Mode: "metro"},
+		},
+		Stops: []network_service.Stop{
+			{ID: 1, StopParams: network_service.StopParams{Zone: "1"}},
+			{ID: 2, StopParams: network_service.StopParams{Zone: "2"}},
+			{ID: 3},
+		},
+	}
+	for i, f := range fares {
+		snap.Fares = append(snap.Fares, network_service.Fare{ID: int64(i + 1), FareParams: f})
+	}
+	return snap
+}
+
+func ride(route, mode string, from, to, minutes int) route_service.Leg {
+	return route_service.Leg{Trip: &route_service.TripLeg{
+		RouteShortName:  route,
+		Mode:            mode,
+		DurationMinutes: minutes,
+		From:            route_service.Stop{StopID: from, Coord: testStops[from]},
+		To:              route_service.Stop{StopID: to, Coord: testStops[to]},
+	}}
+}
+
+func transferLeg(minutes int) route_service.Leg {
+	return route_service.Leg{Transfer: &route_service.TransferLeg{DurationMinutes: minutes}}
+}
+
+func TestPriceJourney(t *testing.T) {
+	flat := network_service.FareParams{Kind: network_service.FareFlat, Amount: 5}
+	bands := []network_service.DistanceBand{{UpToMeters: 1000, Amount: 4}, {UpToMeters: 5000, Amount: 8}}
+
+	tests := []struct {
+		name      string
+		fares     []network_service.FareParams
+		legs      []route_service.Leg
+		category  string
+		products  []route_service.FareProduct
+		wantFares []float64
+		wantCost  float64
+	}{
+		{
+			name:      "flat fare",
+			fares:     []network_service.FareParams{flat},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 10)},
+			wantFares: []float64{5},
+			wantCost:  5,
+		},
+		{
+			name: "route rule beats mode rule beats network rule",
+			fares: []network_service.FareParams{
+				flat,
+				{Kind: network_service.FareFlat, Mode: "bus", Amount: 7},
+				{Kind: network_service.FareFlat, RouteID: 1, Amount: 9},
+			},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 10), ride("M1", "metro", 2, 1, 10)},
+			wantFares: []float64{9, 5},
+			wantCost:  14,
+		},
+		{
+			name:      "distance fare picks the first covering band",
+			fares:     []network_service.FareParams{{Kind: network_service.FareDistance, Bands: bands}},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 10)},
+			wantFares: []float64{8},
+			wantCost:  8,
+		},
+		{
+			name:      "rides past the last band pay the top band",
+			fares:     []network_service.FareParams{{Kind: network_service.FareDistance, Bands: bands}},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 3, 30)},
+			wantFares: []float64{8},
+			wantCost:  8,
+		},
+		{
+			name:      "distance rule without bands is skipped",
+			fares:     []network_service.FareParams{{Kind: network_service.FareDistance}, flat},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 10)},
+			wantFares: []float64{5},
+			wantCost:  5,
+		},
+		{
+			name: "zone fare applies in both directions",
+			fares: []network_service.FareParams{
+				flat,
+				{Kind: network_service.FareZone, FromZone: "1", ToZone: "2", Amount: 6},
+			},
+			legs:      []route_service.Leg{ride("A", "bus", 2, 1, 10), ride("A", "bus", 1, 3, 10)},
+			wantFares: []float64{6, 5},
+			wantCost:  11,
+		},
+		{
+			name: "transfer discount within the window",
+			fares: []network_service.FareParams{
+				flat,
+				{Kind: network_service.FareTransfer, Percent: 50, WithinMinutes: 30},
+			},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 20), transferLeg(5), ride("M1", "metro", 2, 3, 10)},
+			wantFares: []float64{5, 2.5},
+			wantCost:  7.5,
+		},
+		{
+			name: "no transfer discount past the window",
+			fares: []network_service.FareParams{
+				flat,
+				{Kind: network_service.FareTransfer, Percent: 50, WithinMinutes: 20},
+			},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 20), transferLeg(5), ride("M1", "metro", 2, 3, 10)},
+			wantFares: []float64{5, 5},
+			wantCost:  10,
+		},
+		{
+			name: "concession for the rider category",
+			fares: []network_service.FareParams{
+				flat,
+				{Kind: network_service.FareConcession, RiderCategory: route_service.RiderStudent, Percent: 50},
+			},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 10)},
+			category:  route_service.RiderStudent,
+			wantFares: []float64{2.5},
+			wantCost:  2.5,
+		},
+		{
+			name: "no concession for other categories",
+			fares: []network_service.FareParams{
+				flat,
+				{Kind: network_service.FareConcession, RiderCategory: route_service.RiderStudent, Percent: 50},
+			},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 10)},
+			category:  route_service.RiderSenior,
+			wantFares: []float64{5},
+			wantCost:  5,
+		},
+		{
+			name:      "owned pass makes the ride free",
+			fares:     []network_service.FareParams{flat},
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 10), ride("M1", "metro", 2, 3, 10)},
+			products:  []route_service.FareProduct{{Type: route_service.ProductModePass, Mode: "metro"}},
+			wantFares: []float64{5, 0},
+			wantCost:  5,
+		},
+		{
+			name:      "legs no rule covers keep the router fare",
+			legs:      []route_service.Leg{ride("A", "bus", 1, 2, 10)},
+			wantFares: []float64{3},
+			wantCost:  3,
+		},
+		{
+			name:      "ride legs add their cost",
+			fares:     []network_service.FareParams{flat},
+			legs:      []route_service.Leg{{Ride: &route_service.RideLeg{Cost: 20}}, ride("A", "bus", 1, 2, 10)},
+			wantFares: []float64{5},
+			wantCost:  25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := route_service.Journey{Legs: tt.legs}
+			for _, leg := range j.Legs {
+				if leg.Trip != nil {
+					leg.Trip.Fare = 3
+				}
+			}
+
+			NewTable(testSnapshot(tt.fares...)).PriceJourney(&j, tt.category, tt.products)
+
+			var fares []float64
+			for _, leg := range j.Legs {
+				if leg.Trip != nil {
+					fares = append(fares, leg.Trip.Fare)
+				}
+			}
+			if !slices.Equal(fares, tt.wantFares) {
+				t.Errorf("fares = %v, want %v", fares, tt.wantFares)
+			}
+			if j.Summary.Cost != tt.wantCost {
+				t.Errorf("cost = %v, want %v", j.Summary.Cost, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestBand(t *testing.T) {
+	if got := band(nil, 100); got != 0 {
+		t.Errorf("band(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,38 @@
+package fare_service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// cacheTTL bounds how long fare rules are served from memory after an edit
+const cacheTTL = 30 * time.Second
+
+// Service prices journeys from the fare rules stored with the network
+type Service struct {
+	tables *network_service.Cache[*Table]
+}
+
+func NewService(network *network_service.Service) *Service {
+	return &Service{
+		tables: network_service.NewCache(network, cacheTTL, NewTable),
+	}
+}
+
+// Price sets leg fares and journey costs for a rider category, empty for
+// full fares, and the fare products the rider owns
+func (s *Service) Price(ctx context.Context, journeys []route_service.Journey, category string, products []route_service.FareProduct) error {
+	table, err := s.tables.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load fare rules: %w", err)
+	}
+
+	for i := range journeys {
+		table.PriceJourney(&journeys[i], category, products)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package fare_service
+
+import (
+	"context"
+	"log"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Router decorates another Router, replacing the fares it returns with the
+// ones computed from the stored fare rules
+type Router struct {
+	route_service.Router
+	fares *Service
+}
+
+func NewRouter(inner route_service.Router, fares *Service) *Router {
+	return &Router{
+		Router: inner,
+		fares:  fares,
+	}
+}
+
+func (r *Router) FindRoute(ctx context.Context, req route_service.RouteRequest) (route_service.RouteResponse, error) {
+	resp, err := r.Router.FindRoute(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	if err := r.fares.Price(ctx, resp.Journeys, req.RiderCategory, req.FareProducts); err != nil {
+		// The router's own fares are better than none
+		log.Printf("Error pricing journeys: %v", err)
+		return resp, nil
+	}
+	return resp, nil
+}
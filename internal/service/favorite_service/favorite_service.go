@@ -0,0 +1,352 @@
+package favorite_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+)
+
+var PlaceKinds = []string{"home", "work", "university", "other"}
+
+var (
+	ErrPlaceNotFound   = errors.New("place not found")
+	ErrProfileNotFound = errors.New("commute profile not found")
+	ErrNameTaken       = errors.New("name is already used")
+	ErrInvalidPlace    = errors.New("place needs a name, a known kind and valid coordinates")
+	ErrInvalidProfile  = errors.New("profile needs a name and non-negative options")
+)
+
+// Place is a named location saved by a user
+type Place struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Lat       float64   `json:"lat"`
+	Lon       float64   `json:"lon"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PlaceParams is the editable part of a place
+type PlaceParams struct {
+	Name string  `json:"name"`
+	Kind string  `json:"kind,omitempty"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// Profile holds default routing options applied to requests referencing it
+type Profile struct {
+	ID              int64                         `json:"id"`
+	Name            string                        `json:"name"`
+	RestrictedModes []string                      `json:"restricted_modes"`
+	Weights         *route_service.RoutingWeights `json:"weights,omitempty"`
+	WalkingCutoff   float64                       `json:"walking_cutoff"`
+	MaxTransfers    int32                         `json:"max_transfers"`
+	CreatedAt       time.Time                     `json:"created_at"`
+	UpdatedAt       time.Time                     `json:"updated_at"`
+}
+
+// ProfileParams is the editable part of a profile
+type ProfileParams struct {
+	Name            string                        `json:"name"`
+	RestrictedModes []string                      `json:"restricted_modes,omitempty"`
+	Weights         *route_service.RoutingWeights `json:"weights,omitempty"`
+	WalkingCutoff   float64                       `json:"walking_cutoff"`
+	MaxTransfers    int32                         `json:"max_transfers"`
+}
+
+type Service struct {
+	store *postgres.Store
+}
+
+func NewService(store *postgres.Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+func (s *Service) ListPlaces(ctx context.Context, userID int64) ([]Place, error) {
+	rows, err := s.store.ListPlaces(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list places: %w", err)
+	}
+
+	places := make([]Place, len(rows))
+	for i, row := range rows {
+		places[i] = toPlace(row)
+	}
+	return places, nil
+}
+
+func (s *Service) GetPlace(ctx context.Context, userID, id int64) (Place, error) {
+	row, err := s.store.GetPlace(ctx, database.GetPlaceParams{ID: id, UserID: userID})
+	if postgres.IsNotFound(err) {
+		return Place{}, ErrPlaceNotFound
+	}
+	if err != nil {
+		return Place{}, fmt.Errorf("failed to load place: %w", err)
+	}
+	return toPlace(row), nil
+}
+
+func (s *Service) CreatePlace(ctx context.Context, userID int64, params PlaceParams) (Place, error) {
+	if err := validatePlace(&params); err != nil {
+		return Place{}, err
+	}
+
+	row, err := s.store.CreatePlace(ctx, database.CreatePlaceParams{
+		UserID: userID,
+		Name:   params.Name,
+		Kind:   params.Kind,
+		Lat:    params.Lat,
+		Lon:    params.Lon,
+	})
+	if postgres.IsUniqueViolation(err) {
+		return Place{}, ErrNameTaken
+	}
+	if err != nil {
+		return Place{}, fmt.Errorf("failed to create place: %w", err)
+	}
+	return toPlace(row), nil
+}
+
+func (s *Service) UpdatePlace(ctx context.Context, userID, id int64, params PlaceParams) (Place, error) {
+	if err := validatePlace(&params); err != nil {
+		return Place{}, err
+	}
+
+	row, err := s.store.UpdatePlace(ctx, database.UpdatePlaceParams{
+		ID:     id,
+		UserID: userID,
+		Name:   params.Name,
+		Kind:   params.Kind,
+		Lat:    params.Lat,
+		Lon:    params.Lon,
+	})
+	switch {
+	case postgres.IsNotFound(err):
+		return Place{}, ErrPlaceNotFound
+	case postgres.IsUniqueViolation(err):
+		return Place{}, ErrNameTaken
+	case err != nil:
+		return Place{}, fmt.Errorf("failed to update place: %w", err)
+	}
+	return toPlace(row), nil
+}
+
+func (s *Service) DeletePlace(ctx context.Context, userID, id int64) error {
+	n, err := s.store.DeletePlace(ctx, database.DeletePlaceParams{ID: id, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete place: %w", err)
+	}
+	if n == 0 {
+		return ErrPlaceNotFound
+	}
+	return nil
+}
+
+func (s *Service) ListProfiles(ctx context.Context, userID int64) ([]Profile, error) {
+	rows, err := s.store.ListCommuteProfiles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	profiles := make([]Profile, len(rows))
+	for i, row := range rows {
+		if profiles[i], err = toProfile(row); err != nil {
+			return nil, err
+		}
+	}
+	return profiles, nil
+}
+
+func (s *Service) GetProfile(ctx context.Context, userID, id int64) (Profile, error) {
+	row, err := s.store.GetCommuteProfile(ctx, database.GetCommuteProfileParams{ID: id, UserID: userID})
+	if postgres.IsNotFound(err) {
+		return Profile{}, ErrProfileNotFound
+	}
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to load profile: %w", err)
+	}
+	return toProfile(row)
+}
+
+func (s *Service) CreateProfile(ctx context.Context, userID int64, params ProfileParams) (Profile, error) {
+	weights, err := validateProfile(&params)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	row, err := s.store.CreateCommuteProfile(ctx, database.CreateCommuteProfileParams{
+		UserID:          userID,
+		Name:            params.Name,
+		RestrictedModes: params.RestrictedModes,
+		Weights:         weights,
+		WalkingCutoff:   params.WalkingCutoff,
+		MaxTransfers:    params.MaxTransfers,
+	})
+	if postgres.IsUniqueViolation(err) {
+		return Profile{}, ErrNameTaken
+	}
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to create profile: %w", err)
+	}
+	return toProfile(row)
+}
+
+func (s *Service) UpdateProfile(ctx context.Context, userID, id int64, params ProfileParams) (Profile, error) {
+	weights, err := validateProfile(&params)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	row, err := s.store.UpdateCommuteProfile(ctx, database.UpdateCommuteProfileParams{
+		ID:              id,
+		UserID:          userID,
+		Name:            params.Name,
+		RestrictedModes: params.RestrictedModes,
+		Weights:         weights,
+		WalkingCutoff:   params.WalkingCutoff,
+		MaxTransfers:    params.MaxTransfers,
+	})
+	switch {
+	case postgres.IsNotFound(err):
+		return Profile{}, ErrProfileNotFound
+	case postgres.IsUniqueViolation(err):
+		return Profile{}, ErrNameTaken
+	case err != nil:
+		return Profile{}, fmt.Errorf("failed to update profile: %w", err)
+	}
+	return toProfile(row)
+}
+
+func (s *Service) DeleteProfile(ctx context.Context, userID, id int64) error {
+	n, err := s.store.DeleteCommuteProfile(ctx, database.DeleteCommuteProfileParams{ID: id, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	if n == 0 {
+		return ErrProfileNotFound
+	}
+	return nil
+}
+
+// Resolve replaces place and profile references in req with the stored
+// values. Coordinates come from the places, profile options only fill in
+// fields the request left unset
+func (s *Service) Resolve(ctx context.Context, userID int64, req *route_service.RouteRequest) error {
+	if req.StartPlaceID != 0 {
+		place, err := s.GetPlace(ctx, userID, req.StartPlaceID)
+		if err != nil {
+			return err
+		}
+		req.StartLat, req.StartLon = place.Lat, place.Lon
+	}
+
+	if req.EndPlaceID != 0 {
+		place, err := s.GetPlace(ctx, userID, req.EndPlaceID)
+		if err != nil {
+			return err
+		}
+		req.EndLat, req.EndLon = place.Lat, place.Lon
+	}
+
+	if req.ProfileID != 0 {
+		profile, err := s.GetProfile(ctx, userID, req.ProfileID)
+		if err != nil {
+			return err
+		}
+		if len(req.RestrictedModes) == 0 {
+			req.RestrictedModes = profile.RestrictedModes
+		}
+		if req.Weights == nil {
+			req.Weights = profile.Weights
+		}
+		if req.WalkingCutoff == 0 {
+			req.WalkingCutoff = profile.WalkingCutoff
+		}
+		if req.MaxTransfers == 0 {
+			req.MaxTransfers = profile.MaxTransfers
+		}
+	}
+
+	return nil
+}
+
+func validatePlace(params *PlaceParams) error {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Kind == "" {
+		params.Kind = "other"
+	}
+	if params.Name == "" || !slices.Contains(PlaceKinds, params.Kind) {
+		return ErrInvalidPlace
+	}
+	if params.Lat < -90 || params.Lat > 90 || params.Lon < -180 || params.Lon > 180 || (params.Lat == 0 && params.Lon == 0) {
+		return ErrInvalidPlace
+	}
+	return nil
+}
+
+// validateProfile checks params and returns the encoded weights
+func validateProfile(params *ProfileParams) ([]byte, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" || params.WalkingCutoff < 0 || params.MaxTransfers < 0 {
+		return nil, ErrInvalidProfile
+	}
+	if params.RestrictedModes == nil {
+		params.RestrictedModes = []string{}
+	}
+	if params.Weights == nil {
+		return nil, nil
+	}
+
+	w := params.Weights
+	if w.Time < 0 || w.Cost < 0 || w.Walk < 0 || w.Transfer < 0 || w.CO2 < 0 {
+		return nil, ErrInvalidProfile
+	}
+	weights, err := json.Marshal(w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode weights: %w", err)
+	}
+	return weights, nil
+}
+
+func toPlace(row database.Place) Place {
+	return Place{
+		ID:        row.ID,
+		Name:      row.Name,
+		Kind:      row.Kind,
+		Lat:       row.Lat,
+		Lon:       row.Lon,
+		CreatedAt: row.CreatedAt.Time,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+}
+
+func toProfile(row database.CommuteProfile) (Profile, error) {
+	profile := Profile{
+		ID:              row.ID,
+		Name:            row.Name,
+		RestrictedModes: row.RestrictedModes,
+		WalkingCutoff:   row.WalkingCutoff,
+		MaxTransfers:    row.MaxTransfers,
+		CreatedAt:       row.CreatedAt.Time,
+		UpdatedAt:       row.UpdatedAt.Time,
+	}
+	if row.Weights != nil {
+		profile.Weights = &route_service.RoutingWeights{}
+		if err := json.Unmarshal(row.Weights, profile.Weights); err != nil {
+			return Profile{}, fmt.Errorf("failed to decode weights: %w", err)
+		}
+	}
+	return profile, nil
+}
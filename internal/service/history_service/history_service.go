@@ -0,0 +1,228 @@
+package history_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/ranking"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+)
+
+const (
+	// MinSamples is how many selections are needed before re-ranking kicks in
+	MinSamples = 3
+	// FullConfidenceSamples is when learned weights fully replace the request weights
+	FullConfidenceSamples = 20
+
+	DefaultListLimit = 50
+)
+
+var ErrSelectionNotFound = errors.New("selected journey is not in the list of journeys")
+
+// JourneyRecord is the part of a journey kept in the history
+type JourneyRecord struct {
+	TextSummary string                       `json:"text_summary"`
+	Summary     route_service.JourneySummary `json:"summary"`
+}
+
+// Selection is one journey a user picked and the alternatives they saw
+type Selection struct {
+	ID           int64           `json:"id"`
+	Selected     JourneyRecord   `json:"selected"`
+	Alternatives []JourneyRecord `json:"alternatives"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// RecordParams is the route response the user chose from
+type RecordParams struct {
+	SelectedJourneyID int                     `json:"selected_journey_id"`
+	Journeys          []route_service.Journey `json:"journeys"`
+}
+
+// LearnedWeights are the ranking weights inferred from a user's selections
+type LearnedWeights struct {
+	Weights route_service.RoutingWeights `json:"weights"`
+	Samples int                          `json:"samples"`
+}
+
+type Service struct {
+	store *postgres.Store
+}
+
+func NewService(store *postgres.Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// Record stores a selection and updates the user's learned weights
+func (s *Service) Record(ctx context.Context, userID int64, params RecordParams) (Selection, error) {
+	var chosen route_service.Journey
+	var alternatives []route_service.Journey
+	found := false
+	for _, j := range params.Journeys {
+		if j.ID == params.SelectedJourneyID && !found {
+			chosen, found = j, true
+			continue
+		}
+		alternatives = append(alternatives, j)
+	}
+	if !found {
+		return Selection{}, ErrSelectionNotFound
+	}
+
+	selected, err := json.Marshal(toRecord(chosen))
+	if err != nil {
+		return Selection{}, fmt.Errorf("failed to encode selection: %w", err)
+	}
+	records := make([]JourneyRecord, len(alternatives))
+	altFeatures := make([]ranking.Features, len(alternatives))
+	for i, j := range alternatives {
+		records[i] = toRecord(j)
+		altFeatures[i] = ranking.FeaturesOf(j.Summary)
+	}
+	alts, err := json.Marshal(records)
+	if err != nil {
+		return Selection{}, fmt.Errorf("failed to encode alternatives: %w", err)
+	}
+
+	var row database.TripSelection
+	err = s.store.InTx(ctx, func(q *database.Queries) error {
+		row, err = q.CreateTripSelection(ctx, database.CreateTripSelectionParams{
+			UserID:       userID,
+			Selected:     selected,
+			Alternatives: alts,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save selection: %w", err)
+		}
+
+		current := LearnedWeights{Weights: ranking.DefaultWeights}
+		existing, err := q.GetUserRankingWeightsForUpdate(ctx, userID)
+		switch {
+		case err == nil:
+			current = toLearnedWeights(existing)
+		case !postgres.IsNotFound(err):
+			return fmt.Errorf("failed to load learned weights: %w", err)
+		}
+
+		w := ranking.Learn(current.Weights, ranking.FeaturesOf(chosen.Summary), altFeatures)
+		_, err = q.UpsertUserRankingWeights(ctx, database.UpsertUserRankingWeightsParams{
+			UserID:   userID,
+			Time:     w.Time,
+			Cost:     w.Cost,
+			Walk:     w.Walk,
+			Transfer: w.Transfer,
+			Samples:  int32(current.Samples + 1),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save learned weights: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return Selection{}, err
+	}
+
+	return toSelection(row)
+}
+
+// List returns the most recent selections of a user
+func (s *Service) List(ctx context.Context, userID int64, limit int) ([]Selection, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	rows, err := s.store.ListTripSelections(ctx, database.ListTripSelectionsParams{
+		UserID: userID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list selections: %w", err)
+	}
+
+	selections := make([]Selection, len(rows))
+	for i, row := range rows {
+		if selections[i], err = toSelection(row); err != nil {
+			return nil, err
+		}
+	}
+	return selections, nil
+}
+
+// Weights returns the learned weights of a user, DefaultWeights with zero
+// samples if nothing was learned yet
+func (s *Service) Weights(ctx context.Context, userID int64) (LearnedWeights, error) {
+	row, err := s.store.GetUserRankingWeights(ctx, userID)
+	if postgres.IsNotFound(err) {
+		return LearnedWeights{Weights: ranking.DefaultWeights}, nil
+	}
+	if err != nil {
+		return LearnedWeights{}, fmt.Errorf("failed to load learned weights: %w", err)
+	}
+	return toLearnedWeights(row), nil
+}
+
+// Rerank reorders the journeys of resp for the user. The request weights
+// (or the defaults) are blended with the learned ones in proportion to how
+// many selections they were learned from. Selections do not teach a CO2
+// weight, the request's share of it is kept as asked
+func (s *Service) Rerank(ctx context.Context, userID int64, req route_service.RouteRequest, resp *route_service.RouteResponse) error {
+	if len(resp.Journeys) < 2 {
+		return nil
+	}
+
+	learned, err := s.Weights(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if learned.Samples < MinSamples {
+		return nil
+	}
+
+	confidence := float64(learned.Samples) / FullConfidenceSamples
+
+	request := ranking.RequestWeights(req)
+	weights := ranking.WithCO2(ranking.Blend(request, learned.Weights, confidence), request.CO2)
+	ranking.Order(resp, weights)
+	resp.Personalized = true
+	return nil
+}
+
+func toRecord(j route_service.Journey) JourneyRecord {
+	return JourneyRecord{
+		TextSummary: j.TextSummary,
+		Summary:     j.Summary,
+	}
+}
+
+func toSelection(row database.TripSelection) (Selection, error) {
+	sel := Selection{
+		ID:        row.ID,
+		CreatedAt: row.CreatedAt.Time,
+	}
+	if err := json.Unmarshal(row.Selected, &sel.Selected); err != nil {
+		return Selection{}, fmt.Errorf("failed to decode selection: %w", err)
+	}
+	if err := json.Unmarshal(row.Alternatives, &sel.Alternatives); err != nil {
+		return Selection{}, fmt.Errorf("failed to decode alternatives: %w", err)
+	}
+	return sel, nil
+}
+
+func toLearnedWeights(row database.UserRankingWeight) LearnedWeights {
+	return LearnedWeights{
+		Weights: route_service.RoutingWeights{
+			Time:     row.Time,
+			Cost:     row.Cost,
+			Walk:     row.Walk,
+			Transfer: row.Transfer,
+		},
+		Samples: int(row.Samples),
+	}
+}
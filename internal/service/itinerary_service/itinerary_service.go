@@ -0,0 +1,139 @@
+package itinerary_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+const (
+	// MaxPlaces keeps the pairwise matrix and the order search small
+	MaxPlaces = 8
+	// matrixTTL is how long a pairwise journey is reused
+	matrixTTL = 10 * time.Minute
+	// matrixEntries bounds the pairwise journeys kept across requests
+	matrixEntries = 10000
+	// matrixWorkers bounds the concurrent calls to the router
+	matrixWorkers = 4
+)
+
+var (
+	ErrInvalidItinerary = errors.New("invalid itinerary")
+	ErrNoFeasibleOrder  = errors.New("no order visits every place within its time window")
+)
+
+// Place is a location on the itinerary
+type Place struct {
+	Name  string                   `json:"name,omitempty"`
+	Coord route_service.Coordinate `json:"coord"`
+	// Window is when the place can be visited, arriving early means waiting
+	Window       *TimeWindow `json:"window,omitempty"`
+	DwellMinutes int         `json:"dwell_minutes,omitempty"`
+}
+
+type TimeWindow struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Request is a start, an unordered set of places to visit and an optional end
+type Request struct {
+	Start  Place   `json:"start"`
+	Places []Place `json:"places"`
+	// End defaults to the last place visited
+	End      *Place    `json:"end,omitempty"`
+	DepartAt time.Time `json:"depart_at,omitzero"`
+	// Options apply to every hop, their coordinates are ignored
+	Options route_service.RouteRequest `json:"options"`
+}
+
+// Hop is the journey between two consecutive places
+type Hop struct {
+	From        string                `json:"from"`
+	To          string                `json:"to"`
+	Depart      time.Time             `json:"depart"`
+	Arrive      time.Time             `json:"arrive"`
+	WaitMinutes int                   `json:"wait_minutes,omitempty"`
+	Journey     route_service.Journey `json:"journey"`
+}
+
+type Itinerary struct {
+	// Order lists the indices of the request's places in visiting order
+	Order          []int     `json:"order"`
+	Hops           []Hop     `json:"hops"`
+	TransitMinutes int       `json:"transit_minutes"`
+	Finish         time.Time `json:"finish"`
+}
+
+// Service orders multi-stop itineraries using the router for each hop
+type Service struct {
+	router route_service.Router
+	matrix *matrixCache
+}
+
+func NewService(router route_service.Router) *Service {
+	return &Service{
+		router: router,
+		matrix: newMatrixCache(matrixEntries),
+	}
+}
+
+// Plan finds the visiting order with the least total transit time that
+// respects every time window
+func (s *Service) Plan(ctx context.Context, req Request) (Itinerary, error) {
+	if err := validate(&req); err != nil {
+		return Itinerary{}, err
+	}
+	if req.DepartAt.IsZero() {
+		req.DepartAt = time.Now()
+	}
+
+	nodes := append([]Place{req.Start}, req.Places...)
+	if req.End != nil {
+		nodes = append(nodes, *req.End)
+	}
+
+	matrix, err := s.pairwise(ctx, nodes, req.End != nil, req.Options)
+	if err != nil {
+		return Itinerary{}, err
+	}
+
+	p := planner{nodes: nodes, places: len(req.Places), hasEnd: req.End != nil, matrix: matrix}
+	order, ok := p.search(req.DepartAt)
+	if !ok {
+		return Itinerary{}, ErrNoFeasibleOrder
+	}
+	return p.itinerary(order, req.DepartAt), nil
+}
+
+func validate(req *Request) error {
+	if len(req.Places) == 0 || len(req.Places) > MaxPlaces {
+		return fmt.Errorf("%w: between 1 and %d places are needed", ErrInvalidItinerary, MaxPlaces)
+	}
+
+	all := append([]Place{req.Start}, req.Places...)
+	if req.End != nil {
+		all = append(all, *req.End)
+	}
+	for i, p := range all {
+		if p.Coord.Lat == 0 || p.Coord.Lon == 0 || p.Coord.Lat < -90 || p.Coord.Lat > 90 || p.Coord.Lon < -180 || p.Coord.Lon > 180 {
+			return fmt.Errorf("%w: place %d has invalid coordinates", ErrInvalidItinerary, i)
+		}
+		if p.DwellMinutes < 0 {
+			return fmt.Errorf("%w: place %d has a negative dwell time", ErrInvalidItinerary, i)
+		}
+		if p.Window != nil && !p.Window.End.After(p.Window.Start) {
+			return fmt.Errorf("%w: place %d has an empty time window", ErrInvalidItinerary, i)
+		}
+	}
+
+	// Each hop is a plain A to B request
+	req.Options.Via, req.Options.StartPlaceID, req.Options.EndPlaceID, req.Options.ProfileID = nil, 0, 0, 0
+	if err := req.Options.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidItinerary, err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package itinerary_service
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// matrixCache remembers the fastest journey between two points for a set
+// of routing options, safe for concurrent use. It holds at most max
+// journeys, dropping the least recently used
+type matrixCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]*list.Element
+	// lru orders the entries from most to least recently used
+	lru *list.List
+}
+
+type matrixEntry struct {
+	key     string
+	journey *route_service.Journey
+	expires time.Time
+}
+
+func newMatrixCache(max int) *matrixCache {
+	return &matrixCache{
+		max:     max,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *matrixCache) get(key string) (*route_service.Journey, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*matrixEntry)
+	if time.Now().After(e.expires) {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return e.journey, true
+}
+
+func (c *matrixCache) put(key string, j *route_service.Journey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(matrixTTL)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*matrixEntry)
+		e.journey, e.expires = j, expires
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&matrixEntry{key: key, journey: j, expires: expires})
+	for c.lru.Len() > c.max {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *matrixCache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*matrixEntry).key)
+}
+
+// Purge drops expired pairwise journeys every interval until ctx is done
+func (s *Service) Purge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.matrix.purge(time.Now())
+		}
+	}
+}
+
+func (c *matrixCache) purge(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.lru.Back(); el != nil; {
+		prev := el.Prev()
+		if now.After(el.Value.(*matrixEntry).expires) {
+			c.remove(el)
+		}
+		el = prev
+	}
+}
+
+// pairwise fills matrix[i][j] with the fastest journey from node i to node
+// j, nil when the router found none. The start is never a destination and
+// the end, when set, never an origin
+func (s *Service) pairwise(ctx context.Context, nodes []Place, hasEnd bool, opts route_service.RouteRequest) ([][]*route_service.Journey, error) {
+	optsKey, err := json.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode routing options: %w", err)
+	}
+
+	matrix := make([][]*route_service.Journey, len(nodes))
+	for i := range matrix {
+		matrix[i] = make([]*route_service.Journey, len(nodes))
+	}
+
+	last := len(nodes) - 1
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		slots    = make(chan struct{}, matrixWorkers)
+	)
+	for i := range nodes {
+		for j := range nodes {
+			if i == j || j == 0 || (hasEnd && i == last) {
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				slots <- struct{}{}
+				defer func() { <-slots }()
+
+				journey, err := s.fastest(ctx, nodes[i].Coord, nodes[j].Coord, opts, string(optsKey))
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				matrix[i][j] = journey
+			}()
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return matrix, nil
+}
+
+func (s *Service) fastest(ctx context.Context, from, to route_service.Coordinate, opts route_service.RouteRequest, optsKey string) (*route_service.Journey, error) {
+	key := fmt.Sprintf("%.5f,%.5f>%.5f,%.5f|%s", from.Lat, from.Lon, to.Lat, to.Lon, optsKey)
+	if j, ok := s.matrix.get(key); ok {
+		return j, nil
+	}
+
+	req := opts
+	req.StartLat, req.StartLon = from.Lat, from.Lon
+	req.EndLat, req.EndLon = to.Lat, to.Lon
+
+	resp, err := s.router.FindRoute(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route hop: %w", err)
+	}
+
+	var best *route_service.Journey
+	for i := range resp.Journeys {
+		if best == nil || resp.Journeys[i].Summary.TotalTimeMinutes < best.Summary.TotalTimeMinutes {
+			best = &resp.Journeys[i]
+		}
+	}
+	s.matrix.put(key, best)
+	return best, nil
+}
@@ -0,0 +1,37 @@
+package itinerary_service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+func TestMatrixCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newMatrixCache(2)
+	j := &route_service.Journey{}
+
+	c.put("a", j)
+	c.put("b", j)
+	c.get("a")
+	c.put("c", j)
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"a", true},
+		{"b", false},
+		{"c", true},
+	}
+	for _, tt := range tests {
+		if _, ok := c.get(tt.key); ok != tt.want {
+			t.Errorf("get(%s) found = %v, want %v", tt.key, ok, tt.want)
+		}
+	}
+
+	c.purge(time.Now().Add(matrixTTL + time.Second))
+	if c.lru.Len() != 0 || len(c.entries) != 0 {
+		t.Errorf("purge left %d entries, want 0", c.lru.Len())
+	}
+}
@@ -0,0 +1,143 @@
+package itinerary_service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// planner searches visiting orders over the pairwise matrix. Node 0 is the
+// start, nodes 1..places are the places and the last node is the end when set
+type planner struct {
+	nodes  []Place
+	places int
+	hasEnd bool
+	matrix [][]*route_service.Journey
+
+	best       []int
+	bestTime   int
+	bestFinish time.Time
+}
+
+// search returns the order of places, as node indices, with the least
+// transit time, ties going to the earliest finish
+func (p *planner) search(depart time.Time) ([]int, bool) {
+	p.best = nil
+	order := make([]int, 0, p.places)
+	visited := make([]bool, len(p.nodes))
+	p.visit(0, depart, 0, order, visited)
+	return p.best, p.best != nil
+}
+
+func (p *planner) visit(from int, clock time.Time, transit int, order []int, visited []bool) {
+	if p.best != nil && transit > p.bestTime {
+		return
+	}
+
+	if len(order) == p.places {
+		if p.hasEnd {
+			end := len(p.nodes) - 1
+			var ok bool
+			if clock, transit, ok = p.arrive(from, end, clock, transit); !ok {
+				return
+			}
+		}
+		if p.best == nil || transit < p.bestTime || (transit == p.bestTime && clock.Before(p.bestFinish)) {
+			p.best = append(p.best[:0], order...)
+			p.bestTime, p.bestFinish = transit, clock
+		}
+		return
+	}
+
+	for next := 1; next <= p.places; next++ {
+		if visited[next] {
+			continue
+		}
+		at, t, ok := p.arrive(from, next, clock, transit)
+		if !ok {
+			continue
+		}
+		visited[next] = true
+		p.visit(next, at, t, append(order, next), visited)
+		visited[next] = false
+	}
+}
+
+// arrive travels from one node to another and returns when the traveller
+// leaves it again, false when it is unreachable or its window is missed
+func (p *planner) arrive(from, to int, clock time.Time, transit int) (time.Time, int, bool) {
+	j := p.matrix[from][to]
+	if j == nil {
+		return clock, transit, false
+	}
+
+	minutes := j.Summary.TotalTimeMinutes
+	at := clock.Add(time.Duration(minutes) * time.Minute)
+	place := p.nodes[to]
+	if w := place.Window; w != nil {
+		if at.After(w.End) {
+			return clock, transit, false
+		}
+		if at.Before(w.Start) {
+			at = w.Start
+		}
+	}
+	return at.Add(time.Duration(place.DwellMinutes) * time.Minute), transit + minutes, true
+}
+
+// itinerary builds the hops of an order found by search
+func (p *planner) itinerary(order []int, depart time.Time) Itinerary {
+	stops := append([]int{0}, order...)
+	if p.hasEnd {
+		stops = append(stops, len(p.nodes)-1)
+	}
+
+	it := Itinerary{Order: make([]int, len(order))}
+	for i, n := range order {
+		it.Order[i] = n - 1
+	}
+
+	clock := depart
+	for i := 1; i < len(stops); i++ {
+		from, to := stops[i-1], stops[i]
+		j := p.matrix[from][to]
+		arrive := clock.Add(time.Duration(j.Summary.TotalTimeMinutes) * time.Minute)
+
+		// Journeys are shared through the matrix cache, hops get their own legs
+		journey := *j
+		journey.Legs = slices.Clone(j.Legs)
+
+		hop := Hop{
+			From:    p.name(from),
+			To:      p.name(to),
+			Depart:  clock,
+			Arrive:  arrive,
+			Journey: journey,
+		}
+		if w := p.nodes[to].Window; w != nil && arrive.Before(w.Start) {
+			hop.WaitMinutes = int(w.Start.Sub(arrive).Minutes())
+			arrive = w.Start
+		}
+		it.Hops = append(it.Hops, hop)
+		it.TransitMinutes += j.Summary.TotalTimeMinutes
+
+		clock = arrive.Add(time.Duration(p.nodes[to].DwellMinutes) * time.Minute)
+	}
+	it.Finish = clock
+	return it
+}
+
+func (p *planner) name(n int) string {
+	if p.nodes[n].Name != "" {
+		return p.nodes[n].Name
+	}
+	switch {
+	case n == 0:
+		return "start"
+	case p.hasEnd && n == len(p.nodes)-1:
+		return "end"
+	}
+	return fmt.Sprintf("place %d", n)
+}
@@ -0,0 +1,90 @@
+package mobility_service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Model estimates the duration and cost of a ride from its distance
+type Model struct {
+	// Base is charged once per ride, the flag fall or unlock fee
+	Base      float64
+	PerKm     float64
+	PerMinute float64
+	Minimum   float64
+	// SpeedKmh is the average door to door speed, traffic included
+	SpeedKmh float64
+}
+
+// Models holds a pricing model per ride mode
+type Models map[string]Model
+
+// Estimate returns the minutes and cost of a ride of distanceMeters
+func (m Model) Estimate(distanceMeters int) (int, float64) {
+	km := float64(distanceMeters) / 1000
+	minutes := int(math.Ceil(km / m.SpeedKmh * 60))
+
+	cost := m.Base + km*m.PerKm + float64(minutes)*m.PerMinute
+	cost = math.Max(cost, m.Minimum)
+	return minutes, math.Round(cost*100) / 100
+}
+
+// ParseModels reads "mode=key:value,...;mode=..." where keys are base, km,
+// min, minimum and kmh, for example "taxi=base:10,km:4,min:0.5,kmh:22"
+func ParseModels(spec string) (Models, error) {
+	models := make(Models)
+
+	for entry := range strings.SplitSeq(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		mode, params, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid pricing model %q", entry)
+		}
+		mode = strings.TrimSpace(mode)
+		if !slices.Contains(route_service.RideModes, mode) {
+			return nil, fmt.Errorf("unknown ride mode %q", mode)
+		}
+
+		var m Model
+		for param := range strings.SplitSeq(params, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid pricing parameter %q for %s", param, mode)
+			}
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("invalid value %q for %s", param, mode)
+			}
+
+			switch key {
+			case "base":
+				m.Base = v
+			case "km":
+				m.PerKm = v
+			case "min":
+				m.PerMinute = v
+			case "minimum":
+				m.Minimum = v
+			case "kmh":
+				m.SpeedKmh = v
+			default:
+				return nil, fmt.Errorf("unknown pricing parameter %q for %s", key, mode)
+			}
+		}
+		if m.SpeedKmh <= 0 {
+			return nil, fmt.Errorf("pricing model for %s needs a kmh speed", mode)
+		}
+		models[mode] = m
+	}
+
+	return models, nil
+}
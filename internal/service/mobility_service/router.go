@@ -0,0 +1,63 @@
+package mobility_service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Router decorates another Router, estimating the duration and cost of bike,
+// scooter, taxi and ride-hail legs with the local pricing models
+type Router struct {
+	route_service.Router
+	models Models
+}
+
+func NewRouter(inner route_service.Router, models Models) *Router {
+	return &Router{
+		Router: inner,
+		models: models,
+	}
+}
+
+func (r *Router) FindRoute(ctx context.Context, req route_service.RouteRequest) (route_service.RouteResponse, error) {
+	resp, err := r.Router.FindRoute(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	for i := range resp.Journeys {
+		r.price(&resp.Journeys[i])
+	}
+	return resp, nil
+}
+
+// price replaces the router's estimates for ride legs and moves the journey
+// summary by the difference
+func (r *Router) price(j *route_service.Journey) {
+	for _, leg := range j.Legs {
+		ride := leg.Ride
+		if ride == nil {
+			continue
+		}
+		model, ok := r.models[ride.Mode]
+		if !ok {
+			continue
+		}
+
+		if ride.DistanceMeters == 0 {
+			ride.DistanceMeters = int(geo.Distance(geo.Point{Lat: ride.From.Lat, Lon: ride.From.Lon}, geo.Point{Lat: ride.To.Lat, Lon: ride.To.Lon}))
+		}
+		minutes, cost := model.Estimate(ride.DistanceMeters)
+
+		j.Summary.TotalTimeMinutes += minutes - ride.DurationMinutes
+		j.Summary.Cost += cost - ride.Cost
+		ride.DurationMinutes, ride.Cost = minutes, cost
+
+		if !slices.Contains(j.Summary.Modes, ride.Mode) {
+			j.Summary.Modes = append(j.Summary.Modes, ride.Mode)
+		}
+	}
+}
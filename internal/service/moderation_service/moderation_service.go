@@ -0,0 +1,389 @@
+package moderation_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+
+	// Actions recorded in the audit log for moderation decisions
+	AuditApprove = "approve"
+	AuditReject  = "reject"
+
+	// EntityChangeRequest is how change requests appear in the audit log
+	EntityChangeRequest = "change_request"
+
+	DefaultQueueLimit = 100
+	MaxQueueLimit     = 500
+)
+
+var Statuses = []string{StatusPending, StatusApproved, StatusRejected}
+
+var (
+	ErrNotFound        = errors.New("change request not found")
+	ErrAlreadyReviewed = errors.New("change request was already reviewed")
+	ErrInvalidComment  = errors.New("comment must not be empty")
+	ErrInvalidStatus   = errors.New("unknown change request status")
+)
+
+// ChangeRequest is a proposed network edit waiting for or past review
+type ChangeRequest struct {
+	ID             int64           `json:"id"`
+	Entity         string          `json:"entity"`
+	Action         string          `json:"action"`
+	EntityID       int64           `json:"entity_id,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	SubmittedBy    int64           `json:"submitted_by,omitempty"`
+	SubmissionID   int64           `json:"submission_id,omitempty"`
+	ReviewedBy     int64           `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time      `json:"reviewed_at,omitempty"`
+	NetworkVersion int64           `json:"network_version,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// Review is a change request with what it would change and its discussion
+type Review struct {
+	ChangeRequest
+	Diff     []network_service.FieldChange `json:"diff"`
+	Conflict string                        `json:"conflict,omitempty"`
+	Comments []Comment                     `json:"comments"`
+}
+
+type Comment struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditEntry is one recorded action on the network or the queue
+type AuditEntry struct {
+	ID              int64           `json:"id"`
+	ActorID         int64           `json:"actor_id,omitempty"`
+	Action          string          `json:"action"`
+	Entity          string          `json:"entity"`
+	EntityID        int64           `json:"entity_id,omitempty"`
+	ChangeRequestID int64           `json:"change_request_id,omitempty"`
+	Before          json.RawMessage `json:"before,omitempty"`
+	After           json.RawMessage `json:"after,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+type Service struct {
+	store   *postgres.Store
+	network *network_service.Service
+}
+
+func NewService(store *postgres.Store, network *network_service.Service) *Service {
+	return &Service{
+		store:   store,
+		network: network,
+	}
+}
+
+// Propose validates a change against the current network and queues it
+func (s *Service) Propose(ctx context.Context, userID int64, change network_service.Change) (ChangeRequest, error) {
+	if _, _, err := s.network.Preview(ctx, change); err != nil {
+		return ChangeRequest{}, err
+	}
+	return Propose(ctx, s.store.Queries, userID, 0, change)
+}
+
+// Propose queues a change with q, for callers that create it inside their
+// own transaction
+func Propose(ctx context.Context, q *database.Queries, userID, submissionID int64, change network_service.Change) (ChangeRequest, error) {
+	payload := change.Payload
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+
+	row, err := q.CreateChangeRequest(ctx, database.CreateChangeRequestParams{
+		Entity:       change.Entity,
+		Action:       change.Action,
+		EntityID:     nullInt8(change.EntityID),
+		Payload:      payload,
+		SubmittedBy:  nullInt8(userID),
+		SubmissionID: nullInt8(submissionID),
+	})
+	if err != nil {
+		return ChangeRequest{}, fmt.Errorf("failed to queue change request: %w", err)
+	}
+	return toChangeRequest(row), nil
+}
+
+// Queue lists change requests with a status, oldest first
+func (s *Service) Queue(ctx context.Context, status string, limit int32) ([]ChangeRequest, error) {
+	if status == "" {
+		status = StatusPending
+	}
+	if !slices.Contains(Statuses, status) {
+		return nil, ErrInvalidStatus
+	}
+	if limit <= 0 || limit > MaxQueueLimit {
+		limit = DefaultQueueLimit
+	}
+
+	rows, err := s.store.ListChangeRequests(ctx, database.ListChangeRequestsParams{Status: status, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change requests: %w", err)
+	}
+
+	requests := make([]ChangeRequest, len(rows))
+	for i, row := range rows {
+		requests[i] = toChangeRequest(row)
+	}
+	return requests, nil
+}
+
+// Get returns a change request with its diff against the current network
+// and its comments
+func (s *Service) Get(ctx context.Context, id int64) (Review, error) {
+	row, err := s.store.GetChangeRequest(ctx, id)
+	if postgres.IsNotFound(err) {
+		return Review{}, ErrNotFound
+	}
+	if err != nil {
+		return Review{}, fmt.Errorf("failed to load change request: %w", err)
+	}
+
+	review := Review{ChangeRequest: toChangeRequest(row)}
+	if review.Status == StatusPending {
+		// The network may have moved on since the change was proposed
+		before, after, err := s.network.Preview(ctx, review.change())
+		switch {
+		case errors.Is(err, network_service.ErrNotFound), errors.Is(err, network_service.ErrInvalidChange):
+			review.Conflict = err.Error()
+		case err != nil:
+			return Review{}, err
+		default:
+			if review.Diff, err = network_service.Diff(before, after); err != nil {
+				return Review{}, err
+			}
+		}
+	}
+
+	comments, err := s.store.ListChangeRequestComments(ctx, id)
+	if err != nil {
+		return Review{}, fmt.Errorf("failed to load comments: %w", err)
+	}
+	review.Comments = make([]Comment, len(comments))
+	for i, c := range comments {
+		review.Comments[i] = toComment(c)
+	}
+	return review, nil
+}
+
+// Comment adds a note to a change request
+func (s *Service) Comment(ctx context.Context, userID, id int64, body string) (Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return Comment{}, ErrInvalidComment
+	}
+
+	row, err := s.store.CreateChangeRequestComment(ctx, database.CreateChangeRequestCommentParams{
+		ChangeRequestID: id,
+		UserID:          nullInt8(userID),
+		Body:            body,
+	})
+	if postgres.IsForeignKeyViolation(err) {
+		return Comment{}, ErrNotFound
+	}
+	if err != nil {
+		return Comment{}, fmt.Errorf("failed to save comment: %w", err)
+	}
+	return toComment(row), nil
+}
+
+// Approve applies a pending change, publishes a new network version and
+// asks the router to reload it
+func (s *Service) Approve(ctx context.Context, reviewerID, id int64, comment string) (ChangeRequest, error) {
+	var approved ChangeRequest
+	err := s.store.InTx(ctx, func(q *database.Queries) error {
+		row, err := lockPending(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		req := toChangeRequest(row)
+		if _, err := s.network.Apply(ctx, q, reviewerID, id, req.change()); err != nil {
+			return err
+		}
+		version, err := s.network.Publish(ctx, q, reviewerID, id)
+		if err != nil {
+			return err
+		}
+
+		approved, err = s.review(ctx, q, reviewerID, row, StatusApproved, version, comment)
+		return err
+	})
+	if err != nil {
+		return ChangeRequest{}, err
+	}
+
+	s.network.Reload(ctx, approved.NetworkVersion)
+	return approved, nil
+}
+
+// Reject closes a pending change without applying it
+func (s *Service) Reject(ctx context.Context, reviewerID, id int64, comment string) (ChangeRequest, error) {
+	var rejected ChangeRequest
+	err := s.store.InTx(ctx, func(q *database.Queries) error {
+		row, err := lockPending(ctx, q, id)
+		if err != nil {
+			return err
+		}
+
+		rejected, err = s.review(ctx, q, reviewerID, row, StatusRejected, 0, comment)
+		return err
+	})
+	if err != nil {
+		return ChangeRequest{}, err
+	}
+	return rejected, nil
+}
+
+// Audit lists the audit trail, optionally narrowed to one entity
+func (s *Service) Audit(ctx context.Context, entity string, entityID int64, limit int32) ([]AuditEntry, error) {
+	if limit <= 0 || limit > MaxQueueLimit {
+		limit = DefaultQueueLimit
+	}
+
+	rows, err := s.store.ListAuditEntries(ctx, database.ListAuditEntriesParams{
+		Limit:    limit,
+		Entity:   pgtype.Text{String: entity, Valid: entity != ""},
+		EntityID: nullInt8(entityID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	entries := make([]AuditEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = AuditEntry{
+			ID:              row.ID,
+			ActorID:         row.ActorID.Int64,
+			Action:          row.Action,
+			Entity:          row.Entity,
+			EntityID:        row.EntityID.Int64,
+			ChangeRequestID: row.ChangeRequestID.Int64,
+			Before:          row.Before,
+			After:           row.After,
+			CreatedAt:       row.CreatedAt.Time,
+		}
+	}
+	return entries, nil
+}
+
+// review records the decision, the optional comment and the audit entry, and
+// settles the route submission the change came from
+func (s *Service) review(ctx context.Context, q *database.Queries, reviewerID int64, row database.ChangeRequest, status string, version int64, comment string) (ChangeRequest, error) {
+	reviewed, err := q.ReviewChangeRequest(ctx, database.ReviewChangeRequestParams{
+		ID:             row.ID,
+		Status:         status,
+		ReviewedBy:     nullInt8(reviewerID),
+		NetworkVersion: nullInt8(version),
+	})
+	if err != nil {
+		return ChangeRequest{}, fmt.Errorf("failed to review change request: %w", err)
+	}
+
+	if comment = strings.TrimSpace(comment); comment != "" {
+		if _, err := q.CreateChangeRequestComment(ctx, database.CreateChangeRequestCommentParams{
+			ChangeRequestID: row.ID,
+			UserID:          nullInt8(reviewerID),
+			Body:            comment,
+		}); err != nil {
+			return ChangeRequest{}, fmt.Errorf("failed to save comment: %w", err)
+		}
+	}
+
+	if row.SubmissionID.Valid {
+		if err := q.UpdateRouteSubmissionStatus(ctx, database.UpdateRouteSubmissionStatusParams{
+			ID:     row.SubmissionID.Int64,
+			Status: status,
+		}); err != nil {
+			return ChangeRequest{}, fmt.Errorf("failed to update route submission: %w", err)
+		}
+	}
+
+	action := AuditApprove
+	if status == StatusRejected {
+		action = AuditReject
+	}
+	result := toChangeRequest(reviewed)
+	if err := network_service.Audit(ctx, q, reviewerID, action, EntityChangeRequest, row.ID, row.ID, toChangeRequest(row), result); err != nil {
+		return ChangeRequest{}, err
+	}
+	return result, nil
+}
+
+func lockPending(ctx context.Context, q *database.Queries, id int64) (database.ChangeRequest, error) {
+	row, err := q.GetChangeRequestForUpdate(ctx, id)
+	if postgres.IsNotFound(err) {
+		return row, ErrNotFound
+	}
+	if err != nil {
+		return row, fmt.Errorf("failed to load change request: %w", err)
+	}
+	if row.Status != StatusPending {
+		return row, ErrAlreadyReviewed
+	}
+	return row, nil
+}
+
+func (r ChangeRequest) change() network_service.Change {
+	return network_service.Change{
+		Entity:   r.Entity,
+		Action:   r.Action,
+		EntityID: r.EntityID,
+		Payload:  r.Payload,
+	}
+}
+
+func toChangeRequest(row database.ChangeRequest) ChangeRequest {
+	req := ChangeRequest{
+		ID:             row.ID,
+		Entity:         row.Entity,
+		Action:         row.Action,
+		EntityID:       row.EntityID.Int64,
+		Payload:        row.Payload,
+		Status:         row.Status,
+		SubmittedBy:    row.SubmittedBy.Int64,
+		SubmissionID:   row.SubmissionID.Int64,
+		ReviewedBy:     row.ReviewedBy.Int64,
+		NetworkVersion: row.NetworkVersion.Int64,
+		CreatedAt:      row.CreatedAt.Time,
+	}
+	if row.ReviewedAt.Valid {
+		req.ReviewedAt = &row.ReviewedAt.Time
+	}
+	return req
+}
+
+func toComment(row database.ChangeRequestComment) Comment {
+	return Comment{
+		ID:        row.ID,
+		UserID:    row.UserID.Int64,
+		Body:      row.Body,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+func nullInt8(v int64) pgtype.Int8 {
+	return pgtype.Int8{Int64: v, Valid: v != 0}
+}
@@ -0,0 +1,48 @@
+package network_service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache keeps a value built from the network snapshot in memory, so services
+// reading the network on every request do not hit the database each time.
+// Edits show up once ttl has passed
+type Cache[T any] struct {
+	network *Service
+	ttl     time.Duration
+	build   func(Snapshot) T
+
+	mu       sync.Mutex
+	value    T
+	loaded   bool
+	loadedAt time.Time
+}
+
+func NewCache[T any](network *Service, ttl time.Duration, build func(Snapshot) T) *Cache[T] {
+	return &Cache[T]{
+		network: network,
+		ttl:     ttl,
+		build:   build,
+	}
+}
+
+// Get returns the cached value, rebuilding it when it is older than the ttl
+func (c *Cache[T]) Get(ctx context.Context) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded && time.Since(c.loadedAt) < c.ttl {
+		return c.value, nil
+	}
+
+	snap, err := c.network.Snapshot(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	c.value, c.loaded, c.loadedAt = c.build(snap), true, time.Now()
+	return c.value, nil
+}
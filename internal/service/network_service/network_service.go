@@ -0,0 +1,983 @@
+package network_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	EntityStop        = "stop"
+	EntityRoute       = "route"
+	EntityTripPattern = "trip_pattern"
+	EntityFare        = "fare"
+
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	// ActionMerge is recorded in the audit log for stops merged into another
+	ActionMerge = "merge"
+
+	FareFlat       = "flat"
+	FareDistance   = "distance"
+	FareZone       = "zone"
+	FareTransfer   = "transfer"
+	FareConcession = "concession"
+
+	// reloadTimeout bounds a router reload, which outlives the request that
+	// published the version
+	reloadTimeout = 2 * time.Minute
+)
+
+var (
+	Entities = []string{EntityStop, EntityRoute, EntityTripPattern, EntityFare}
+	Actions  = []string{ActionCreate, ActionUpdate, ActionDelete}
+)
+
+var (
+	ErrNotFound      = errors.New("network entity not found")
+	ErrInvalidChange = errors.New("invalid network change")
+)
+
+// Reloader is told when a new network version is published so the router
+// can pick it up
+type Reloader interface {
+	ReloadNetwork(ctx context.Context, version int64) error
+}
+
+// Stop is a boarding point of the network
+type Stop struct {
+	ID int64 `json:"id"`
+	StopParams
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type StopParams struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+	Zone string  `json:"zone,omitempty"`
+	// WheelchairBoarding is one of the route_service.Wheelchair values
+	WheelchairBoarding int `json:"wheelchair_boarding"`
+	// HasStairs and HasOverpass mark stops only reachable by steps or a
+	// pedestrian bridge
+	HasStairs   bool `json:"has_stairs"`
+	HasOverpass bool `json:"has_overpass"`
+}
+
+// Route is a line, with the shape vehicles follow
+type Route struct {
+	ID        int64                      `json:"id"`
+	ShortName string                     `json:"short_name"`
+	LongName  string                     `json:"long_name"`
+	Mode      string                     `json:"mode"`
+	Shape     []route_service.Coordinate `json:"shape"`
+	// WheelchairAccessible is one of the route_service.Wheelchair values
+	WheelchairAccessible int       `json:"wheelchair_accessible"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+type RouteParams struct {
+	ShortName string                     `json:"short_name"`
+	LongName  string                     `json:"long_name"`
+	Mode      string                     `json:"mode"`
+	Shape     []route_service.Coordinate `json:"shape"`
+	// WheelchairAccessible is one of the route_service.Wheelchair values
+	WheelchairAccessible int `json:"wheelchair_accessible"`
+	// Fare creates a flat fare along with a new route
+	Fare *float64 `json:"fare,omitempty"`
+}
+
+// TripPattern is an ordered list of stops served by a route
+type TripPattern struct {
+	ID        int64     `json:"id"`
+	RouteID   int64     `json:"route_id"`
+	Headsign  string    `json:"headsign"`
+	StopIDs   []int64   `json:"stop_ids"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TripPatternParams struct {
+	RouteID  int64   `json:"route_id"`
+	Headsign string  `json:"headsign"`
+	StopIDs  []int64 `json:"stop_ids"`
+}
+
+// Fare is a fare rule, see FareParams
+type Fare struct {
+	ID int64 `json:"id"`
+	FareParams
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FareParams describes a fare rule. Base rules (flat, distance and zone)
+// price a ride, transfer and concession rules discount it. A rule without a
+// route applies to every route, or every route of Mode when that is set
+type FareParams struct {
+	Kind    string  `json:"kind"`
+	RouteID int64   `json:"route_id,omitempty"`
+	Mode    string  `json:"mode,omitempty"`
+	Amount  float64 `json:"amount,omitempty"`
+	// Bands price distance fares, the first band covering the ride applies
+	Bands    []DistanceBand `json:"bands,omitempty"`
+	FromZone string         `json:"from_zone,omitempty"`
+	ToZone   string         `json:"to_zone,omitempty"`
+	// Percent is the discount of transfer and concession rules
+	Percent float64 `json:"percent,omitempty"`
+	// WithinMinutes limits transfer discounts to rides boarded this soon
+	// after the previous one, 0 means any transfer
+	WithinMinutes int    `json:"within_minutes,omitempty"`
+	RiderCategory string `json:"rider_category,omitempty"`
+}
+
+// DistanceBand charges Amount for rides up to UpToMeters
+type DistanceBand struct {
+	UpToMeters float64 `json:"up_to_meters"`
+	Amount     float64 `json:"amount"`
+}
+
+// Change is a single edit of the network. Payload holds the fields to set,
+// on updates it only needs the ones that change
+type Change struct {
+	Entity   string          `json:"entity"`
+	Action   string          `json:"action"`
+	EntityID int64           `json:"entity_id,omitempty"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+}
+
+// Version describes the published network
+type Version struct {
+	Version     int64     `json:"version"`
+	PublishedAt time.Time `json:"published_at,omitzero"`
+	// Loaded is the version the router last confirmed, it lags Version
+	// while a reload is in flight or after one failed
+	Loaded int64 `json:"loaded_version"`
+}
+
+// Snapshot is a full copy of the network at a version
+type Snapshot struct {
+	Version      int64         `json:"version"`
+	Stops        []Stop        `json:"stops"`
+	Routes       []Route       `json:"routes"`
+	TripPatterns []TripPattern `json:"trip_patterns"`
+	Fares        []Fare        `json:"fares"`
+}
+
+type Service struct {
+	store    *postgres.Store
+	reloader Reloader
+	loaded   atomic.Int64
+
+	// serviceArea bounds where stops may be placed, zero means anywhere
+	serviceArea geo.BBox
+	// duplicateRadius rejects new stops this close to an existing one
+	duplicateRadius float64
+}
+
+// NewService creates the network service, reloader may be nil
+func NewService(store *postgres.Store, reloader Reloader, serviceArea geo.BBox, duplicateRadius float64) *Service {
+	return &Service{
+		store:           store,
+		reloader:        reloader,
+		serviceArea:     serviceArea,
+		duplicateRadius: duplicateRadius,
+	}
+}
+
+// Version returns the latest published network version and the one the
+// router confirmed loading
+func (s *Service) Version(ctx context.Context) (Version, error) {
+	v := Version{Loaded: s.loaded.Load()}
+
+	row, err := s.store.GetLatestNetworkVersion(ctx)
+	if postgres.IsNotFound(err) {
+		return v, nil
+	}
+	if err != nil {
+		return v, fmt.Errorf("failed to load network version: %w", err)
+	}
+
+	v.Version = row.Version
+	v.PublishedAt = row.CreatedAt.Time
+	return v, nil
+}
+
+// Loaded returns the network version the router confirmed loading
+func (s *Service) Loaded() int64 {
+	return s.loaded.Load()
+}
+
+// Snapshot returns the whole current network, this is what routers load.
+// It is read in one snapshot so a concurrent publish can not mix versions
+func (s *Service) Snapshot(ctx context.Context) (Snapshot, error) {
+	var snap Snapshot
+	err := s.store.InSnapshot(ctx, func(q *database.Queries) error {
+		row, err := q.GetLatestNetworkVersion(ctx)
+		if err != nil && !postgres.IsNotFound(err) {
+			return fmt.Errorf("failed to load network version: %w", err)
+		}
+		snap.Version = row.Version
+
+		stops, err := q.ListNetworkStops(ctx)
+		if snap.Stops, err = convert(stops, err, toStop); err != nil {
+			return err
+		}
+		routes, err := q.ListNetworkRoutes(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list routes: %w", err)
+		}
+		snap.Routes = make([]Route, len(routes))
+		for i, row := range routes {
+			if snap.Routes[i], err = toRoute(row); err != nil {
+				return err
+			}
+		}
+		patterns, err := q.ListNetworkTripPatterns(ctx)
+		if snap.TripPatterns, err = convert(patterns, err, toTripPattern); err != nil {
+			return err
+		}
+		fares, err := q.ListNetworkFares(ctx)
+		snap.Fares, err = toFares(fares, err)
+		return err
+	})
+	return snap, err
+}
+
+// Sync asks the router to load the latest published version, used at
+// startup so a restarted router does not serve stale data
+func (s *Service) Sync(ctx context.Context) {
+	v, err := s.Version(ctx)
+	if err != nil {
+		log.Printf("Error loading network version: %v", err)
+		return
+	}
+	if v.Version > 0 {
+		s.Reload(ctx, v.Version)
+	}
+}
+
+// Preview validates a change and returns the entity before and after it,
+// before is nil for creations and after is nil for deletions
+func (s *Service) Preview(ctx context.Context, change Change) (before, after any, err error) {
+	return s.preview(ctx, s.store.Queries, change)
+}
+
+// Edit applies a change directly, publishes a new network version and asks
+// the router to reload it. It returns the entity after the change, nil for
+// deletions
+func (s *Service) Edit(ctx context.Context, actorID int64, change Change) (any, int64, error) {
+	var result any
+	var version int64
+	err := s.store.InTx(ctx, func(q *database.Queries) error {
+		var err error
+		if result, err = s.Apply(ctx, q, actorID, 0, change); err != nil {
+			return err
+		}
+		version, err = s.Publish(ctx, q, actorID, 0)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.Reload(ctx, version)
+	return result, version, nil
+}
+
+// Apply performs a change in a transaction, recording it in the audit log
+// against changeRequestID when it came from the moderation queue
+func (s *Service) Apply(ctx context.Context, q *database.Queries, actorID, changeRequestID int64, change Change) (any, error) {
+	before, after, err := s.preview(ctx, q, change)
+	if err != nil {
+		return nil, err
+	}
+
+	id, result, err := apply(ctx, q, change, after)
+	if postgres.IsForeignKeyViolation(err) {
+		return nil, fmt.Errorf("%w: referenced route does not exist", ErrInvalidChange)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s %s: %w", change.Action, change.Entity, err)
+	}
+
+	if err := Audit(ctx, q, actorID, change.Action, change.Entity, id, changeRequestID, before, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MergeStops folds duplicate stops into keepID, repointing every trip
+// pattern that used them
+func (s *Service) MergeStops(ctx context.Context, actorID, keepID int64, stopIDs []int64) (Stop, int64, error) {
+	// Listing a stop twice must not make the second lookup miss
+	stopIDs = slices.Compact(slices.Sorted(slices.Values(stopIDs)))
+	if len(stopIDs) == 0 || slices.Contains(stopIDs, keepID) {
+		return Stop{}, 0, fmt.Errorf("%w: merge needs other stops than the one kept", ErrInvalidChange)
+	}
+
+	var kept Stop
+	var version int64
+	err := s.store.InTx(ctx, func(q *database.Queries) error {
+		row, err := q.GetNetworkStop(ctx, keepID)
+		if postgres.IsNotFound(err) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load stop: %w", err)
+		}
+		kept = toStop(row)
+
+		for _, id := range stopIDs {
+			merged, err := q.GetNetworkStop(ctx, id)
+			if postgres.IsNotFound(err) {
+				return ErrNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load stop: %w", err)
+			}
+			if _, err := q.ReplaceStopInTripPatterns(ctx, database.ReplaceStopInTripPatternsParams{OldStopID: id, NewStopID: keepID}); err != nil {
+				return fmt.Errorf("failed to repoint trip patterns: %w", err)
+			}
+			if _, err := q.DeleteNetworkStop(ctx, id); err != nil {
+				return fmt.Errorf("failed to delete merged stop: %w", err)
+			}
+			if err := Audit(ctx, q, actorID, ActionMerge, EntityStop, id, 0, toStop(merged), kept); err != nil {
+				return err
+			}
+		}
+
+		version, err = s.Publish(ctx, q, actorID, 0)
+		return err
+	})
+	if err != nil {
+		return Stop{}, 0, err
+	}
+
+	s.Reload(ctx, version)
+	return kept, version, nil
+}
+
+// List returns every entity of a kind
+func (s *Service) List(ctx context.Context, entity string) (any, error) {
+	switch entity {
+	case EntityStop:
+		rows, err := s.store.ListNetworkStops(ctx)
+		return convert(rows, err, toStop)
+	case EntityRoute:
+		rows, err := s.store.ListNetworkRoutes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list routes: %w", err)
+		}
+		routes := make([]Route, len(rows))
+		for i, row := range rows {
+			if routes[i], err = toRoute(row); err != nil {
+				return nil, err
+			}
+		}
+		return routes, nil
+	case EntityTripPattern:
+		rows, err := s.store.ListNetworkTripPatterns(ctx)
+		return convert(rows, err, toTripPattern)
+	case EntityFare:
+		rows, err := s.store.ListNetworkFares(ctx)
+		return toFares(rows, err)
+	}
+	return nil, fmt.Errorf("%w: unknown entity '%s'", ErrInvalidChange, entity)
+}
+
+// Publish records a new network version
+func (s *Service) Publish(ctx context.Context, q *database.Queries, actorID, changeRequestID int64) (int64, error) {
+	v, err := q.CreateNetworkVersion(ctx, database.CreateNetworkVersionParams{
+		ChangeRequestID: nullInt8(changeRequestID),
+		CreatedBy:       nullInt8(actorID),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish network version: %w", err)
+	}
+	return v.Version, nil
+}
+
+// Reload asks the router to load a newly published version. Failures are
+// only logged, the version stays published and the next reload picks it up.
+// The reload is not cancelled with ctx, and a reload finishing after a newer
+// one does not move the loaded version back
+func (s *Service) Reload(ctx context.Context, version int64) {
+	if s.reloader == nil {
+		log.Printf("Network version %d published, no router reload configured", version)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), reloadTimeout)
+	defer cancel()
+	if err := s.reloader.ReloadNetwork(ctx, version); err != nil {
+		log.Printf("Error reloading network version %d: %v", version, err)
+		return
+	}
+	for {
+		loaded := s.loaded.Load()
+		if version <= loaded || s.loaded.CompareAndSwap(loaded, version) {
+			return
+		}
+	}
+}
+
+// Audit appends an entry to the audit log
+func Audit(ctx context.Context, q *database.Queries, actorID int64, action, entity string, entityID, changeRequestID int64, before, after any) error {
+	params := database.CreateAuditEntryParams{
+		ActorID:         nullInt8(actorID),
+		Action:          action,
+		Entity:          entity,
+		EntityID:        nullInt8(entityID),
+		ChangeRequestID: nullInt8(changeRequestID),
+	}
+
+	var err error
+	if before != nil {
+		if params.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if params.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+
+	if err := q.CreateAuditEntry(ctx, params); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) preview(ctx context.Context, q *database.Queries, change Change) (before, after any, err error) {
+	switch {
+	case !slices.Contains(Entities, change.Entity):
+		return nil, nil, fmt.Errorf("%w: unknown entity '%s'", ErrInvalidChange, change.Entity)
+	case !slices.Contains(Actions, change.Action):
+		return nil, nil, fmt.Errorf("%w: unknown action '%s'", ErrInvalidChange, change.Action)
+	case change.Action == ActionCreate && change.EntityID != 0:
+		return nil, nil, fmt.Errorf("%w: entity_id must not be set when creating", ErrInvalidChange)
+	case change.Action != ActionCreate && change.EntityID == 0:
+		return nil, nil, fmt.Errorf("%w: entity_id is required", ErrInvalidChange)
+	}
+
+	switch change.Entity {
+	case EntityStop:
+		return previewEntity(ctx, q, change, loadStop, stopParams, s.validateStop)
+	case EntityRoute:
+		return previewEntity(ctx, q, change, loadRoute, routeParams, validateRoute)
+	case EntityTripPattern:
+		return previewEntity(ctx, q, change, loadTripPattern, tripPatternParams, validateTripPattern)
+	default:
+		return previewEntity(ctx, q, change, loadFare, fareParams, validateFare)
+	}
+}
+
+// previewEntity loads the current entity and merges the payload over it
+func previewEntity[E, P any](
+	ctx context.Context,
+	q *database.Queries,
+	change Change,
+	load func(context.Context, *database.Queries, int64) (E, error),
+	toParams func(E) P,
+	validate func(context.Context, *database.Queries, int64, *P) error,
+) (any, any, error) {
+	var current P
+	var before any
+	if change.Action != ActionCreate {
+		entity, err := load(ctx, q, change.EntityID)
+		if postgres.IsNotFound(err) {
+			return nil, nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s: %w", change.Entity, err)
+		}
+		current = toParams(entity)
+		before = current
+	}
+	if change.Action == ActionDelete {
+		return before, nil, nil
+	}
+
+	after, err := merge(current, change.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validate(ctx, q, change.EntityID, &after); err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// merge overlays the fields in payload on current. Unknown fields are
+// rejected so typos do not silently become no-ops
+func merge[P any](current P, payload json.RawMessage) (P, error) {
+	if len(payload) == 0 {
+		return current, nil
+	}
+
+	var probe P
+	dec := json.NewDecoder(strings.NewReader(string(payload)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&probe); err != nil {
+		return current, fmt.Errorf("%w: %v", ErrInvalidChange, err)
+	}
+
+	base, err := json.Marshal(current)
+	if err != nil {
+		return current, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return current, err
+	}
+	overlay := map[string]json.RawMessage{}
+	if err := json.Unmarshal(payload, &overlay); err != nil {
+		return current, fmt.Errorf("%w: %v", ErrInvalidChange, err)
+	}
+	for k, v := range overlay {
+		fields[k] = v
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return current, err
+	}
+	var result P
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return current, fmt.Errorf("%w: %v", ErrInvalidChange, err)
+	}
+	return result, nil
+}
+
+func apply(ctx context.Context, q *database.Queries, change Change, after any) (int64, any, error) {
+	if change.Action == ActionDelete {
+		return change.EntityID, nil, remove(ctx, q, change)
+	}
+
+	switch p := after.(type) {
+	case StopParams:
+		var row database.NetworkStop
+		var err error
+		if change.Action == ActionCreate {
+			row, err = q.CreateNetworkStop(ctx, database.CreateNetworkStopParams{
+				Name:               p.Name,
+				Lat:                p.Lat,
+				Lon:                p.Lon,
+				Zone:               p.Zone,
+				WheelchairBoarding: int16(p.WheelchairBoarding),
+				HasStairs:          p.HasStairs,
+				HasOverpass:        p.HasOverpass,
+			})
+		} else {
+			row, err = q.UpdateNetworkStop(ctx, database.UpdateNetworkStopParams{
+				ID:                 change.EntityID,
+				Name:               p.Name,
+				Lat:                p.Lat,
+				Lon:                p.Lon,
+				Zone:               p.Zone,
+				WheelchairBoarding: int16(p.WheelchairBoarding),
+				HasStairs:          p.HasStairs,
+				HasOverpass:        p.HasOverpass,
+			})
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		return row.ID, toStop(row), nil
+
+	case RouteParams:
+		shape, err := json.Marshal(p.Shape)
+		if err != nil {
+			return 0, nil, err
+		}
+		var row database.NetworkRoute
+		if change.Action == ActionCreate {
+			row, err = q.CreateNetworkRoute(ctx, database.CreateNetworkRouteParams{
+				ShortName:            p.ShortName,
+				LongName:             p.LongName,
+				Mode:                 p.Mode,
+				Shape:                shape,
+				WheelchairAccessible: int16(p.WheelchairAccessible),
+			})
+		} else {
+			row, err = q.UpdateNetworkRoute(ctx, database.UpdateNetworkRouteParams{
+				ID:                   change.EntityID,
+				ShortName:            p.ShortName,
+				LongName:             p.LongName,
+				Mode:                 p.Mode,
+				Shape:                shape,
+				WheelchairAccessible: int16(p.WheelchairAccessible),
+			})
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		if change.Action == ActionCreate && p.Fare != nil {
+			if _, err := q.CreateNetworkFare(ctx, database.CreateNetworkFareParams{
+				RouteID: nullInt8(row.ID),
+				Amount:  *p.Fare,
+				Kind:    FareFlat,
+				Bands:   []byte("[]"),
+			}); err != nil {
+				return 0, nil, err
+			}
+		}
+		route, err := toRoute(row)
+		return row.ID, route, err
+
+	case TripPatternParams:
+		var row database.NetworkTripPattern
+		var err error
+		if change.Action == ActionCreate {
+			row, err = q.CreateNetworkTripPattern(ctx, database.CreateNetworkTripPatternParams{RouteID: p.RouteID, Headsign: p.Headsign, StopIds: p.StopIDs})
+		} else {
+			row, err = q.UpdateNetworkTripPattern(ctx, database.UpdateNetworkTripPatternParams{ID: change.EntityID, RouteID: p.RouteID, Headsign: p.Headsign, StopIds: p.StopIDs})
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		return row.ID, toTripPattern(row), nil
+
+	case FareParams:
+		bands, err := json.Marshal(p.Bands)
+		if err != nil {
+			return 0, nil, err
+		}
+		var row database.NetworkFare
+		if change.Action == ActionCreate {
+			row, err = q.CreateNetworkFare(ctx, database.CreateNetworkFareParams{
+				RouteID:       nullInt8(p.RouteID),
+				Amount:        p.Amount,
+				Kind:          p.Kind,
+				Mode:          p.Mode,
+				Bands:         bands,
+				FromZone:      p.FromZone,
+				ToZone:        p.ToZone,
+				Percent:       p.Percent,
+				WithinMinutes: int32(p.WithinMinutes),
+				RiderCategory: p.RiderCategory,
+			})
+		} else {
+			row, err = q.UpdateNetworkFare(ctx, database.UpdateNetworkFareParams{
+				ID:            change.EntityID,
+				RouteID:       nullInt8(p.RouteID),
+				Amount:        p.Amount,
+				Kind:          p.Kind,
+				Mode:          p.Mode,
+				Bands:         bands,
+				FromZone:      p.FromZone,
+				ToZone:        p.ToZone,
+				Percent:       p.Percent,
+				WithinMinutes: int32(p.WithinMinutes),
+				RiderCategory: p.RiderCategory,
+			})
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		fare, err := toFare(row)
+		return row.ID, fare, err
+	}
+	return 0, nil, fmt.Errorf("%w: unsupported change", ErrInvalidChange)
+}
+
+func remove(ctx context.Context, q *database.Queries, change Change) error {
+	var n int64
+	var err error
+	switch change.Entity {
+	case EntityStop:
+		var used int64
+		if used, err = q.CountTripPatternsUsingStop(ctx, change.EntityID); err != nil {
+			return err
+		}
+		if used > 0 {
+			return fmt.Errorf("%w: stop is used by %d trip patterns", ErrInvalidChange, used)
+		}
+		n, err = q.DeleteNetworkStop(ctx, change.EntityID)
+	case EntityRoute:
+		n, err = q.DeleteNetworkRoute(ctx, change.EntityID)
+	case EntityTripPattern:
+		n, err = q.DeleteNetworkTripPattern(ctx, change.EntityID)
+	case EntityFare:
+		n, err = q.DeleteNetworkFare(ctx, change.EntityID)
+	}
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Service) validateStop(ctx context.Context, q *database.Queries, id int64, p *StopParams) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("%w: stop name is required", ErrInvalidChange)
+	}
+	point := geo.Point{Lat: p.Lat, Lon: p.Lon}
+	if !point.Valid() || (p.Lat == 0 && p.Lon == 0) {
+		return fmt.Errorf("%w: stop coordinates out of range", ErrInvalidChange)
+	}
+	if !s.serviceArea.Contains(point) {
+		return fmt.Errorf("%w: stop is outside the service area", ErrInvalidChange)
+	}
+	if !validWheelchair(p.WheelchairBoarding) {
+		return fmt.Errorf("%w: wheelchair_boarding must be 0, 1 or 2", ErrInvalidChange)
+	}
+
+	if s.duplicateRadius <= 0 {
+		return nil
+	}
+	box := geo.Around(point, s.duplicateRadius)
+	nearby, err := q.ListNetworkStopsInBox(ctx, database.ListNetworkStopsInBoxParams{
+		MinLat: box.MinLat,
+		MaxLat: box.MaxLat,
+		MinLon: box.MinLon,
+		MaxLon: box.MaxLon,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check nearby stops: %w", err)
+	}
+	for _, other := range nearby {
+		if other.ID == id {
+			continue
+		}
+		if d := geo.Distance(point, geo.Point{Lat: other.Lat, Lon: other.Lon}); d <= s.duplicateRadius {
+			return fmt.Errorf("%w: stop '%s' (%d) is only %.0f meters away, merge instead", ErrInvalidChange, other.Name, other.ID, d)
+		}
+	}
+	return nil
+}
+
+func validateRoute(_ context.Context, _ *database.Queries, _ int64, p *RouteParams) error {
+	p.ShortName = strings.TrimSpace(p.ShortName)
+	p.LongName = strings.TrimSpace(p.LongName)
+	switch {
+	case p.ShortName == "":
+		return fmt.Errorf("%w: route short_name is required", ErrInvalidChange)
+	case p.Mode == "":
+		return fmt.Errorf("%w: route mode is required", ErrInvalidChange)
+	case p.Fare != nil && *p.Fare < 0:
+		return fmt.Errorf("%w: fare must not be negative", ErrInvalidChange)
+	case !validWheelchair(p.WheelchairAccessible):
+		return fmt.Errorf("%w: wheelchair_accessible must be 0, 1 or 2", ErrInvalidChange)
+	}
+	for _, c := range p.Shape {
+		if !(geo.Point{Lat: c.Lat, Lon: c.Lon}).Valid() {
+			return fmt.Errorf("%w: shape coordinates out of range", ErrInvalidChange)
+		}
+	}
+	if p.Shape == nil {
+		p.Shape = []route_service.Coordinate{}
+	}
+	return nil
+}
+
+func validateTripPattern(ctx context.Context, q *database.Queries, _ int64, p *TripPatternParams) error {
+	p.Headsign = strings.TrimSpace(p.Headsign)
+	switch {
+	case p.RouteID == 0:
+		return fmt.Errorf("%w: trip pattern route_id is required", ErrInvalidChange)
+	case p.Headsign == "":
+		return fmt.Errorf("%w: trip pattern headsign is required", ErrInvalidChange)
+	case len(p.StopIDs) < 2:
+		return fmt.Errorf("%w: trip pattern needs at least two stops", ErrInvalidChange)
+	}
+
+	missing, err := q.CountMissingNetworkStops(ctx, p.StopIDs)
+	if err != nil {
+		return fmt.Errorf("failed to check stops: %w", err)
+	}
+	if missing > 0 {
+		return fmt.Errorf("%w: %d stops of the pattern do not exist", ErrInvalidChange, missing)
+	}
+	return nil
+}
+
+func validateFare(_ context.Context, _ *database.Queries, _ int64, p *FareParams) error {
+	if p.Kind == "" {
+		p.Kind = FareFlat
+	}
+	if p.Amount < 0 {
+		return fmt.Errorf("%w: fare amount must not be negative", ErrInvalidChange)
+	}
+	if p.Percent < 0 || p.Percent > 100 {
+		return fmt.Errorf("%w: fare percent must be between 0 and 100", ErrInvalidChange)
+	}
+
+	switch p.Kind {
+	case FareFlat:
+	case FareDistance:
+		if len(p.Bands) == 0 {
+			return fmt.Errorf("%w: distance fare needs bands", ErrInvalidChange)
+		}
+		for i, b := range p.Bands {
+			if b.Amount < 0 || b.UpToMeters <= 0 || (i > 0 && b.UpToMeters <= p.Bands[i-1].UpToMeters) {
+				return fmt.Errorf("%w: distance bands must grow and have non-negative amounts", ErrInvalidChange)
+			}
+		}
+	case FareZone:
+		if p.FromZone == "" || p.ToZone == "" {
+			return fmt.Errorf("%w: zone fare needs from_zone and to_zone", ErrInvalidChange)
+		}
+	case FareTransfer:
+		if p.Amount == 0 && p.Percent == 0 {
+			return fmt.Errorf("%w: transfer discount needs an amount or a percent", ErrInvalidChange)
+		}
+		if p.WithinMinutes < 0 {
+			return fmt.Errorf("%w: within_minutes must not be negative", ErrInvalidChange)
+		}
+	case FareConcession:
+		if !slices.Contains(route_service.RiderCategories, p.RiderCategory) {
+			return fmt.Errorf("%w: concession needs a rider_category out of %s", ErrInvalidChange, strings.Join(route_service.RiderCategories, ", "))
+		}
+		if p.Percent == 0 {
+			return fmt.Errorf("%w: concession needs a percent", ErrInvalidChange)
+		}
+	default:
+		return fmt.Errorf("%w: unknown fare kind '%s'", ErrInvalidChange, p.Kind)
+	}
+	return nil
+}
+
+func loadStop(ctx context.Context, q *database.Queries, id int64) (database.NetworkStop, error) {
+	return q.GetNetworkStop(ctx, id)
+}
+
+func loadRoute(ctx context.Context, q *database.Queries, id int64) (database.NetworkRoute, error) {
+	return q.GetNetworkRoute(ctx, id)
+}
+
+func loadTripPattern(ctx context.Context, q *database.Queries, id int64) (database.NetworkTripPattern, error) {
+	return q.GetNetworkTripPattern(ctx, id)
+}
+
+func loadFare(ctx context.Context, q *database.Queries, id int64) (database.NetworkFare, error) {
+	return q.GetNetworkFare(ctx, id)
+}
+
+func stopParams(row database.NetworkStop) StopParams {
+	return StopParams{
+		Name:               row.Name,
+		Lat:                row.Lat,
+		Lon:                row.Lon,
+		Zone:               row.Zone,
+		WheelchairBoarding: int(row.WheelchairBoarding),
+		HasStairs:          row.HasStairs,
+		HasOverpass:        row.HasOverpass,
+	}
+}
+
+func routeParams(row database.NetworkRoute) RouteParams {
+	// A corrupt shape shows up as empty in the diff rather than failing it
+	route, _ := toRoute(row)
+	return RouteParams{
+		ShortName:            row.ShortName,
+		LongName:             row.LongName,
+		Mode:                 row.Mode,
+		Shape:                route.Shape,
+		WheelchairAccessible: route.WheelchairAccessible,
+	}
+}
+
+func tripPatternParams(row database.NetworkTripPattern) TripPatternParams {
+	return TripPatternParams{RouteID: row.RouteID, Headsign: row.Headsign, StopIDs: row.StopIds}
+}
+
+func fareParams(row database.NetworkFare) FareParams {
+	// A corrupt band list shows up as empty in the diff rather than failing it
+	fare, _ := toFare(row)
+	return fare.FareParams
+}
+
+func toStop(row database.NetworkStop) Stop {
+	return Stop{ID: row.ID, StopParams: stopParams(row), UpdatedAt: row.UpdatedAt.Time}
+}
+
+func toRoute(row database.NetworkRoute) (Route, error) {
+	route := Route{
+		ID:                   row.ID,
+		ShortName:            row.ShortName,
+		LongName:             row.LongName,
+		Mode:                 row.Mode,
+		WheelchairAccessible: int(row.WheelchairAccessible),
+		UpdatedAt:            row.UpdatedAt.Time,
+	}
+	if err := json.Unmarshal(row.Shape, &route.Shape); err != nil {
+		return route, fmt.Errorf("failed to decode route shape: %w", err)
+	}
+	return route, nil
+}
+
+func toTripPattern(row database.NetworkTripPattern) TripPattern {
+	return TripPattern{ID: row.ID, RouteID: row.RouteID, Headsign: row.Headsign, StopIDs: row.StopIds, UpdatedAt: row.UpdatedAt.Time}
+}
+
+func toFare(row database.NetworkFare) (Fare, error) {
+	fare := Fare{
+		ID: row.ID,
+		FareParams: FareParams{
+			Kind:          row.Kind,
+			RouteID:       row.RouteID.Int64,
+			Mode:          row.Mode,
+			Amount:        row.Amount,
+			FromZone:      row.FromZone,
+			ToZone:        row.ToZone,
+			Percent:       row.Percent,
+			WithinMinutes: int(row.WithinMinutes),
+			RiderCategory: row.RiderCategory,
+		},
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+	if err := json.Unmarshal(row.Bands, &fare.Bands); err != nil {
+		return fare, fmt.Errorf("failed to decode fare bands: %w", err)
+	}
+	return fare, nil
+}
+
+func convert[R, E any](rows []R, err error, to func(R) E) ([]E, error) {
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network: %w", err)
+	}
+	entities := make([]E, len(rows))
+	for i, row := range rows {
+		entities[i] = to(row)
+	}
+	return entities, nil
+}
+
+func validWheelchair(v int) bool {
+	return v == route_service.WheelchairUnknown || v == route_service.WheelchairAccessible || v == route_service.WheelchairInaccessible
+}
+
+func toFares(rows []database.NetworkFare, err error) ([]Fare, error) {
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fares: %w", err)
+	}
+	fares := make([]Fare, len(rows))
+	for i, row := range rows {
+		if fares[i], err = toFare(row); err != nil {
+			return nil, err
+		}
+	}
+	return fares, nil
+}
+
+func nullInt8(v int64) pgtype.Int8 {
+	return pgtype.Int8{Int64: v, Valid: v != 0}
+}
@@ -0,0 +1,33 @@
+package network_service
+
+import (
+	"context"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// Router decorates another Router, stamping responses with the network
+// version the router last confirmed when the backend does not report one
+type Router struct {
+	route_service.Router
+	network *Service
+}
+
+func NewRouter(inner route_service.Router, network *Service) *Router {
+	return &Router{
+		Router:  inner,
+		network: network,
+	}
+}
+
+func (r *Router) FindRoute(ctx context.Context, req route_service.RouteRequest) (route_service.RouteResponse, error) {
+	resp, err := r.Router.FindRoute(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	if resp.NetworkVersion == 0 {
+		resp.NetworkVersion = r.network.Loaded()
+	}
+	return resp, nil
+}
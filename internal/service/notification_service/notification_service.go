@@ -0,0 +1,383 @@
+package notification_service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/directions"
+	"github.com/Marwan051/final_project_backend/internal/notify"
+	"github.com/Marwan051/final_project_backend/internal/service/alert_service"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	DefaultLeadMinutes = 10
+	MaxLeadMinutes     = 120
+	// MaxScheduleAhead bounds how far in the future a reminder can be set
+	MaxScheduleAhead = 30 * 24 * time.Hour
+	// ConfirmWithin is how long an email target has to be confirmed before it is deleted
+	ConfirmWithin = 48 * time.Hour
+	// ConfirmResendAfter is how long until subscribing again mails a new confirmation
+	ConfirmResendAfter = 15 * time.Minute
+	// MaxUnconfirmed caps the unconfirmed email targets per user
+	MaxUnconfirmed = 3
+)
+
+// Job kinds
+const (
+	KindReminder = "reminder"
+	KindAlert    = "alert"
+)
+
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrInvalidSubscription = errors.New("invalid subscription")
+	ErrTooManyUnconfirmed  = errors.New("too many unconfirmed email addresses, confirm or remove one first")
+	ErrInvalidReminder     = errors.New("invalid reminder")
+	ErrWebPushDisabled     = errors.New("web push is not configured")
+)
+
+// SubscriptionParams registers a device or address. For web push, Target is
+// the endpoint of the browser's PushSubscription and P256dh and Auth its keys
+type SubscriptionParams struct {
+	Channel string `json:"channel"`
+	Target  string `json:"target"`
+	P256dh  string `json:"p256dh,omitempty"`
+	Auth    string `json:"auth,omitempty"`
+}
+
+// Subscription is a registered delivery target, keys are never returned.
+// Email targets are not Confirmed until the link mailed to them is opened
+type Subscription struct {
+	ID        int64     `json:"id"`
+	Channel   string    `json:"channel"`
+	Target    string    `json:"target"`
+	Confirmed bool      `json:"confirmed"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReminderParams schedules a reminder LeadMinutes before DepartAt, by default
+// DefaultLeadMinutes. WatchAlerts defaults to true
+type ReminderParams struct {
+	Journey     route_service.Journey `json:"journey"`
+	DepartAt    time.Time             `json:"depart_at"`
+	LeadMinutes int                   `json:"lead_minutes,omitempty"`
+	WatchAlerts *bool                 `json:"watch_alerts,omitempty"`
+}
+
+// Reminder is a scheduled departure reminder for a saved journey. Its
+// notifications are written in Locale, the language it was created in
+type Reminder struct {
+	ID          int64                 `json:"id"`
+	Journey     route_service.Journey `json:"journey"`
+	DepartAt    time.Time             `json:"depart_at"`
+	RemindAt    time.Time             `json:"remind_at"`
+	LeadMinutes int                   `json:"lead_minutes"`
+	WatchAlerts bool                  `json:"watch_alerts"`
+	Locale      directions.Locale     `json:"-"`
+	CreatedAt   time.Time             `json:"created_at"`
+}
+
+// Service stores subscriptions and reminders and delivers the notification
+// jobs queued for them
+type Service struct {
+	store      *postgres.Store
+	transports map[string]notify.Transport
+	alerts     *alert_service.Service
+	baseURL    string
+
+	lastPurge time.Time
+}
+
+// NewService delivers over the given transports keyed by channel, channels
+// without a transport cannot be subscribed to. Confirmation links point at baseURL
+func NewService(store *postgres.Store, transports map[string]notify.Transport, alerts *alert_service.Service, baseURL string) *Service {
+	return &Service{
+		store:      store,
+		transports: transports,
+		alerts:     alerts,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// VAPIDPublicKey is the application server key browsers subscribe with
+func (s *Service) VAPIDPublicKey() (string, error) {
+	wp, ok := s.transports[notify.ChannelWebPush].(*notify.WebPush)
+	if !ok {
+		return "", ErrWebPushDisabled
+	}
+	return wp.PublicKey(), nil
+}
+
+// Subscribe registers a delivery target. Email targets are mailed a
+// confirmation link and receive nothing else until it is opened, so an
+// account cannot make the server mail addresses it does not own. Subscribing
+// again resends the link at most every ConfirmResendAfter
+func (s *Service) Subscribe(ctx context.Context, userID int64, params SubscriptionParams) (Subscription, error) {
+	if err := validateSubscription(params, s.transports); err != nil {
+		return Subscription{}, err
+	}
+
+	arg := database.CreateNotificationSubscriptionParams{
+		UserID:  userID,
+		Channel: params.Channel,
+		Target:  params.Target,
+		P256dh:  params.P256dh,
+		Auth:    params.Auth,
+	}
+	now := time.Now()
+	token := ""
+	if params.Channel == notify.ChannelEmail {
+		pending, err := s.store.CountUnconfirmedNotificationSubscriptions(ctx, database.CountUnconfirmedNotificationSubscriptionsParams{
+			UserID: userID,
+			Target: params.Target,
+		})
+		if err != nil {
+			return Subscription{}, fmt.Errorf("failed to count unconfirmed subscriptions: %w", err)
+		}
+		if pending >= MaxUnconfirmed {
+			return Subscription{}, ErrTooManyUnconfirmed
+		}
+
+		token = rand.Text()
+		arg.ConfirmTokenHash = hashToken(token)
+		arg.ConfirmSentAt = pgtype.Timestamptz{Time: now, Valid: true}
+		arg.ResendBefore = pgtype.Timestamptz{Time: now.Add(-ConfirmResendAfter), Valid: true}
+	} else {
+		arg.ConfirmedAt = pgtype.Timestamptz{Time: now, Valid: true}
+	}
+
+	row, err := s.store.CreateNotificationSubscription(ctx, arg)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("failed to save subscription: %w", err)
+	}
+
+	// The token is only stored when this call may send it
+	if !row.ConfirmedAt.Valid && token != "" && row.ConfirmTokenHash == arg.ConfirmTokenHash {
+		ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+		defer cancel()
+		err := s.transports[row.Channel].Send(ctx, notify.Subscription{Channel: row.Channel, Target: row.Target}, confirmMessage(s.baseURL, token))
+		if err != nil {
+			return Subscription{}, fmt.Errorf("failed to send confirmation: %w", err)
+		}
+	}
+	return toSubscription(row), nil
+}
+
+// ConfirmSubscription activates the email target the token was mailed to
+func (s *Service) ConfirmSubscription(ctx context.Context, token string) error {
+	if token == "" {
+		return ErrNotFound
+	}
+	n, err := s.store.ConfirmNotificationSubscription(ctx, hashToken(token))
+	if err != nil {
+		return fmt.Errorf("failed to confirm subscription: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Service) ListSubscriptions(ctx context.Context, userID int64) ([]Subscription, error) {
+	rows, err := s.store.ListNotificationSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	subs := make([]Subscription, len(rows))
+	for i, row := range rows {
+		subs[i] = toSubscription(row)
+	}
+	return subs, nil
+}
+
+func (s *Service) Unsubscribe(ctx context.Context, userID, id int64) error {
+	n, err := s.store.DeleteNotificationSubscription(ctx, database.DeleteNotificationSubscriptionParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CreateReminder saves the reminder and queues its notification, reminders
+// whose time has already come are sent on the next run. Notifications are
+// written for loc
+func (s *Service) CreateReminder(ctx context.Context, userID int64, params ReminderParams, loc directions.Locale) (Reminder, error) {
+	now := time.Now()
+	if err := validateReminder(&params, now); err != nil {
+		return Reminder{}, err
+	}
+
+	journey, err := json.Marshal(params.Journey)
+	if err != nil {
+		return Reminder{}, fmt.Errorf("failed to encode journey: %w", err)
+	}
+
+	var reminder Reminder
+	err = s.store.InTx(ctx, func(q *database.Queries) error {
+		row, err := q.CreateTripReminder(ctx, database.CreateTripReminderParams{
+			UserID:      userID,
+			Journey:     journey,
+			DepartAt:    pgtype.Timestamptz{Time: params.DepartAt, Valid: true},
+			LeadMinutes: int32(params.LeadMinutes),
+			WatchAlerts: *params.WatchAlerts,
+			Locale:      loc.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save reminder: %w", err)
+		}
+		if reminder, err = toReminder(row); err != nil {
+			return err
+		}
+
+		// The text counts down to departure, so it is written when the job is sent
+		return enqueue(ctx, q, userID, row.ID, KindReminder, "reminder:"+strconv.FormatInt(row.ID, 10),
+			notify.Message{}, reminder.RemindAt)
+	})
+	if err != nil {
+		return Reminder{}, err
+	}
+	return reminder, nil
+}
+
+// ListReminders returns the user's reminders that have not departed more than an hour ago
+func (s *Service) ListReminders(ctx context.Context, userID int64) ([]Reminder, error) {
+	rows, err := s.store.ListTripReminders(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reminders: %w", err)
+	}
+
+	reminders := make([]Reminder, len(rows))
+	for i, row := range rows {
+		if reminders[i], err = toReminder(row); err != nil {
+			return nil, err
+		}
+	}
+	return reminders, nil
+}
+
+// DeleteReminder removes the reminder along with its pending notifications
+func (s *Service) DeleteReminder(ctx context.Context, userID, id int64) error {
+	n, err := s.store.DeleteTripReminder(ctx, database.DeleteTripReminderParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete reminder: %w", err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func validateSubscription(params SubscriptionParams, transports map[string]notify.Transport) error {
+	if _, ok := transports[params.Channel]; !ok {
+		return fmt.Errorf("%w: channel '%s' is not available", ErrInvalidSubscription, params.Channel)
+	}
+	if params.Target == "" {
+		return fmt.Errorf("%w: target is required", ErrInvalidSubscription)
+	}
+
+	switch params.Channel {
+	case notify.ChannelWebPush:
+		if !notify.ValidPushEndpoint(params.Target) {
+			return fmt.Errorf("%w: web push target must be the https endpoint of a known push service", ErrInvalidSubscription)
+		}
+		if params.P256dh == "" || params.Auth == "" {
+			return fmt.Errorf("%w: web push needs the p256dh and auth keys", ErrInvalidSubscription)
+		}
+	case notify.ChannelEmail:
+		if _, err := mail.ParseAddress(params.Target); err != nil {
+			return fmt.Errorf("%w: invalid email address", ErrInvalidSubscription)
+		}
+	}
+	return nil
+}
+
+// validateReminder checks params and fills in the defaults
+func validateReminder(params *ReminderParams, now time.Time) error {
+	if len(params.Journey.Legs) == 0 {
+		return fmt.Errorf("%w: journey has no legs", ErrInvalidReminder)
+	}
+	if params.DepartAt.IsZero() {
+		return fmt.Errorf("%w: depart_at is required", ErrInvalidReminder)
+	}
+	if !params.DepartAt.After(now) {
+		return fmt.Errorf("%w: depart_at must be in the future", ErrInvalidReminder)
+	}
+	if params.DepartAt.After(now.Add(MaxScheduleAhead)) {
+		return fmt.Errorf("%w: depart_at is more than %d days away", ErrInvalidReminder, int(MaxScheduleAhead.Hours()/24))
+	}
+	if params.LeadMinutes < 0 || params.LeadMinutes > MaxLeadMinutes {
+		return fmt.Errorf("%w: lead_minutes must be between 0 and %d", ErrInvalidReminder, MaxLeadMinutes)
+	}
+
+	if params.LeadMinutes == 0 {
+		params.LeadMinutes = DefaultLeadMinutes
+	}
+	if params.WatchAlerts == nil {
+		watch := true
+		params.WatchAlerts = &watch
+	}
+	return nil
+}
+
+func toSubscription(row database.NotificationSubscription) Subscription {
+	return Subscription{
+		ID:        row.ID,
+		Channel:   row.Channel,
+		Target:    row.Target,
+		Confirmed: row.ConfirmedAt.Valid,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+func confirmMessage(baseURL, token string) notify.Message {
+	link := baseURL + "/api/v1/notifications/confirm?token=" + url.QueryEscape(token)
+	return notify.Message{
+		Title: "Confirm your trip notifications",
+		Body:  "Open this link to receive trip reminders and alerts at this address: " + link + "\n\nIf you did not ask for them, ignore this email.",
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toReminder(row database.TripReminder) (Reminder, error) {
+	r := Reminder{
+		ID:          row.ID,
+		DepartAt:    row.DepartAt.Time,
+		RemindAt:    row.DepartAt.Time.Add(-time.Duration(row.LeadMinutes) * time.Minute),
+		LeadMinutes: int(row.LeadMinutes),
+		WatchAlerts: row.WatchAlerts,
+		Locale:      directions.ParseAcceptLanguage(row.Locale),
+		CreatedAt:   row.CreatedAt.Time,
+	}
+	if err := json.Unmarshal(row.Journey, &r.Journey); err != nil {
+		return Reminder{}, fmt.Errorf("failed to decode journey: %w", err)
+	}
+	return r, nil
+}
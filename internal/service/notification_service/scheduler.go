@@ -0,0 +1,374 @@
+package notification_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/directions"
+	"github.com/Marwan051/final_project_backend/internal/notify"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// MaxAttempts is how many times a job is tried before it is marked failed
+	MaxAttempts = 5
+	// WatchHorizon is how long before departure a reminder starts watching for alerts
+	WatchHorizon = 3 * time.Hour
+
+	claimBatch = 50
+	// claimLease is how long a claimed job is held before another worker may take it
+	claimLease = 2 * time.Minute
+	// sendTimeout bounds the delivery of one job, a job is only started while
+	// at least this much of its lease is left
+	sendTimeout   = 30 * time.Second
+	retryBackoff  = 30 * time.Second
+	purgeInterval = time.Hour
+	keepJobs      = 7 * 24 * time.Hour
+	keepReminders = 24 * time.Hour
+)
+
+// Run watches reminders for alerts and delivers due notifications every
+// interval until ctx is done. Several instances may run against the same
+// database, jobs are claimed with row locks and alerts are deduplicated
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx, time.Now())
+		}
+	}
+}
+
+func (s *Service) tick(ctx context.Context, now time.Time) {
+	if s.alerts != nil {
+		if err := s.watchAlerts(ctx, now); err != nil {
+			log.Printf("Error watching reminders for alerts: %v", err)
+		}
+	}
+	if err := s.deliver(ctx); err != nil {
+		log.Printf("Error delivering notifications: %v", err)
+	}
+	if now.Sub(s.lastPurge) >= purgeInterval {
+		s.purge(ctx, now)
+		s.lastPurge = now
+	}
+}
+
+// errExpired fails a job that is no longer worth sending, such as a reminder
+// whose departure has passed
+var errExpired = errors.New("notification expired")
+
+// watchAlerts queues one notification per reminder and alert affecting its
+// journey between departure and arrival, for reminders departing within WatchHorizon
+func (s *Service) watchAlerts(ctx context.Context, now time.Time) error {
+	rows, err := s.store.ListWatchedTripReminders(ctx, pgtype.Timestamptz{Time: now.Add(WatchHorizon), Valid: true})
+	if err != nil {
+		return fmt.Errorf("failed to list watched reminders: %w", err)
+	}
+
+	for _, row := range rows {
+		reminder, err := toReminder(row)
+		if err != nil {
+			log.Printf("Error loading reminder %d: %v", row.ID, err)
+			continue
+		}
+
+		active, err := s.alerts.ActiveBetween(ctx, reminder.DepartAt, expiresAt(KindAlert, reminder))
+		if err != nil {
+			return err
+		}
+		for _, a := range active {
+			trip := affectedTrip(reminder.Journey, a)
+			if trip == nil {
+				continue
+			}
+			key := fmt.Sprintf("alert:%d:%s:%s", reminder.ID, a.Source, a.ID)
+			err := enqueue(ctx, s.store.Queries, row.UserID, reminder.ID, KindAlert, key, alertMessage(reminder, a, trip), now)
+			if err != nil {
+				log.Printf("Error queueing alert %s for reminder %d: %v", a.ID, reminder.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// deliver sends due jobs until none are left. Jobs are only started while
+// their lease has time for a send, the rest are handed back to the queue so
+// another worker cannot take them over mid-send
+func (s *Service) deliver(ctx context.Context) error {
+	for ctx.Err() == nil {
+		lease := time.Now().Add(claimLease)
+		jobs, err := s.store.ClaimNotificationJobs(ctx, database.ClaimNotificationJobsParams{
+			LockedUntil: pgtype.Timestamptz{Time: lease, Valid: true},
+			Batch:       claimBatch,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to claim notification jobs: %w", err)
+		}
+
+		for i, job := range jobs {
+			if !leaseLeft(lease, time.Now()) {
+				s.release(ctx, jobs[i:])
+				return nil
+			}
+			if err := s.process(ctx, job, time.Now()); err != nil {
+				s.release(ctx, jobs[i+1:])
+				return err
+			}
+		}
+		if len(jobs) < claimBatch {
+			return nil
+		}
+	}
+	return nil
+}
+
+// process builds the job's message, sends it and records the outcome
+func (s *Service) process(ctx context.Context, job database.NotificationJob, now time.Time) error {
+	msg, expires, err := s.message(ctx, job, now)
+	if err == nil {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err = s.send(sendCtx, job.UserID, msg)
+		cancel()
+	}
+	return s.finish(ctx, job, err, time.Now(), expires)
+}
+
+// message returns what to send for the job and when it stops being worth
+// sending, the zero time if never. Reminders are written at send time so the
+// countdown matches when they arrive
+func (s *Service) message(ctx context.Context, job database.NotificationJob, now time.Time) (notify.Message, time.Time, error) {
+	var msg notify.Message
+	var expires time.Time
+
+	if job.ReminderID.Valid {
+		row, err := s.store.GetTripReminder(ctx, job.ReminderID.Int64)
+		if postgres.IsNotFound(err) {
+			return msg, expires, errExpired
+		}
+		if err != nil {
+			return msg, expires, fmt.Errorf("failed to load reminder: %w", err)
+		}
+		reminder, err := toReminder(row)
+		if err != nil {
+			return msg, expires, err
+		}
+
+		expires = expiresAt(job.Kind, reminder)
+		if !expires.IsZero() && !now.Before(expires) {
+			return msg, expires, errExpired
+		}
+		if job.Kind == KindReminder {
+			return reminderMessage(reminder, now), expires, nil
+		}
+	}
+
+	if err := json.Unmarshal(job.Payload, &msg); err != nil {
+		return msg, expires, fmt.Errorf("failed to decode message: %w", err)
+	}
+	return msg, expires, nil
+}
+
+// send delivers a message to every confirmed subscription of the user. It
+// only fails when no subscription could be reached, retrying would notify
+// the others twice
+func (s *Service) send(ctx context.Context, userID int64, msg notify.Message) error {
+	subs, err := s.store.ListNotificationSubscriptions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	sent := 0
+	var errs []error
+	for _, sub := range subs {
+		transport, ok := s.transports[sub.Channel]
+		if !ok || !sub.ConfirmedAt.Valid {
+			continue
+		}
+
+		err := transport.Send(ctx, notify.Subscription{
+			Channel: sub.Channel,
+			Target:  sub.Target,
+			P256dh:  sub.P256dh,
+			Auth:    sub.Auth,
+		}, msg)
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, notify.ErrGone):
+			err = s.store.DeleteNotificationSubscriptionByTarget(ctx, database.DeleteNotificationSubscriptionByTargetParams{
+				Channel: sub.Channel,
+				Target:  sub.Target,
+			})
+			if err != nil {
+				log.Printf("Error deleting expired subscription %d: %v", sub.ID, err)
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s: %w", sub.Channel, err))
+		}
+	}
+
+	if sent == 0 && len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// finish records the outcome of a job, failed jobs are retried with an
+// exponential backoff until MaxAttempts or until they expire
+func (s *Service) finish(ctx context.Context, job database.NotificationJob, sendErr error, now, expires time.Time) error {
+	var err error
+	runAt, retry := retryAt(job.Attempts, now, expires)
+	switch {
+	case sendErr == nil:
+		err = s.store.CompleteNotificationJob(ctx, job.ID)
+	case errors.Is(sendErr, errExpired) || !retry:
+		if !errors.Is(sendErr, errExpired) {
+			log.Printf("Error sending notification %d, giving up: %v", job.ID, sendErr)
+		}
+		err = s.store.FailNotificationJob(ctx, database.FailNotificationJobParams{
+			ID:        job.ID,
+			LastError: sendErr.Error(),
+		})
+	default:
+		err = s.store.RetryNotificationJob(ctx, database.RetryNotificationJobParams{
+			ID:        job.ID,
+			RunAt:     pgtype.Timestamptz{Time: runAt, Valid: true},
+			LastError: sendErr.Error(),
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update notification job %d: %w", job.ID, err)
+	}
+	return nil
+}
+
+// release hands claimed jobs back to the queue without counting the attempt
+func (s *Service) release(ctx context.Context, jobs []database.NotificationJob) {
+	for _, job := range jobs {
+		if err := s.store.ReleaseNotificationJob(ctx, job.ID); err != nil {
+			log.Printf("Error releasing notification job %d: %v", job.ID, err)
+		}
+	}
+}
+
+// leaseLeft reports whether a job claimed until lease can still be sent at now
+func leaseLeft(lease, now time.Time) bool {
+	return lease.Sub(now) >= sendTimeout
+}
+
+// retryAt returns when a job that failed on its attempts-th try runs again,
+// or false when it has run out of attempts or the retry would come after it expires
+func retryAt(attempts int32, now, expires time.Time) (time.Time, bool) {
+	if attempts >= MaxAttempts {
+		return time.Time{}, false
+	}
+	at := now.Add(retryBackoff << (max(attempts, 1) - 1))
+	if !expires.IsZero() && !at.Before(expires) {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// expiresAt is when a job about the reminder stops being worth sending:
+// reminders at departure, alerts on arrival
+func expiresAt(kind string, r Reminder) time.Time {
+	switch kind {
+	case KindReminder:
+		return r.DepartAt
+	case KindAlert:
+		return r.DepartAt.Add(time.Duration(r.Journey.Summary.TotalTimeMinutes) * time.Minute)
+	}
+	return time.Time{}
+}
+
+// purge deletes delivered and failed jobs, reminders long past departure and
+// email targets never confirmed
+func (s *Service) purge(ctx context.Context, now time.Time) {
+	if _, err := s.store.DeleteFinishedNotificationJobs(ctx, pgtype.Timestamptz{Time: now.Add(-keepJobs), Valid: true}); err != nil {
+		log.Printf("Error purging notification jobs: %v", err)
+	}
+	if _, err := s.store.DeleteTripRemindersBefore(ctx, pgtype.Timestamptz{Time: now.Add(-keepReminders), Valid: true}); err != nil {
+		log.Printf("Error purging reminders: %v", err)
+	}
+	if _, err := s.store.DeleteUnconfirmedNotificationSubscriptions(ctx, pgtype.Timestamptz{Time: now.Add(-ConfirmWithin), Valid: true}); err != nil {
+		log.Printf("Error purging unconfirmed subscriptions: %v", err)
+	}
+}
+
+// enqueue queues a message for the user, a job whose dedupe key was already
+// queued is ignored
+func enqueue(ctx context.Context, q *database.Queries, userID, reminderID int64, kind, key string, msg notify.Message, runAt time.Time) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	_, err = q.EnqueueNotificationJob(ctx, database.EnqueueNotificationJobParams{
+		UserID:     userID,
+		ReminderID: pgtype.Int8{Int64: reminderID, Valid: true},
+		Kind:       kind,
+		DedupeKey:  pgtype.Text{String: key, Valid: true},
+		Payload:    payload,
+		RunAt:      pgtype.Timestamptz{Time: runAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue notification: %w", err)
+	}
+	return nil
+}
+
+// affectedTrip returns the first trip leg of the journey the alert applies to
+func affectedTrip(j route_service.Journey, a route_service.Alert) *route_service.TripLeg {
+	for _, leg := range j.Legs {
+		if leg.Trip != nil && a.Affects(leg.Trip) {
+			return leg.Trip
+		}
+	}
+	return nil
+}
+
+// reminderMessage counts down from now to departure, it is built when the
+// reminder is sent so a late run or a short lead shows the time actually left
+func reminderMessage(r Reminder, now time.Time) notify.Message {
+	minutes := min(r.LeadMinutes, int(math.Ceil(r.DepartAt.Sub(now).Minutes())))
+	notice := directions.LeaveNotice(&r.Journey, minutes, r.Locale)
+	return notify.Message{
+		Title: notice.Title,
+		Body:  notice.Body,
+		Data: map[string]string{
+			"kind":        KindReminder,
+			"reminder_id": strconv.FormatInt(r.ID, 10),
+			"depart_at":   r.DepartAt.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+func alertMessage(r Reminder, a route_service.Alert, trip *route_service.TripLeg) notify.Message {
+	notice := directions.AlertNotice(a, trip, r.Locale)
+	return notify.Message{
+		Title: notice.Title,
+		Body:  notice.Body,
+		Data: map[string]string{
+			"kind":        KindAlert,
+			"reminder_id": strconv.FormatInt(r.ID, 10),
+			"alert_id":    a.ID,
+			"effect":      a.Effect,
+			"depart_at":   r.DepartAt.UTC().Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,150 @@
+package notification_service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/directions"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+func TestRetryAt(t *testing.T) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		attempts  int32
+		expires   time.Time
+		want      time.Time
+		wantRetry bool
+	}{
+		{name: "first failure waits the base backoff", attempts: 1, want: now.Add(30 * time.Second), wantRetry: true},
+		{name: "backoff doubles per attempt", attempts: 3, want: now.Add(2 * time.Minute), wantRetry: true},
+		{name: "released job without attempts", attempts: 0, want: now.Add(30 * time.Second), wantRetry: true},
+		{name: "out of attempts", attempts: MaxAttempts},
+		{name: "retry before expiry", attempts: 1, expires: now.Add(time.Minute), want: now.Add(30 * time.Second), wantRetry: true},
+		{name: "retry at expiry gives up", attempts: 1, expires: now.Add(30 * time.Second)},
+		{name: "retry after expiry gives up", attempts: 4, expires: now.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		got, retry := retryAt(tt.attempts, now, tt.expires)
+		if retry != tt.wantRetry || !got.Equal(tt.want) {
+			t.Errorf("%s: retryAt = %v, %v, want %v, %v", tt.name, got, retry, tt.want, tt.wantRetry)
+		}
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	depart := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	r := Reminder{
+		DepartAt: depart,
+		Journey:  route_service.Journey{Summary: route_service.JourneySummary{TotalTimeMinutes: 45}},
+	}
+
+	tests := []struct {
+		kind string
+		want time.Time
+	}{
+		{KindReminder, depart},
+		{KindAlert, depart.Add(45 * time.Minute)},
+		{"other", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		if got := expiresAt(tt.kind, r); !got.Equal(tt.want) {
+			t.Errorf("expiresAt(%s) = %v, want %v", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestLeaseLeft(t *testing.T) {
+	claimed := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	lease := claimed.Add(claimLease)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{name: "just claimed", now: claimed, want: true},
+		{name: "exactly one send left", now: lease.Add(-sendTimeout), want: true},
+		{name: "less than one send left", now: lease.Add(-sendTimeout + time.Second), want: false},
+		{name: "lease expired", now: lease.Add(time.Second), want: false},
+	}
+
+	for _, tt := range tests {
+		if got := leaseLeft(lease, tt.now); got != tt.want {
+			t.Errorf("%s: leaseLeft = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReminderMessage(t *testing.T) {
+	depart := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	reminder := func(loc directions.Locale) Reminder {
+		return Reminder{ID: 7, DepartAt: depart, LeadMinutes: 10, Locale: loc}
+	}
+	en := directions.Locale{Lang: directions.English}
+	ar := directions.Locale{Lang: directions.Arabic}
+
+	tests := []struct {
+		name      string
+		loc       directions.Locale
+		now       time.Time
+		wantTitle string
+		wantBody  string
+	}{
+		{name: "on time", loc: en, now: depart.Add(-10 * time.Minute), wantTitle: "Time to leave", wantBody: "Leave in 10 min"},
+		{name: "a few seconds late", loc: en, now: depart.Add(-10*time.Minute + 5*time.Second), wantBody: "Leave in 10 min"},
+		{name: "sent late", loc: en, now: depart.Add(-4 * time.Minute), wantBody: "Leave in 4 min"},
+		{name: "set early", loc: en, now: depart.Add(-time.Hour), wantBody: "Leave in 10 min"},
+		{name: "last minute", loc: en, now: depart.Add(-30 * time.Second), wantBody: "Leave now"},
+		{name: "arabic", loc: ar, now: depart.Add(-2 * time.Minute), wantTitle: "حان وقت الخروج", wantBody: "اخرج خلال دقيقتان"},
+		{name: "arabic last minute", loc: ar, now: depart.Add(-30 * time.Second), wantBody: "اخرج الآن"},
+	}
+
+	for _, tt := range tests {
+		msg := reminderMessage(reminder(tt.loc), tt.now)
+		if !strings.HasPrefix(msg.Body, tt.wantBody+":") {
+			t.Errorf("%s: body = %q, want it to start with %q", tt.name, msg.Body, tt.wantBody)
+		}
+		if tt.wantTitle != "" && msg.Title != tt.wantTitle {
+			t.Errorf("%s: title = %q, want %q", tt.name, msg.Title, tt.wantTitle)
+		}
+	}
+}
+
+func TestAlertMessage(t *testing.T) {
+	trip := &route_service.TripLeg{RouteShortName: "M1"}
+	tests := []struct {
+		name      string
+		alert     route_service.Alert
+		loc       directions.Locale
+		wantTitle string
+		wantBody  string
+	}{
+		{
+			name:      "alert text in the reminder's language",
+			alert:     route_service.Alert{Header: map[string]string{"en": "Closed", "ar": "مغلق"}, Description: map[string]string{"en": "Station closed", "ar": "المحطة مغلقة"}},
+			loc:       directions.Locale{Lang: directions.Arabic},
+			wantTitle: "مغلق",
+			wantBody:  "المحطة مغلقة",
+		},
+		{
+			name:      "no description names the route",
+			alert:     route_service.Alert{Header: map[string]string{"en": "Delays"}},
+			loc:       directions.Locale{Lang: directions.English},
+			wantTitle: "Delays",
+			wantBody:  "This affects route M1 on your trip",
+		},
+	}
+
+	for _, tt := range tests {
+		msg := alertMessage(Reminder{ID: 7, Locale: tt.loc}, tt.alert, trip)
+		if msg.Title != tt.wantTitle || msg.Body != tt.wantBody {
+			t.Errorf("%s: message = %q / %q, want %q / %q", tt.name, msg.Title, msg.Body, tt.wantTitle, tt.wantBody)
+		}
+	}
+}
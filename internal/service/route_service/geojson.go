@@ -0,0 +1,103 @@
+package route_service
+
+// FeatureCollection is a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON feature
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON geometry, Coordinates is either a position or a list of positions
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// GeoJSON converts the journey into a feature collection with one
+// LineString per leg that has a path and one Point per boarding/alighting stop
+func (j Journey) GeoJSON() FeatureCollection {
+	features := make([]Feature, 0, len(j.Legs))
+
+	for i, leg := range j.Legs {
+		var path []Coordinate
+		var stops []Feature
+		props := map[string]any{
+			"leg_index": i,
+			"type":      leg.Type,
+		}
+
+		switch {
+		case leg.Walk != nil:
+			path = leg.Walk.Path
+			props["distance_meters"] = leg.Walk.DistanceMeters
+			props["duration_minutes"] = leg.Walk.DurationMinutes
+		case leg.Trip != nil:
+			path = leg.Trip.Path
+			props["trip_id"] = leg.Trip.TripID
+			props["mode"] = leg.Trip.Mode
+			props["route_short_name"] = leg.Trip.RouteShortName
+			props["headsign"] = leg.Trip.Headsign
+			props["duration_minutes"] = leg.Trip.DurationMinutes
+			stops = []Feature{stopFeature(leg.Trip.From, "board"), stopFeature(leg.Trip.To, "alight")}
+		case leg.Transfer != nil:
+			path = leg.Transfer.Path
+			props["distance_meters"] = leg.Transfer.WalkingDistanceMeters
+			props["duration_minutes"] = leg.Transfer.DurationMinutes
+		case leg.Ride != nil:
+			path = leg.Ride.Path
+			if len(path) < 2 {
+				path = []Coordinate{leg.Ride.From, leg.Ride.To}
+			}
+			props["mode"] = leg.Ride.Mode
+			props["distance_meters"] = leg.Ride.DistanceMeters
+			props["duration_minutes"] = leg.Ride.DurationMinutes
+			props["cost"] = leg.Ride.Cost
+		}
+
+		if len(path) >= 2 {
+			features = append(features, Feature{
+				Type: "Feature",
+				Geometry: Geometry{
+					Type:        "LineString",
+					Coordinates: positions(path),
+				},
+				Properties: props,
+			})
+		}
+		features = append(features, stops...)
+	}
+
+	return FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: features,
+	}
+}
+
+func stopFeature(s Stop, role string) Feature {
+	return Feature{
+		Type: "Feature",
+		Geometry: Geometry{
+			Type:        "Point",
+			Coordinates: [2]float64{s.Coord.Lon, s.Coord.Lat},
+		},
+		Properties: map[string]any{
+			"stop_id": s.StopID,
+			"name":    s.Name,
+			"role":    role,
+		},
+	}
+}
+
+func positions(path []Coordinate) [][2]float64 {
+	result := make([][2]float64, len(path))
+	for i, c := range path {
+		result[i] = [2]float64{c.Lon, c.Lat}
+	}
+	return result
+}
@@ -21,6 +21,121 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Request to load a network version, snapshot_url serves it as JSON when set.
+// The router sends snapshot_token in the X-Snapshot-Token header when fetching it
+type ReloadNetworkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	SnapshotUrl   string                 `protobuf:"bytes,2,opt,name=snapshot_url,json=snapshotUrl,proto3" json:"snapshot_url,omitempty"`
+	SnapshotToken string                 `protobuf:"bytes,3,opt,name=snapshot_token,json=snapshotToken,proto3" json:"snapshot_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadNetworkRequest) Reset() {
+	*x = ReloadNetworkRequest{}
+	mi := &file_routing_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadNetworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadNetworkRequest) ProtoMessage() {}
+
+func (x *ReloadNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_routing_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadNetworkRequest.ProtoReflect.Descriptor instead.
+func (*ReloadNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_routing_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReloadNetworkRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ReloadNetworkRequest) GetSnapshotUrl() string {
+	if x != nil {
+		return x.SnapshotUrl
+	}
+	return ""
+}
+
+func (x *ReloadNetworkRequest) GetSnapshotToken() string {
+	if x != nil {
+		return x.SnapshotToken
+	}
+	return ""
+}
+
+// Network version the router serves after the reload
+type ReloadNetworkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadNetworkResponse) Reset() {
+	*x = ReloadNetworkResponse{}
+	mi := &file_routing_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadNetworkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadNetworkResponse) ProtoMessage() {}
+
+func (x *ReloadNetworkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_routing_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadNetworkResponse.ProtoReflect.Descriptor instead.
+func (*ReloadNetworkResponse) Descriptor() ([]byte, []int) {
+	return file_routing_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ReloadNetworkResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ReloadNetworkResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 // Health check request (empty)
 type HealthRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -30,7 +145,7 @@ type HealthRequest struct {
 
 func (x *HealthRequest) Reset() {
 	*x = HealthRequest{}
-	mi := &file_routing_proto_msgTypes[0]
+	mi := &file_routing_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -42,7 +157,7 @@ func (x *HealthRequest) String() string {
 func (*HealthRequest) ProtoMessage() {}
 
 func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[0]
+	mi := &file_routing_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -55,7 +170,7 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
 func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{0}
+	return file_routing_proto_rawDescGZIP(), []int{2}
 }
 
 // Health check response
@@ -69,7 +184,7 @@ type HealthResponse struct {
 
 func (x *HealthResponse) Reset() {
 	*x = HealthResponse{}
-	mi := &file_routing_proto_msgTypes[1]
+	mi := &file_routing_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -81,7 +196,7 @@ func (x *HealthResponse) String() string {
 func (*HealthResponse) ProtoMessage() {}
 
 func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[1]
+	mi := &file_routing_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -94,7 +209,7 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
 func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{1}
+	return file_routing_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *HealthResponse) GetStatus() string {
@@ -123,13 +238,19 @@ type RouteRequest struct {
 	RestrictedModes []string               `protobuf:"bytes,7,rep,name=restricted_modes,json=restrictedModes,proto3" json:"restricted_modes,omitempty"`
 	Weights         *RoutingWeights        `protobuf:"bytes,8,opt,name=weights,proto3" json:"weights,omitempty"`
 	TopK            int32                  `protobuf:"varint,9,opt,name=top_k,json=topK,proto3" json:"top_k,omitempty"`
+	RiderCategory   string                 `protobuf:"bytes,10,opt,name=rider_category,json=riderCategory,proto3" json:"rider_category,omitempty"`
+	FareProducts    []*FareProduct         `protobuf:"bytes,11,rep,name=fare_products,json=fareProducts,proto3" json:"fare_products,omitempty"`
+	Accessibility   *AccessibilityOptions  `protobuf:"bytes,12,opt,name=accessibility,proto3" json:"accessibility,omitempty"`
+	Via             []*ViaPoint            `protobuf:"bytes,13,rep,name=via,proto3" json:"via,omitempty"`
+	Avoid           *AvoidOptions          `protobuf:"bytes,14,opt,name=avoid,proto3" json:"avoid,omitempty"`
+	AccessModes     []string               `protobuf:"bytes,15,rep,name=access_modes,json=accessModes,proto3" json:"access_modes,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
 
 func (x *RouteRequest) Reset() {
 	*x = RouteRequest{}
-	mi := &file_routing_proto_msgTypes[2]
+	mi := &file_routing_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -141,7 +262,7 @@ func (x *RouteRequest) String() string {
 func (*RouteRequest) ProtoMessage() {}
 
 func (x *RouteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[2]
+	mi := &file_routing_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -154,7 +275,7 @@ func (x *RouteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RouteRequest.ProtoReflect.Descriptor instead.
 func (*RouteRequest) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{2}
+	return file_routing_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *RouteRequest) GetStartLon() float64 {
@@ -220,6 +341,344 @@ func (x *RouteRequest) GetTopK() int32 {
 	return 0
 }
 
+func (x *RouteRequest) GetRiderCategory() string {
+	if x != nil {
+		return x.RiderCategory
+	}
+	return ""
+}
+
+func (x *RouteRequest) GetFareProducts() []*FareProduct {
+	if x != nil {
+		return x.FareProducts
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetAccessibility() *AccessibilityOptions {
+	if x != nil {
+		return x.Accessibility
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetVia() []*ViaPoint {
+	if x != nil {
+		return x.Via
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetAvoid() *AvoidOptions {
+	if x != nil {
+		return x.Avoid
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetAccessModes() []string {
+	if x != nil {
+		return x.AccessModes
+	}
+	return nil
+}
+
+// An intermediate point to pass through, in request order
+type ViaPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Coord         *Coordinate            `protobuf:"bytes,1,opt,name=coord,proto3" json:"coord,omitempty"`
+	DwellMinutes  int32                  `protobuf:"varint,2,opt,name=dwell_minutes,json=dwellMinutes,proto3" json:"dwell_minutes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ViaPoint) Reset() {
+	*x = ViaPoint{}
+	mi := &file_routing_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ViaPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ViaPoint) ProtoMessage() {}
+
+func (x *ViaPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_routing_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ViaPoint.ProtoReflect.Descriptor instead.
+func (*ViaPoint) Descriptor() ([]byte, []int) {
+	return file_routing_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ViaPoint) GetCoord() *Coordinate {
+	if x != nil {
+		return x.Coord
+	}
+	return nil
+}
+
+func (x *ViaPoint) GetDwellMinutes() int32 {
+	if x != nil {
+		return x.DwellMinutes
+	}
+	return 0
+}
+
+// Areas, stops and routes journeys must stay clear of
+type AvoidOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Areas         []*Polygon             `protobuf:"bytes,1,rep,name=areas,proto3" json:"areas,omitempty"`
+	StopIds       []int32                `protobuf:"varint,2,rep,packed,name=stop_ids,json=stopIds,proto3" json:"stop_ids,omitempty"`
+	Routes        []string               `protobuf:"bytes,3,rep,name=routes,proto3" json:"routes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AvoidOptions) Reset() {
+	*x = AvoidOptions{}
+	mi := &file_routing_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AvoidOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AvoidOptions) ProtoMessage() {}
+
+func (x *AvoidOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_routing_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AvoidOptions.ProtoReflect.Descriptor instead.
+func (*AvoidOptions) Descriptor() ([]byte, []int) {
+	return file_routing_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AvoidOptions) GetAreas() []*Polygon {
+	if x != nil {
+		return x.Areas
+	}
+	return nil
+}
+
+func (x *AvoidOptions) GetStopIds() []int32 {
+	if x != nil {
+		return x.StopIds
+	}
+	return nil
+}
+
+func (x *AvoidOptions) GetRoutes() []string {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+type Polygon struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Points        []*Coordinate          `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Polygon) Reset() {
+	*x = Polygon{}
+	mi := &file_routing_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Polygon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Polygon) ProtoMessage() {}
+
+func (x *Polygon) ProtoReflect() protoreflect.Message {
+	mi := &file_routing_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Polygon.ProtoReflect.Descriptor instead.
+func (*Polygon) Descriptor() ([]byte, []int) {
+	return file_routing_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Polygon) GetPoints() []*Coordinate {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+// Restrictions for riders with reduced mobility
+type AccessibilityOptions struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	StepFreeOnly           bool                   `protobuf:"varint,1,opt,name=step_free_only,json=stepFreeOnly,proto3" json:"step_free_only,omitempty"`
+	MaxWalkMeters          float64                `protobuf:"fixed64,2,opt,name=max_walk_meters,json=maxWalkMeters,proto3" json:"max_walk_meters,omitempty"`
+	AvoidStairs            bool                   `protobuf:"varint,3,opt,name=avoid_stairs,json=avoidStairs,proto3" json:"avoid_stairs,omitempty"`
+	AvoidOverpasses        bool                   `protobuf:"varint,4,opt,name=avoid_overpasses,json=avoidOverpasses,proto3" json:"avoid_overpasses,omitempty"`
+	AccessibleVehiclesOnly bool                   `protobuf:"varint,5,opt,name=accessible_vehicles_only,json=accessibleVehiclesOnly,proto3" json:"accessible_vehicles_only,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *AccessibilityOptions) Reset() {
+	*x = AccessibilityOptions{}
+	mi := &file_routing_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccessibilityOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessibilityOptions) ProtoMessage() {}
+
+func (x *AccessibilityOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_routing_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessibilityOptions.ProtoReflect.Descriptor instead.
+func (*AccessibilityOptions) Descriptor() ([]byte, []int) {
+	return file_routing_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AccessibilityOptions) GetStepFreeOnly() bool {
+	if x != nil {
+		return x.StepFreeOnly
+	}
+	return false
+}
+
+func (x *AccessibilityOptions) GetMaxWalkMeters() float64 {
+	if x != nil {
+		return x.MaxWalkMeters
+	}
+	return 0
+}
+
+func (x *AccessibilityOptions) GetAvoidStairs() bool {
+	if x != nil {
+		return x.AvoidStairs
+	}
+	return false
+}
+
+func (x *AccessibilityOptions) GetAvoidOverpasses() bool {
+	if x != nil {
+		return x.AvoidOverpasses
+	}
+	return false
+}
+
+func (x *AccessibilityOptions) GetAccessibleVehiclesOnly() bool {
+	if x != nil {
+		return x.AccessibleVehiclesOnly
+	}
+	return false
+}
+
+// A pass the rider owns, line passes name a route, mode passes a mode
+type FareProduct struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Route         string                 `protobuf:"bytes,2,opt,name=route,proto3" json:"route,omitempty"`
+	Mode          string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FareProduct) Reset() {
+	*x = FareProduct{}
+	mi := &file_routing_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareProduct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareProduct) ProtoMessage() {}
+
+func (x *FareProduct) ProtoReflect() protoreflect.Message {
+	mi := &file_routing_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareProduct.ProtoReflect.Descriptor instead.
+func (*FareProduct) Descriptor() ([]byte, []int) {
+	return file_routing_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FareProduct) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *FareProduct) GetRoute() string {
+	if x != nil {
+		return x.Route
+	}
+	return ""
+}
+
+func (x *FareProduct) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
 // Routing weights for journey ranking
 type RoutingWeights struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -227,13 +686,14 @@ type RoutingWeights struct {
 	Cost          float64                `protobuf:"fixed64,2,opt,name=cost,proto3" json:"cost,omitempty"`
 	Walk          float64                `protobuf:"fixed64,3,opt,name=walk,proto3" json:"walk,omitempty"`
 	Transfer      float64                `protobuf:"fixed64,4,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	Co2           float64                `protobuf:"fixed64,5,opt,name=co2,proto3" json:"co2,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RoutingWeights) Reset() {
 	*x = RoutingWeights{}
-	mi := &file_routing_proto_msgTypes[3]
+	mi := &file_routing_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -245,7 +705,7 @@ func (x *RoutingWeights) String() string {
 func (*RoutingWeights) ProtoMessage() {}
 
 func (x *RoutingWeights) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[3]
+	mi := &file_routing_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -258,7 +718,7 @@ func (x *RoutingWeights) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RoutingWeights.ProtoReflect.Descriptor instead.
 func (*RoutingWeights) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{3}
+	return file_routing_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *RoutingWeights) GetTime() float64 {
@@ -289,6 +749,13 @@ func (x *RoutingWeights) GetTransfer() float64 {
 	return 0
 }
 
+func (x *RoutingWeights) GetCo2() float64 {
+	if x != nil {
+		return x.Co2
+	}
+	return 0
+}
+
 // Response containing all found journeys
 type RouteResponse struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
@@ -298,13 +765,14 @@ type RouteResponse struct {
 	EndTripsFound    int32                  `protobuf:"varint,4,opt,name=end_trips_found,json=endTripsFound,proto3" json:"end_trips_found,omitempty"`
 	TotalRoutesFound int32                  `protobuf:"varint,5,opt,name=total_routes_found,json=totalRoutesFound,proto3" json:"total_routes_found,omitempty"`
 	Error            string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	NetworkVersion   int64                  `protobuf:"varint,7,opt,name=network_version,json=networkVersion,proto3" json:"network_version,omitempty"`
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
 func (x *RouteResponse) Reset() {
 	*x = RouteResponse{}
-	mi := &file_routing_proto_msgTypes[4]
+	mi := &file_routing_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -316,7 +784,7 @@ func (x *RouteResponse) String() string {
 func (*RouteResponse) ProtoMessage() {}
 
 func (x *RouteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[4]
+	mi := &file_routing_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -329,7 +797,7 @@ func (x *RouteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RouteResponse.ProtoReflect.Descriptor instead.
 func (*RouteResponse) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{4}
+	return file_routing_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *RouteResponse) GetNumJourneys() int32 {
@@ -374,6 +842,13 @@ func (x *RouteResponse) GetError() string {
 	return ""
 }
 
+func (x *RouteResponse) GetNetworkVersion() int64 {
+	if x != nil {
+		return x.NetworkVersion
+	}
+	return 0
+}
+
 // A single journey option
 type Journey struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -387,7 +862,7 @@ type Journey struct {
 
 func (x *Journey) Reset() {
 	*x = Journey{}
-	mi := &file_routing_proto_msgTypes[5]
+	mi := &file_routing_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -399,7 +874,7 @@ func (x *Journey) String() string {
 func (*Journey) ProtoMessage() {}
 
 func (x *Journey) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[5]
+	mi := &file_routing_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -412,7 +887,7 @@ func (x *Journey) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Journey.ProtoReflect.Descriptor instead.
 func (*Journey) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{5}
+	return file_routing_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Journey) GetId() int32 {
@@ -458,7 +933,7 @@ type JourneySummary struct {
 
 func (x *JourneySummary) Reset() {
 	*x = JourneySummary{}
-	mi := &file_routing_proto_msgTypes[6]
+	mi := &file_routing_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -470,7 +945,7 @@ func (x *JourneySummary) String() string {
 func (*JourneySummary) ProtoMessage() {}
 
 func (x *JourneySummary) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[6]
+	mi := &file_routing_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -483,7 +958,7 @@ func (x *JourneySummary) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use JourneySummary.ProtoReflect.Descriptor instead.
 func (*JourneySummary) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{6}
+	return file_routing_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *JourneySummary) GetTotalTimeMinutes() int32 {
@@ -536,6 +1011,10 @@ type Leg struct {
 	//	*Leg_Walk
 	//	*Leg_Trip
 	//	*Leg_Transfer
+	//	*Leg_Bike
+	//	*Leg_Scooter
+	//	*Leg_Taxi
+	//	*Leg_RideHail
 	LegType       isLeg_LegType `protobuf_oneof:"leg_type"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -543,7 +1022,7 @@ type Leg struct {
 
 func (x *Leg) Reset() {
 	*x = Leg{}
-	mi := &file_routing_proto_msgTypes[7]
+	mi := &file_routing_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -555,7 +1034,7 @@ func (x *Leg) String() string {
 func (*Leg) ProtoMessage() {}
 
 func (x *Leg) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[7]
+	mi := &file_routing_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -568,7 +1047,7 @@ func (x *Leg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Leg.ProtoReflect.Descriptor instead.
 func (*Leg) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{7}
+	return file_routing_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *Leg) GetLegType() isLeg_LegType {
@@ -605,6 +1084,42 @@ func (x *Leg) GetTransfer() *TransferLeg {
 	return nil
 }
 
+func (x *Leg) GetBike() *RideLeg {
+	if x != nil {
+		if x, ok := x.LegType.(*Leg_Bike); ok {
+			return x.Bike
+		}
+	}
+	return nil
+}
+
+func (x *Leg) GetScooter() *RideLeg {
+	if x != nil {
+		if x, ok := x.LegType.(*Leg_Scooter); ok {
+			return x.Scooter
+		}
+	}
+	return nil
+}
+
+func (x *Leg) GetTaxi() *RideLeg {
+	if x != nil {
+		if x, ok := x.LegType.(*Leg_Taxi); ok {
+			return x.Taxi
+		}
+	}
+	return nil
+}
+
+func (x *Leg) GetRideHail() *RideLeg {
+	if x != nil {
+		if x, ok := x.LegType.(*Leg_RideHail); ok {
+			return x.RideHail
+		}
+	}
+	return nil
+}
+
 type isLeg_LegType interface {
 	isLeg_LegType()
 }
@@ -621,12 +1136,129 @@ type Leg_Transfer struct {
 	Transfer *TransferLeg `protobuf:"bytes,3,opt,name=transfer,proto3,oneof"`
 }
 
+type Leg_Bike struct {
+	Bike *RideLeg `protobuf:"bytes,4,opt,name=bike,proto3,oneof"`
+}
+
+type Leg_Scooter struct {
+	Scooter *RideLeg `protobuf:"bytes,5,opt,name=scooter,proto3,oneof"`
+}
+
+type Leg_Taxi struct {
+	Taxi *RideLeg `protobuf:"bytes,6,opt,name=taxi,proto3,oneof"`
+}
+
+type Leg_RideHail struct {
+	RideHail *RideLeg `protobuf:"bytes,7,opt,name=ride_hail,json=rideHail,proto3,oneof"`
+}
+
 func (*Leg_Walk) isLeg_LegType() {}
 
 func (*Leg_Trip) isLeg_LegType() {}
 
 func (*Leg_Transfer) isLeg_LegType() {}
 
+func (*Leg_Bike) isLeg_LegType() {}
+
+func (*Leg_Scooter) isLeg_LegType() {}
+
+func (*Leg_Taxi) isLeg_LegType() {}
+
+func (*Leg_RideHail) isLeg_LegType() {}
+
+// Bike, scooter, taxi or ride-hail leg
+type RideLeg struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Provider        string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	From            *Coordinate            `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To              *Coordinate            `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	DistanceMeters  int32                  `protobuf:"varint,4,opt,name=distance_meters,json=distanceMeters,proto3" json:"distance_meters,omitempty"`
+	DurationMinutes int32                  `protobuf:"varint,5,opt,name=duration_minutes,json=durationMinutes,proto3" json:"duration_minutes,omitempty"`
+	Cost            float64                `protobuf:"fixed64,6,opt,name=cost,proto3" json:"cost,omitempty"`
+	Path            []*Coordinate          `protobuf:"bytes,7,rep,name=path,proto3" json:"path,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RideLeg) Reset() {
+	*x = RideLeg{}
+	mi := &file_routing_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RideLeg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RideLeg) ProtoMessage() {}
+
+func (x *RideLeg) ProtoReflect() protoreflect.Message {
+	mi := &file_routing_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RideLeg.ProtoReflect.Descriptor instead.
+func (*RideLeg) Descriptor() ([]byte, []int) {
+	return file_routing_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RideLeg) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *RideLeg) GetFrom() *Coordinate {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *RideLeg) GetTo() *Coordinate {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *RideLeg) GetDistanceMeters() int32 {
+	if x != nil {
+		return x.DistanceMeters
+	}
+	return 0
+}
+
+func (x *RideLeg) GetDurationMinutes() int32 {
+	if x != nil {
+		return x.DurationMinutes
+	}
+	return 0
+}
+
+func (x *RideLeg) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *RideLeg) GetPath() []*Coordinate {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
 // Walking leg
 type WalkLeg struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
@@ -639,7 +1271,7 @@ type WalkLeg struct {
 
 func (x *WalkLeg) Reset() {
 	*x = WalkLeg{}
-	mi := &file_routing_proto_msgTypes[8]
+	mi := &file_routing_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -651,7 +1283,7 @@ func (x *WalkLeg) String() string {
 func (*WalkLeg) ProtoMessage() {}
 
 func (x *WalkLeg) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[8]
+	mi := &file_routing_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -664,7 +1296,7 @@ func (x *WalkLeg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WalkLeg.ProtoReflect.Descriptor instead.
 func (*WalkLeg) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{8}
+	return file_routing_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *WalkLeg) GetDistanceMeters() int32 {
@@ -700,13 +1332,15 @@ type TripLeg struct {
 	From            *Stop                  `protobuf:"bytes,7,opt,name=from,proto3" json:"from,omitempty"`
 	To              *Stop                  `protobuf:"bytes,8,opt,name=to,proto3" json:"to,omitempty"`
 	Path            []*Coordinate          `protobuf:"bytes,9,rep,name=path,proto3" json:"path,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// GTFS route_id of the trip's route, alerts are matched on it
+	RouteId       string `protobuf:"bytes,10,opt,name=route_id,json=routeId,proto3" json:"route_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TripLeg) Reset() {
 	*x = TripLeg{}
-	mi := &file_routing_proto_msgTypes[9]
+	mi := &file_routing_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -718,7 +1352,7 @@ func (x *TripLeg) String() string {
 func (*TripLeg) ProtoMessage() {}
 
 func (x *TripLeg) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[9]
+	mi := &file_routing_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -731,7 +1365,7 @@ func (x *TripLeg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TripLeg.ProtoReflect.Descriptor instead.
 func (*TripLeg) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{9}
+	return file_routing_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *TripLeg) GetTripId() string {
@@ -797,6 +1431,13 @@ func (x *TripLeg) GetPath() []*Coordinate {
 	return nil
 }
 
+func (x *TripLeg) GetRouteId() string {
+	if x != nil {
+		return x.RouteId
+	}
+	return ""
+}
+
 // Transfer between trips
 type TransferLeg struct {
 	state                 protoimpl.MessageState `protogen:"open.v1"`
@@ -813,7 +1454,7 @@ type TransferLeg struct {
 
 func (x *TransferLeg) Reset() {
 	*x = TransferLeg{}
-	mi := &file_routing_proto_msgTypes[10]
+	mi := &file_routing_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -825,7 +1466,7 @@ func (x *TransferLeg) String() string {
 func (*TransferLeg) ProtoMessage() {}
 
 func (x *TransferLeg) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[10]
+	mi := &file_routing_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -838,7 +1479,7 @@ func (x *TransferLeg) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TransferLeg.ProtoReflect.Descriptor instead.
 func (*TransferLeg) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{10}
+	return file_routing_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *TransferLeg) GetFromTripId() string {
@@ -902,7 +1543,7 @@ type Stop struct {
 
 func (x *Stop) Reset() {
 	*x = Stop{}
-	mi := &file_routing_proto_msgTypes[11]
+	mi := &file_routing_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -914,7 +1555,7 @@ func (x *Stop) String() string {
 func (*Stop) ProtoMessage() {}
 
 func (x *Stop) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[11]
+	mi := &file_routing_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -927,7 +1568,7 @@ func (x *Stop) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Stop.ProtoReflect.Descriptor instead.
 func (*Stop) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{11}
+	return file_routing_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *Stop) GetStopId() int32 {
@@ -962,7 +1603,7 @@ type Coordinate struct {
 
 func (x *Coordinate) Reset() {
 	*x = Coordinate{}
-	mi := &file_routing_proto_msgTypes[12]
+	mi := &file_routing_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -974,7 +1615,7 @@ func (x *Coordinate) String() string {
 func (*Coordinate) ProtoMessage() {}
 
 func (x *Coordinate) ProtoReflect() protoreflect.Message {
-	mi := &file_routing_proto_msgTypes[12]
+	mi := &file_routing_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -987,7 +1628,7 @@ func (x *Coordinate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Coordinate.ProtoReflect.Descriptor instead.
 func (*Coordinate) Descriptor() ([]byte, []int) {
-	return file_routing_proto_rawDescGZIP(), []int{12}
+	return file_routing_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *Coordinate) GetLon() float64 {
@@ -1008,11 +1649,18 @@ var File_routing_proto protoreflect.FileDescriptor
 
 const file_routing_proto_rawDesc = "" +
 	"\n" +
-	"\rrouting.proto\x12\arouting\"\x0f\n" +
+	"\rrouting.proto\x12\arouting\"z\n" +
+	"\x14ReloadNetworkRequest\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12!\n" +
+	"\fsnapshot_url\x18\x02 \x01(\tR\vsnapshotUrl\x12%\n" +
+	"\x0esnapshot_token\x18\x03 \x01(\tR\rsnapshotToken\"K\n" +
+	"\x15ReloadNetworkResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x0f\n" +
 	"\rHealthRequest\"B\n" +
 	"\x0eHealthResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\xb9\x02\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xd5\x04\n" +
 	"\fRouteRequest\x12\x1b\n" +
 	"\tstart_lon\x18\x01 \x01(\x01R\bstartLon\x12\x1b\n" +
 	"\tstart_lat\x18\x02 \x01(\x01R\bstartLat\x12\x17\n" +
@@ -1022,19 +1670,47 @@ const file_routing_proto_rawDesc = "" +
 	"\x0ewalking_cutoff\x18\x06 \x01(\x01R\rwalkingCutoff\x12)\n" +
 	"\x10restricted_modes\x18\a \x03(\tR\x0frestrictedModes\x121\n" +
 	"\aweights\x18\b \x01(\v2\x17.routing.RoutingWeightsR\aweights\x12\x13\n" +
-	"\x05top_k\x18\t \x01(\x05R\x04topK\"h\n" +
+	"\x05top_k\x18\t \x01(\x05R\x04topK\x12%\n" +
+	"\x0erider_category\x18\n" +
+	" \x01(\tR\rriderCategory\x129\n" +
+	"\rfare_products\x18\v \x03(\v2\x14.routing.FareProductR\ffareProducts\x12C\n" +
+	"\raccessibility\x18\f \x01(\v2\x1d.routing.AccessibilityOptionsR\raccessibility\x12#\n" +
+	"\x03via\x18\r \x03(\v2\x11.routing.ViaPointR\x03via\x12+\n" +
+	"\x05avoid\x18\x0e \x01(\v2\x15.routing.AvoidOptionsR\x05avoid\x12!\n" +
+	"\faccess_modes\x18\x0f \x03(\tR\vaccessModes\"Z\n" +
+	"\bViaPoint\x12)\n" +
+	"\x05coord\x18\x01 \x01(\v2\x13.routing.CoordinateR\x05coord\x12#\n" +
+	"\rdwell_minutes\x18\x02 \x01(\x05R\fdwellMinutes\"i\n" +
+	"\fAvoidOptions\x12&\n" +
+	"\x05areas\x18\x01 \x03(\v2\x10.routing.PolygonR\x05areas\x12\x19\n" +
+	"\bstop_ids\x18\x02 \x03(\x05R\astopIds\x12\x16\n" +
+	"\x06routes\x18\x03 \x03(\tR\x06routes\"6\n" +
+	"\aPolygon\x12+\n" +
+	"\x06points\x18\x01 \x03(\v2\x13.routing.CoordinateR\x06points\"\xec\x01\n" +
+	"\x14AccessibilityOptions\x12$\n" +
+	"\x0estep_free_only\x18\x01 \x01(\bR\fstepFreeOnly\x12&\n" +
+	"\x0fmax_walk_meters\x18\x02 \x01(\x01R\rmaxWalkMeters\x12!\n" +
+	"\favoid_stairs\x18\x03 \x01(\bR\vavoidStairs\x12)\n" +
+	"\x10avoid_overpasses\x18\x04 \x01(\bR\x0favoidOverpasses\x128\n" +
+	"\x18accessible_vehicles_only\x18\x05 \x01(\bR\x16accessibleVehiclesOnly\"K\n" +
+	"\vFareProduct\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05route\x18\x02 \x01(\tR\x05route\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\"z\n" +
 	"\x0eRoutingWeights\x12\x12\n" +
 	"\x04time\x18\x01 \x01(\x01R\x04time\x12\x12\n" +
 	"\x04cost\x18\x02 \x01(\x01R\x04cost\x12\x12\n" +
 	"\x04walk\x18\x03 \x01(\x01R\x04walk\x12\x1a\n" +
-	"\btransfer\x18\x04 \x01(\x01R\btransfer\"\xf8\x01\n" +
+	"\btransfer\x18\x04 \x01(\x01R\btransfer\x12\x10\n" +
+	"\x03co2\x18\x05 \x01(\x01R\x03co2\"\xa1\x02\n" +
 	"\rRouteResponse\x12!\n" +
 	"\fnum_journeys\x18\x01 \x01(\x05R\vnumJourneys\x12,\n" +
 	"\bjourneys\x18\x02 \x03(\v2\x10.routing.JourneyR\bjourneys\x12*\n" +
 	"\x11start_trips_found\x18\x03 \x01(\x05R\x0fstartTripsFound\x12&\n" +
 	"\x0fend_trips_found\x18\x04 \x01(\x05R\rendTripsFound\x12,\n" +
 	"\x12total_routes_found\x18\x05 \x01(\x05R\x10totalRoutesFound\x12\x14\n" +
-	"\x05error\x18\x06 \x01(\tR\x05error\"\x91\x01\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12'\n" +
+	"\x0fnetwork_version\x18\a \x01(\x03R\x0enetworkVersion\"\x91\x01\n" +
 	"\aJourney\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x05R\x02id\x12!\n" +
 	"\ftext_summary\x18\x02 \x01(\tR\vtextSummary\x121\n" +
@@ -1046,17 +1722,29 @@ const file_routing_proto_rawDesc = "" +
 	"\x17walking_distance_meters\x18\x03 \x01(\x05R\x15walkingDistanceMeters\x12\x1c\n" +
 	"\ttransfers\x18\x04 \x01(\x05R\ttransfers\x12\x12\n" +
 	"\x04cost\x18\x05 \x01(\x01R\x04cost\x12\x14\n" +
-	"\x05modes\x18\x06 \x03(\tR\x05modes\"\x95\x01\n" +
+	"\x05modes\x18\x06 \x03(\tR\x05modes\"\xc4\x02\n" +
 	"\x03Leg\x12&\n" +
 	"\x04walk\x18\x01 \x01(\v2\x10.routing.WalkLegH\x00R\x04walk\x12&\n" +
 	"\x04trip\x18\x02 \x01(\v2\x10.routing.TripLegH\x00R\x04trip\x122\n" +
-	"\btransfer\x18\x03 \x01(\v2\x14.routing.TransferLegH\x00R\btransferB\n" +
+	"\btransfer\x18\x03 \x01(\v2\x14.routing.TransferLegH\x00R\btransfer\x12&\n" +
+	"\x04bike\x18\x04 \x01(\v2\x10.routing.RideLegH\x00R\x04bike\x12,\n" +
+	"\ascooter\x18\x05 \x01(\v2\x10.routing.RideLegH\x00R\ascooter\x12&\n" +
+	"\x04taxi\x18\x06 \x01(\v2\x10.routing.RideLegH\x00R\x04taxi\x12/\n" +
+	"\tride_hail\x18\a \x01(\v2\x10.routing.RideLegH\x00R\brideHailB\n" +
 	"\n" +
-	"\bleg_type\"\x86\x01\n" +
+	"\bleg_type\"\x84\x02\n" +
+	"\aRideLeg\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12'\n" +
+	"\x04from\x18\x02 \x01(\v2\x13.routing.CoordinateR\x04from\x12#\n" +
+	"\x02to\x18\x03 \x01(\v2\x13.routing.CoordinateR\x02to\x12'\n" +
+	"\x0fdistance_meters\x18\x04 \x01(\x05R\x0edistanceMeters\x12)\n" +
+	"\x10duration_minutes\x18\x05 \x01(\x05R\x0fdurationMinutes\x12\x12\n" +
+	"\x04cost\x18\x06 \x01(\x01R\x04cost\x12'\n" +
+	"\x04path\x18\a \x03(\v2\x13.routing.CoordinateR\x04path\"\x86\x01\n" +
 	"\aWalkLeg\x12'\n" +
 	"\x0fdistance_meters\x18\x01 \x01(\x05R\x0edistanceMeters\x12)\n" +
 	"\x10duration_minutes\x18\x02 \x01(\x05R\x0fdurationMinutes\x12'\n" +
-	"\x04path\x18\x03 \x03(\v2\x13.routing.CoordinateR\x04path\"\xa6\x02\n" +
+	"\x04path\x18\x03 \x03(\v2\x13.routing.CoordinateR\x04path\"\xc1\x02\n" +
 	"\aTripLeg\x12\x17\n" +
 	"\atrip_id\x18\x01 \x01(\tR\x06tripId\x12\x12\n" +
 	"\x04mode\x18\x02 \x01(\tR\x04mode\x12(\n" +
@@ -1066,7 +1754,9 @@ const file_routing_proto_rawDesc = "" +
 	"\x10duration_minutes\x18\x06 \x01(\x05R\x0fdurationMinutes\x12!\n" +
 	"\x04from\x18\a \x01(\v2\r.routing.StopR\x04from\x12\x1d\n" +
 	"\x02to\x18\b \x01(\v2\r.routing.StopR\x02to\x12'\n" +
-	"\x04path\x18\t \x03(\v2\x13.routing.CoordinateR\x04path\"\xa1\x02\n" +
+	"\x04path\x18\t \x03(\v2\x13.routing.CoordinateR\x04path\x12\x19\n" +
+	"\broute_id\x18\n" +
+	" \x01(\tR\arouteId\"\xa1\x02\n" +
 	"\vTransferLeg\x12 \n" +
 	"\ffrom_trip_id\x18\x01 \x01(\tR\n" +
 	"fromTripId\x12\x1c\n" +
@@ -1085,10 +1775,11 @@ const file_routing_proto_rawDesc = "" +
 	"\n" +
 	"Coordinate\x12\x10\n" +
 	"\x03lon\x18\x01 \x01(\x01R\x03lon\x12\x10\n" +
-	"\x03lat\x18\x02 \x01(\x01R\x03lat2\x90\x01\n" +
+	"\x03lat\x18\x02 \x01(\x01R\x03lat2\xe2\x01\n" +
 	"\x0eRoutingService\x12@\n" +
 	"\vHealthCheck\x12\x16.routing.HealthRequest\x1a\x17.routing.HealthResponse\"\x00\x12<\n" +
-	"\tFindRoute\x12\x15.routing.RouteRequest\x1a\x16.routing.RouteResponse\"\x00BQZOgithub.com/Marwan051/final_project_backend/internal/service/route_service/protob\x06proto3"
+	"\tFindRoute\x12\x15.routing.RouteRequest\x1a\x16.routing.RouteResponse\"\x00\x12P\n" +
+	"\rReloadNetwork\x12\x1d.routing.ReloadNetworkRequest\x1a\x1e.routing.ReloadNetworkResponse\"\x00BQZOgithub.com/Marwan051/final_project_backend/internal/service/route_service/protob\x06proto3"
 
 var (
 	file_routing_proto_rawDescOnce sync.Once
@@ -1102,45 +1793,69 @@ func file_routing_proto_rawDescGZIP() []byte {
 	return file_routing_proto_rawDescData
 }
 
-var file_routing_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_routing_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_routing_proto_goTypes = []any{
-	(*HealthRequest)(nil),  // 0: routing.HealthRequest
-	(*HealthResponse)(nil), // 1: routing.HealthResponse
-	(*RouteRequest)(nil),   // 2: routing.RouteRequest
-	(*RoutingWeights)(nil), // 3: routing.RoutingWeights
-	(*RouteResponse)(nil),  // 4: routing.RouteResponse
-	(*Journey)(nil),        // 5: routing.Journey
-	(*JourneySummary)(nil), // 6: routing.JourneySummary
-	(*Leg)(nil),            // 7: routing.Leg
-	(*WalkLeg)(nil),        // 8: routing.WalkLeg
-	(*TripLeg)(nil),        // 9: routing.TripLeg
-	(*TransferLeg)(nil),    // 10: routing.TransferLeg
-	(*Stop)(nil),           // 11: routing.Stop
-	(*Coordinate)(nil),     // 12: routing.Coordinate
+	(*ReloadNetworkRequest)(nil),  // 0: routing.ReloadNetworkRequest
+	(*ReloadNetworkResponse)(nil), // 1: routing.ReloadNetworkResponse
+	(*HealthRequest)(nil),         // 2: routing.HealthRequest
+	(*HealthResponse)(nil),        // 3: routing.HealthResponse
+	(*RouteRequest)(nil),          // 4: routing.RouteRequest
+	(*ViaPoint)(nil),              // 5: routing.ViaPoint
+	(*AvoidOptions)(nil),          // 6: routing.AvoidOptions
+	(*Polygon)(nil),               // 7: routing.Polygon
+	(*AccessibilityOptions)(nil),  // 8: routing.AccessibilityOptions
+	(*FareProduct)(nil),           // 9: routing.FareProduct
+	(*RoutingWeights)(nil),        // 10: routing.RoutingWeights
+	(*RouteResponse)(nil),         // 11: routing.RouteResponse
+	(*Journey)(nil),               // 12: routing.Journey
+	(*JourneySummary)(nil),        // 13: routing.JourneySummary
+	(*Leg)(nil),                   // 14: routing.Leg
+	(*RideLeg)(nil),               // 15: routing.RideLeg
+	(*WalkLeg)(nil),               // 16: routing.WalkLeg
+	(*TripLeg)(nil),               // 17: routing.TripLeg
+	(*TransferLeg)(nil),           // 18: routing.TransferLeg
+	(*Stop)(nil),                  // 19: routing.Stop
+	(*Coordinate)(nil),            // 20: routing.Coordinate
 }
 var file_routing_proto_depIdxs = []int32{
-	3,  // 0: routing.RouteRequest.weights:type_name -> routing.RoutingWeights
-	5,  // 1: routing.RouteResponse.journeys:type_name -> routing.Journey
-	6,  // 2: routing.Journey.summary:type_name -> routing.JourneySummary
-	7,  // 3: routing.Journey.legs:type_name -> routing.Leg
-	8,  // 4: routing.Leg.walk:type_name -> routing.WalkLeg
-	9,  // 5: routing.Leg.trip:type_name -> routing.TripLeg
-	10, // 6: routing.Leg.transfer:type_name -> routing.TransferLeg
-	12, // 7: routing.WalkLeg.path:type_name -> routing.Coordinate
-	11, // 8: routing.TripLeg.from:type_name -> routing.Stop
-	11, // 9: routing.TripLeg.to:type_name -> routing.Stop
-	12, // 10: routing.TripLeg.path:type_name -> routing.Coordinate
-	12, // 11: routing.TransferLeg.path:type_name -> routing.Coordinate
-	12, // 12: routing.Stop.coord:type_name -> routing.Coordinate
-	0,  // 13: routing.RoutingService.HealthCheck:input_type -> routing.HealthRequest
-	2,  // 14: routing.RoutingService.FindRoute:input_type -> routing.RouteRequest
-	1,  // 15: routing.RoutingService.HealthCheck:output_type -> routing.HealthResponse
-	4,  // 16: routing.RoutingService.FindRoute:output_type -> routing.RouteResponse
-	15, // [15:17] is the sub-list for method output_type
-	13, // [13:15] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	10, // 0: routing.RouteRequest.weights:type_name -> routing.RoutingWeights
+	9,  // 1: routing.RouteRequest.fare_products:type_name -> routing.FareProduct
+	8,  // 2: routing.RouteRequest.accessibility:type_name -> routing.AccessibilityOptions
+	5,  // 3: routing.RouteRequest.via:type_name -> routing.ViaPoint
+	6,  // 4: routing.RouteRequest.avoid:type_name -> routing.AvoidOptions
+	20, // 5: routing.ViaPoint.coord:type_name -> routing.Coordinate
+	7,  // 6: routing.AvoidOptions.areas:type_name -> routing.Polygon
+	20, // 7: routing.Polygon.points:type_name -> routing.Coordinate
+	12, // 8: routing.RouteResponse.journeys:type_name -> routing.Journey
+	13, // 9: routing.Journey.summary:type_name -> routing.JourneySummary
+	14, // 10: routing.Journey.legs:type_name -> routing.Leg
+	16, // 11: routing.Leg.walk:type_name -> routing.WalkLeg
+	17, // 12: routing.Leg.trip:type_name -> routing.TripLeg
+	18, // 13: routing.Leg.transfer:type_name -> routing.TransferLeg
+	15, // 14: routing.Leg.bike:type_name -> routing.RideLeg
+	15, // 15: routing.Leg.scooter:type_name -> routing.RideLeg
+	15, // 16: routing.Leg.taxi:type_name -> routing.RideLeg
+	15, // 17: routing.Leg.ride_hail:type_name -> routing.RideLeg
+	20, // 18: routing.RideLeg.from:type_name -> routing.Coordinate
+	20, // 19: routing.RideLeg.to:type_name -> routing.Coordinate
+	20, // 20: routing.RideLeg.path:type_name -> routing.Coordinate
+	20, // 21: routing.WalkLeg.path:type_name -> routing.Coordinate
+	19, // 22: routing.TripLeg.from:type_name -> routing.Stop
+	19, // 23: routing.TripLeg.to:type_name -> routing.Stop
+	20, // 24: routing.TripLeg.path:type_name -> routing.Coordinate
+	20, // 25: routing.TransferLeg.path:type_name -> routing.Coordinate
+	20, // 26: routing.Stop.coord:type_name -> routing.Coordinate
+	2,  // 27: routing.RoutingService.HealthCheck:input_type -> routing.HealthRequest
+	4,  // 28: routing.RoutingService.FindRoute:input_type -> routing.RouteRequest
+	0,  // 29: routing.RoutingService.ReloadNetwork:input_type -> routing.ReloadNetworkRequest
+	3,  // 30: routing.RoutingService.HealthCheck:output_type -> routing.HealthResponse
+	11, // 31: routing.RoutingService.FindRoute:output_type -> routing.RouteResponse
+	1,  // 32: routing.RoutingService.ReloadNetwork:output_type -> routing.ReloadNetworkResponse
+	30, // [30:33] is the sub-list for method output_type
+	27, // [27:30] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_routing_proto_init() }
@@ -1148,10 +1863,14 @@ func file_routing_proto_init() {
 	if File_routing_proto != nil {
 		return
 	}
-	file_routing_proto_msgTypes[7].OneofWrappers = []any{
+	file_routing_proto_msgTypes[14].OneofWrappers = []any{
 		(*Leg_Walk)(nil),
 		(*Leg_Trip)(nil),
 		(*Leg_Transfer)(nil),
+		(*Leg_Bike)(nil),
+		(*Leg_Scooter)(nil),
+		(*Leg_Taxi)(nil),
+		(*Leg_RideHail)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1159,7 +1878,7 @@ func file_routing_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_routing_proto_rawDesc), len(file_routing_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
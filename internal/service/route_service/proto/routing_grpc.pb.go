@@ -19,8 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RoutingService_HealthCheck_FullMethodName = "/routing.RoutingService/HealthCheck"
-	RoutingService_FindRoute_FullMethodName   = "/routing.RoutingService/FindRoute"
+	RoutingService_HealthCheck_FullMethodName   = "/routing.RoutingService/HealthCheck"
+	RoutingService_FindRoute_FullMethodName     = "/routing.RoutingService/FindRoute"
+	RoutingService_ReloadNetwork_FullMethodName = "/routing.RoutingService/ReloadNetwork"
 )
 
 // RoutingServiceClient is the client API for RoutingService service.
@@ -33,6 +34,8 @@ type RoutingServiceClient interface {
 	HealthCheck(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	// Find routes between two locations
 	FindRoute(ctx context.Context, in *RouteRequest, opts ...grpc.CallOption) (*RouteResponse, error)
+	// Load a newly published network version without restarting
+	ReloadNetwork(ctx context.Context, in *ReloadNetworkRequest, opts ...grpc.CallOption) (*ReloadNetworkResponse, error)
 }
 
 type routingServiceClient struct {
@@ -63,6 +66,16 @@ func (c *routingServiceClient) FindRoute(ctx context.Context, in *RouteRequest,
 	return out, nil
 }
 
+func (c *routingServiceClient) ReloadNetwork(ctx context.Context, in *ReloadNetworkRequest, opts ...grpc.CallOption) (*ReloadNetworkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadNetworkResponse)
+	err := c.cc.Invoke(ctx, RoutingService_ReloadNetwork_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RoutingServiceServer is the server API for RoutingService service.
 // All implementations must embed UnimplementedRoutingServiceServer
 // for forward compatibility.
@@ -73,6 +86,8 @@ type RoutingServiceServer interface {
 	HealthCheck(context.Context, *HealthRequest) (*HealthResponse, error)
 	// Find routes between two locations
 	FindRoute(context.Context, *RouteRequest) (*RouteResponse, error)
+	// Load a newly published network version without restarting
+	ReloadNetwork(context.Context, *ReloadNetworkRequest) (*ReloadNetworkResponse, error)
 	mustEmbedUnimplementedRoutingServiceServer()
 }
 
@@ -89,6 +104,9 @@ func (UnimplementedRoutingServiceServer) HealthCheck(context.Context, *HealthReq
 func (UnimplementedRoutingServiceServer) FindRoute(context.Context, *RouteRequest) (*RouteResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method FindRoute not implemented")
 }
+func (UnimplementedRoutingServiceServer) ReloadNetwork(context.Context, *ReloadNetworkRequest) (*ReloadNetworkResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReloadNetwork not implemented")
+}
 func (UnimplementedRoutingServiceServer) mustEmbedUnimplementedRoutingServiceServer() {}
 func (UnimplementedRoutingServiceServer) testEmbeddedByValue()                        {}
 
@@ -146,6 +164,24 @@ func _RoutingService_FindRoute_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RoutingService_ReloadNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadNetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RoutingServiceServer).ReloadNetwork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RoutingService_ReloadNetwork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RoutingServiceServer).ReloadNetwork(ctx, req.(*ReloadNetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RoutingService_ServiceDesc is the grpc.ServiceDesc for RoutingService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -161,6 +197,10 @@ var RoutingService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FindRoute",
 			Handler:    _RoutingService_FindRoute_Handler,
 		},
+		{
+			MethodName: "ReloadNetwork",
+			Handler:    _RoutingService_ReloadNetwork_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "routing.proto",
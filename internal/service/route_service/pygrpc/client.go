@@ -15,12 +15,18 @@ type ClientConfig struct {
 	Address        string
 	RequestTimeout time.Duration
 	DialOptions    []grpc.DialOption
+	// SnapshotURL is where the router fetches network versions from
+	SnapshotURL string
+	// SnapshotToken authenticates the router when it fetches a snapshot
+	SnapshotToken string
 }
 
 type Client struct {
 	client         pb.RoutingServiceClient
 	conn           *grpc.ClientConn
 	requestTimeout time.Duration
+	snapshotURL    string
+	snapshotToken  string
 }
 
 func NewClient(cfg ClientConfig) (route_service.Router, error) {
@@ -46,6 +52,8 @@ func NewClient(cfg ClientConfig) (route_service.Router, error) {
 		client:         pb.NewRoutingServiceClient(conn),
 		conn:           conn,
 		requestTimeout: timeout,
+		snapshotURL:    cfg.SnapshotURL,
+		snapshotToken:  cfg.SnapshotToken,
 	}, nil
 }
 
@@ -73,6 +81,47 @@ func (c *Client) FindRoute(ctx context.Context, req route_service.RouteRequest)
 		WalkingCutoff:   req.WalkingCutoff,
 		RestrictedModes: req.RestrictedModes,
 		TopK:            req.TopK,
+		RiderCategory:   req.RiderCategory,
+		AccessModes:     req.AccessModes,
+	}
+
+	for _, p := range req.FareProducts {
+		pbReq.FareProducts = append(pbReq.FareProducts, &pb.FareProduct{
+			Type:  p.Type,
+			Route: p.Route,
+			Mode:  p.Mode,
+		})
+	}
+
+	if a := req.Accessibility; a != nil {
+		pbReq.Accessibility = &pb.AccessibilityOptions{
+			StepFreeOnly:           a.StepFreeOnly,
+			MaxWalkMeters:          a.MaxWalkMeters,
+			AvoidStairs:            a.AvoidStairs,
+			AvoidOverpasses:        a.AvoidOverpasses,
+			AccessibleVehiclesOnly: a.AccessibleVehiclesOnly,
+		}
+	}
+
+	for _, v := range req.Via {
+		pbReq.Via = append(pbReq.Via, &pb.ViaPoint{
+			Coord:        &pb.Coordinate{Lon: v.Coord.Lon, Lat: v.Coord.Lat},
+			DwellMinutes: v.DwellMinutes,
+		})
+	}
+
+	if a := req.Avoid; a != nil {
+		pbReq.Avoid = &pb.AvoidOptions{Routes: a.Routes}
+		for _, area := range a.Areas {
+			poly := &pb.Polygon{}
+			for _, c := range area {
+				poly.Points = append(poly.Points, &pb.Coordinate{Lon: c.Lon, Lat: c.Lat})
+			}
+			pbReq.Avoid.Areas = append(pbReq.Avoid.Areas, poly)
+		}
+		for _, id := range a.StopIDs {
+			pbReq.Avoid.StopIds = append(pbReq.Avoid.StopIds, int32(id))
+		}
 	}
 
 	// Map weights if provided
@@ -82,6 +131,7 @@ func (c *Client) FindRoute(ctx context.Context, req route_service.RouteRequest)
 			Cost:     req.Weights.Cost,
 			Walk:     req.Weights.Walk,
 			Transfer: req.Weights.Transfer,
+			Co2:      req.Weights.CO2,
 		}
 	}
 
@@ -104,6 +154,24 @@ func (c *Client) HealthCheck(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
+func (c *Client) ReloadNetwork(ctx context.Context, version int64) error {
+	ctx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+	defer cancel()
+
+	resp, err := c.client.ReloadNetwork(ctx, &pb.ReloadNetworkRequest{
+		Version:       version,
+		SnapshotUrl:   c.snapshotURL,
+		SnapshotToken: c.snapshotToken,
+	})
+	if err != nil {
+		return fmt.Errorf("grpc reloadnetwork failed: %w", err)
+	}
+	if resp.GetVersion() != version {
+		return fmt.Errorf("router loaded network version %d instead of %d: %s", resp.GetVersion(), version, resp.GetMessage())
+	}
+	return nil
+}
+
 func mapProtoToDomain(resp *pb.RouteResponse) route_service.RouteResponse {
 	journeys := make([]route_service.Journey, len(resp.GetJourneys()))
 
@@ -123,6 +191,7 @@ func mapProtoToDomain(resp *pb.RouteResponse) route_service.RouteResponse {
 		EndTripsFound:    int(resp.GetEndTripsFound()),
 		TotalRoutesFound: int(resp.GetTotalRoutesFound()),
 		Error:            resp.GetError(),
+		NetworkVersion:   resp.GetNetworkVersion(),
 	}
 }
 
@@ -156,24 +225,51 @@ func mapLeg(leg *pb.Leg) route_service.Leg {
 	switch v := leg.GetLegType().(type) {
 	case *pb.Leg_Walk:
 		return route_service.Leg{
-			Type: "walk",
+			Type: route_service.LegWalk,
 			Walk: mapWalkLeg(v.Walk),
 		}
 	case *pb.Leg_Trip:
 		return route_service.Leg{
-			Type: "trip",
+			Type: route_service.LegTrip,
 			Trip: mapTripLeg(v.Trip),
 		}
 	case *pb.Leg_Transfer:
 		return route_service.Leg{
-			Type:     "transfer",
+			Type:     route_service.LegTransfer,
 			Transfer: mapTransferLeg(v.Transfer),
 		}
+	case *pb.Leg_Bike:
+		return mapRideLeg(route_service.LegBike, v.Bike)
+	case *pb.Leg_Scooter:
+		return mapRideLeg(route_service.LegScooter, v.Scooter)
+	case *pb.Leg_Taxi:
+		return mapRideLeg(route_service.LegTaxi, v.Taxi)
+	case *pb.Leg_RideHail:
+		return mapRideLeg(route_service.LegRideHail, v.RideHail)
 	default:
 		return route_service.Leg{}
 	}
 }
 
+func mapRideLeg(mode string, r *pb.RideLeg) route_service.Leg {
+	if r == nil {
+		return route_service.Leg{Type: mode}
+	}
+	return route_service.Leg{
+		Type: mode,
+		Ride: &route_service.RideLeg{
+			Mode:            mode,
+			Provider:        r.GetProvider(),
+			From:            mapCoordinate(r.GetFrom()),
+			To:              mapCoordinate(r.GetTo()),
+			DistanceMeters:  int(r.GetDistanceMeters()),
+			DurationMinutes: int(r.GetDurationMinutes()),
+			Cost:            r.GetCost(),
+			Path:            mapCoordinates(r.GetPath()),
+		},
+	}
+}
+
 func mapWalkLeg(w *pb.WalkLeg) *route_service.WalkLeg {
 	if w == nil {
 		return nil
@@ -192,6 +288,7 @@ func mapTripLeg(t *pb.TripLeg) *route_service.TripLeg {
 	return &route_service.TripLeg{
 		TripID:          t.GetTripId(),
 		Mode:            t.GetMode(),
+		RouteID:         t.GetRouteId(),
 		RouteShortName:  t.GetRouteShortName(),
 		Headsign:        t.GetHeadsign(),
 		Fare:            t.GetFare(),
@@ -2,6 +2,12 @@ package route_service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
 )
 
 const (
@@ -10,6 +16,83 @@ const (
 	DefaultTopK          int32   = 5
 )
 
+// Rider categories, students and seniors are eligible for concession fares
+const (
+	RiderAdult   = "adult"
+	RiderStudent = "student"
+	RiderSenior  = "senior"
+)
+
+var RiderCategories = []string{RiderStudent, RiderSenior}
+
+// Wheelchair values of stops and routes, as in GTFS
+const (
+	WheelchairUnknown      = 0
+	WheelchairAccessible   = 1
+	WheelchairInaccessible = 2
+)
+
+// AccessibilityOptions restrict journeys for riders with reduced mobility
+type AccessibilityOptions struct {
+	// StepFreeOnly implies AvoidStairs and AvoidOverpasses and needs
+	// wheelchair boarding at every stop used
+	StepFreeOnly           bool    `json:"step_free_only,omitempty"`
+	MaxWalkMeters          float64 `json:"max_walk_meters,omitempty"`
+	AvoidStairs            bool    `json:"avoid_stairs,omitempty"`
+	AvoidOverpasses        bool    `json:"avoid_overpasses,omitempty"`
+	AccessibleVehiclesOnly bool    `json:"accessible_vehicles_only,omitempty"`
+}
+
+// Fare product types a rider can own
+const (
+	// ProductLinePass covers every ride on one route, such as a monthly pass
+	ProductLinePass = "line_pass"
+	// ProductModePass covers every ride of one mode, such as a metro subscription
+	ProductModePass = "mode_pass"
+)
+
+// FareProduct is a pass the rider already paid for
+type FareProduct struct {
+	Type  string `json:"type"`
+	Route string `json:"route,omitempty"`
+	Mode  string `json:"mode,omitempty"`
+}
+
+// Covers reports whether the product pays for a ride
+func (p FareProduct) Covers(trip *TripLeg) bool {
+	switch p.Type {
+	case ProductLinePass:
+		return p.Route == trip.RouteShortName
+	case ProductModePass:
+		return p.Mode == trip.Mode
+	}
+	return false
+}
+
+// Limits on the via points and avoid areas of a request
+const (
+	MaxViaPoints       = 5
+	MaxDwellMinutes    = 240
+	MaxAvoidAreas      = 10
+	MaxPolygonVertices = 100
+)
+
+// ViaPoint is an intermediate stop the journey must pass through, in order
+type ViaPoint struct {
+	Coord Coordinate `json:"coord"`
+	// DwellMinutes is the time spent at the via point before continuing
+	DwellMinutes int32 `json:"dwell_minutes,omitempty"`
+}
+
+// AvoidOptions keep journeys out of areas, stops and routes, such as
+// squares closed for an event
+type AvoidOptions struct {
+	// Areas are polygons journeys must not enter
+	Areas   [][]Coordinate `json:"areas,omitempty"`
+	StopIDs []int          `json:"stop_ids,omitempty"`
+	Routes  []string       `json:"routes,omitempty"`
+}
+
 // RouteRequest represents a request to find routes between two locations
 type RouteRequest struct {
 	StartLat        float64         `json:"start_lat"`
@@ -21,6 +104,123 @@ type RouteRequest struct {
 	RestrictedModes []string        `json:"restricted_modes,omitempty"`
 	Weights         *RoutingWeights `json:"weights,omitempty"`
 	TopK            int32           `json:"top_k,omitempty"`
+
+	// Saved places and commute profiles of the signed-in user, resolved by
+	// the handler before the request reaches the router
+	StartPlaceID int64 `json:"start_place_id,omitempty"`
+	EndPlaceID   int64 `json:"end_place_id,omitempty"`
+	ProfileID    int64 `json:"profile_id,omitempty"`
+
+	// ExcludeSuspendedRoutes drops journeys using a route or stop with a NO_SERVICE alert
+	ExcludeSuspendedRoutes bool `json:"exclude_suspended_routes,omitempty"`
+
+	// RiderCategory and FareProducts make costs reflect what this rider pays
+	RiderCategory string        `json:"rider_category,omitempty"`
+	FareProducts  []FareProduct `json:"fare_products,omitempty"`
+
+	Accessibility *AccessibilityOptions `json:"accessibility,omitempty"`
+
+	Via   []ViaPoint    `json:"via,omitempty"`
+	Avoid *AvoidOptions `json:"avoid,omitempty"`
+
+	// AccessModes are the ride modes allowed besides walking for the first
+	// and last mile
+	AccessModes []string `json:"access_modes,omitempty"`
+
+	// Debug lists the journeys dropped on the way and why
+	Debug bool `json:"debug,omitempty"`
+}
+
+// Validate checks the optional parts of a request, coordinates are checked
+// by the caller once favorites are resolved
+func (r *RouteRequest) Validate() error {
+	if r.RiderCategory != "" && r.RiderCategory != RiderAdult && !slices.Contains(RiderCategories, r.RiderCategory) {
+		return fmt.Errorf("unknown rider_category '%s'", r.RiderCategory)
+	}
+	if r.Accessibility != nil && r.Accessibility.MaxWalkMeters < 0 {
+		return errors.New("max_walk_meters must not be negative")
+	}
+	for _, p := range r.FareProducts {
+		switch {
+		case p.Type == ProductLinePass && p.Route == "":
+			return errors.New("line_pass fare products need a route")
+		case p.Type == ProductModePass && p.Mode == "":
+			return errors.New("mode_pass fare products need a mode")
+		case p.Type != ProductLinePass && p.Type != ProductModePass:
+			return fmt.Errorf("unknown fare product type '%s'", p.Type)
+		}
+	}
+	for _, m := range r.AccessModes {
+		if !slices.Contains(RideModes, m) {
+			return fmt.Errorf("unknown access mode '%s'", m)
+		}
+	}
+	if err := r.validateVia(); err != nil {
+		return err
+	}
+	return r.validateAvoid()
+}
+
+func (r *RouteRequest) validateVia() error {
+	if len(r.Via) > MaxViaPoints {
+		return fmt.Errorf("at most %d via points are allowed", MaxViaPoints)
+	}
+	for i, v := range r.Via {
+		if v.Coord.Lat == 0 || v.Coord.Lon == 0 || !v.Coord.point().Valid() {
+			return fmt.Errorf("via point %d has invalid coordinates", i+1)
+		}
+		if v.DwellMinutes < 0 || v.DwellMinutes > MaxDwellMinutes {
+			return fmt.Errorf("via point %d dwell_minutes must be between 0 and %d", i+1, MaxDwellMinutes)
+		}
+	}
+	return nil
+}
+
+func (r *RouteRequest) validateAvoid() error {
+	a := r.Avoid
+	if a == nil {
+		return nil
+	}
+	if len(a.Areas) > MaxAvoidAreas {
+		return fmt.Errorf("at most %d avoid areas are allowed", MaxAvoidAreas)
+	}
+
+	start := Coordinate{Lat: r.StartLat, Lon: r.StartLon}.point()
+	end := Coordinate{Lat: r.EndLat, Lon: r.EndLon}.point()
+	for i, area := range a.Areas {
+		if len(area) < 3 || len(area) > MaxPolygonVertices {
+			return fmt.Errorf("avoid area %d must have between 3 and %d points", i+1, MaxPolygonVertices)
+		}
+		poly := make(geo.Polygon, len(area))
+		for k, c := range area {
+			if !c.point().Valid() {
+				return fmt.Errorf("avoid area %d has invalid coordinates", i+1)
+			}
+			poly[k] = c.point()
+		}
+		if poly.Contains(start) || poly.Contains(end) {
+			return fmt.Errorf("avoid area %d contains the start or end of the journey", i+1)
+		}
+		for _, v := range r.Via {
+			if poly.Contains(v.Coord.point()) {
+				return fmt.Errorf("avoid area %d contains a via point", i+1)
+			}
+		}
+	}
+	for _, id := range a.StopIDs {
+		if id <= 0 {
+			return fmt.Errorf("invalid avoid stop id %d", id)
+		}
+	}
+	if slices.Contains(a.Routes, "") {
+		return errors.New("avoid routes must not be empty")
+	}
+	return nil
+}
+
+// UsesFavorites reports whether the request references saved places or profiles
+func (r *RouteRequest) UsesFavorites() bool {
+	return r.StartPlaceID != 0 || r.EndPlaceID != 0 || r.ProfileID != 0
 }
 
 // RoutingWeights for journey ranking
@@ -29,6 +229,8 @@ type RoutingWeights struct {
 	Cost     float64 `json:"cost"`
 	Walk     float64 `json:"walk"`
 	Transfer float64 `json:"transfer"`
+	// CO2 favors the greenest journeys, it is zero unless asked for
+	CO2 float64 `json:"co2,omitempty"`
 }
 
 // ApplyDefaults sets default values for optional fields
@@ -39,6 +241,9 @@ func (r *RouteRequest) ApplyDefaults() {
 	if r.WalkingCutoff == 0 {
 		r.WalkingCutoff = DefaultWalkingCutoff
 	}
+	if a := r.Accessibility; a != nil && a.MaxWalkMeters > 0 && a.MaxWalkMeters < r.WalkingCutoff {
+		r.WalkingCutoff = a.MaxWalkMeters
+	}
 	if r.TopK == 0 {
 		r.TopK = DefaultTopK
 	}
@@ -52,6 +257,66 @@ type RouteResponse struct {
 	EndTripsFound    int       `json:"end_trips_found"`
 	TotalRoutesFound int       `json:"total_routes_found"`
 	Error            string    `json:"error,omitempty"`
+
+	// Personalized is set when journeys were re-ranked with the user's learned weights
+	Personalized bool `json:"personalized,omitempty"`
+	// NetworkVersion is the network data the journeys were computed from
+	NetworkVersion int64 `json:"network_version,omitempty"`
+
+	// Ranking is how journeys were ordered, each one carries its Score
+	Ranking *RankingInfo `json:"ranking,omitempty"`
+	// Dropped lists removed candidates, only for debug requests
+	Dropped []DroppedJourney `json:"dropped,omitempty"`
+}
+
+// SetJourneys replaces the journeys after some were dropped, numbering them
+// from 1 in their current order and keeping NumJourneys in step. Dropped
+// journeys keep the ID they had when they were removed
+func (r *RouteResponse) SetJourneys(journeys []Journey) {
+	for i := range journeys {
+		journeys[i].ID = i + 1
+	}
+	r.Journeys = journeys
+	r.NumJourneys = len(journeys)
+}
+
+// RankingInfo holds the effective weights and the feature ranges used to
+// normalize the candidates
+type RankingInfo struct {
+	Weights RoutingWeights `json:"weights"`
+	Min     Criteria       `json:"min"`
+	Max     Criteria       `json:"max"`
+}
+
+// Criteria are values of the ranking criteria of a journey
+type Criteria struct {
+	Time     float64 `json:"time"`
+	Cost     float64 `json:"cost"`
+	Walk     float64 `json:"walk"`
+	Transfer float64 `json:"transfer"`
+	CO2      float64 `json:"co2"`
+}
+
+// ScoreBreakdown explains a journey's rank, lower totals rank first
+type ScoreBreakdown struct {
+	Total float64 `json:"total"`
+	// Normalized maps each criterion onto [0, 1] across the candidates
+	Normalized Criteria `json:"normalized"`
+	// Weighted are the normalized criteria times their weight, they sum to Total
+	Weighted Criteria `json:"weighted"`
+}
+
+// DroppedJourney is a candidate removed before the response
+type DroppedJourney struct {
+	ID          int            `json:"id"`
+	TextSummary string         `json:"text_summary"`
+	Summary     JourneySummary `json:"summary"`
+	Reason      string         `json:"reason"`
+}
+
+// Dropped records why j was removed
+func Dropped(j Journey, reason string) DroppedJourney {
+	return DroppedJourney{ID: j.ID, TextSummary: j.TextSummary, Summary: j.Summary, Reason: reason}
 }
 
 // Journey represents a single journey option
@@ -60,6 +325,14 @@ type Journey struct {
 	TextSummary string         `json:"text_summary"`
 	Summary     JourneySummary `json:"summary"`
 	Legs        []Leg          `json:"legs"`
+
+	// AccessibilityUnverified is set when accessibility was requested but some
+	// stops or routes used have no accessibility data
+	AccessibilityUnverified bool `json:"accessibility_unverified,omitempty"`
+	// Labels name the criteria this journey is best on, such as "fastest"
+	Labels []string `json:"labels,omitempty"`
+	// Score is set once journeys are ranked
+	Score *ScoreBreakdown `json:"score,omitempty"`
 }
 
 // JourneySummary contains summary metrics for a journey
@@ -70,14 +343,52 @@ type JourneySummary struct {
 	Transfers             int      `json:"transfers"`
 	Cost                  float64  `json:"cost"`
 	Modes                 []string `json:"modes"`
+
+	// CO2Grams is the CO2-equivalent emitted per passenger, unset when a
+	// ride's mode has no emission factor. DrivingCO2Grams is what driving the
+	// same distance would emit
+	CO2Grams        *int `json:"co2_grams,omitempty"`
+	DrivingCO2Grams int  `json:"driving_co2_grams"`
 }
 
+// Leg types
+const (
+	LegWalk     = "walk"
+	LegTrip     = "trip"
+	LegTransfer = "transfer"
+	LegBike     = "bike"
+	LegScooter  = "scooter"
+	LegTaxi     = "taxi"
+	LegRideHail = "ride_hail"
+)
+
+// RideModes are the leg types carried by a RideLeg
+var RideModes = []string{LegBike, LegScooter, LegTaxi, LegRideHail}
+
 // Leg represents a segment of the journey
 type Leg struct {
-	Type     string       `json:"type"` // "walk", "trip", or "transfer"
+	Type     string       `json:"type"`
 	Walk     *WalkLeg     `json:"walk,omitempty"`
 	Trip     *TripLeg     `json:"trip,omitempty"`
 	Transfer *TransferLeg `json:"transfer,omitempty"`
+	// Ride is set for bike, scooter, taxi and ride-hail legs
+	Ride *RideLeg `json:"ride,omitempty"`
+	// Instruction is the leg in words, in the language the client accepts
+	Instruction string `json:"instruction,omitempty"`
+}
+
+// RideLeg is a bike, scooter, taxi or ride-hail segment, typically the first
+// or last mile of a journey
+type RideLeg struct {
+	Mode            string       `json:"mode"`
+	Provider        string       `json:"provider,omitempty"`
+	From            Coordinate   `json:"from"`
+	To              Coordinate   `json:"to"`
+	DistanceMeters  int          `json:"distance_meters"`
+	DurationMinutes int          `json:"duration_minutes"`
+	Cost            float64      `json:"cost"`
+	Path            []Coordinate `json:"path,omitempty"`
+	CO2Grams        *int         `json:"co2_grams,omitempty"`
 }
 
 // WalkLeg represents a walking segment
@@ -91,6 +402,7 @@ type WalkLeg struct {
 type TripLeg struct {
 	TripID          string       `json:"trip_id"`
 	Mode            string       `json:"mode"`
+	RouteID         string       `json:"route_id,omitempty"`
 	RouteShortName  string       `json:"route_short_name"`
 	Headsign        string       `json:"headsign"`
 	Fare            float64      `json:"fare"`
@@ -98,6 +410,49 @@ type TripLeg struct {
 	From            Stop         `json:"from"`
 	To              Stop         `json:"to"`
 	Path            []Coordinate `json:"path,omitempty"`
+
+	// Realtime is filled from live feeds when data for the trip is available
+	Realtime *RealtimeInfo `json:"realtime,omitempty"`
+	// Alerts are the active service alerts affecting the route or trip
+	Alerts []Alert `json:"alerts,omitempty"`
+	// CO2Grams is this passenger's share of the vehicle's emissions, unset
+	// when the mode has no emission factor
+	CO2Grams *int `json:"co2_grams,omitempty"`
+}
+
+// RideMeters measures a ride along its path, or as the crow flies without one
+func (t *TripLeg) RideMeters() float64 {
+	if len(t.Path) > 1 {
+		points := make([]geo.Point, len(t.Path))
+		for i, c := range t.Path {
+			points[i] = c.point()
+		}
+		return geo.Length(points)
+	}
+	return geo.Distance(t.From.Coord.point(), t.To.Coord.point())
+}
+
+// RealtimeInfo is live data attached to a trip leg
+type RealtimeInfo struct {
+	DelaySeconds int              `json:"delay_seconds"`
+	Vehicle      *VehiclePosition `json:"vehicle,omitempty"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+
+	// Crowdsourced estimates for trips without vehicle tracking
+	Vehicles       []VehiclePosition `json:"vehicles,omitempty"`
+	HeadwaySeconds int               `json:"headway_seconds,omitempty"`
+}
+
+// VehiclePosition is the last known position of a vehicle serving a trip
+type VehiclePosition struct {
+	VehicleID string     `json:"vehicle_id,omitempty"`
+	Coord     Coordinate `json:"coord"`
+	Bearing   float64    `json:"bearing,omitempty"`
+	SpeedMps  float64    `json:"speed_mps,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+	Source    string     `json:"source"`
+	// Reporters is the number of riders behind a crowdsourced position
+	Reporters int `json:"reporters,omitempty"`
 }
 
 // TransferLeg represents a transfer between trips
@@ -116,6 +471,70 @@ type Stop struct {
 	StopID int        `json:"stop_id"`
 	Name   string     `json:"name"`
 	Coord  Coordinate `json:"coord"`
+	Alerts []Alert    `json:"alerts,omitempty"`
+}
+
+// Alert effects as defined by GTFS-Realtime
+const (
+	EffectNoService      = "NO_SERVICE"
+	EffectReducedService = "REDUCED_SERVICE"
+	EffectDetour         = "DETOUR"
+)
+
+// Alert is a service alert, either published by an admin or received from a
+// GTFS-Realtime feed. Routes hold GTFS route_ids and are matched on TripLeg.RouteID
+type Alert struct {
+	ID            string            `json:"id"`
+	Source        string            `json:"source"`
+	Cause         string            `json:"cause"`
+	Effect        string            `json:"effect"`
+	Severity      string            `json:"severity"`
+	Header        map[string]string `json:"header"`
+	Description   map[string]string `json:"description,omitempty"`
+	Routes        []string          `json:"routes,omitempty"`
+	TripIDs       []string          `json:"trip_ids,omitempty"`
+	StopIDs       []int             `json:"stop_ids,omitempty"`
+	ActivePeriods []AlertPeriod     `json:"active_periods,omitempty"`
+}
+
+// AlertPeriod is an active period of an alert, zero times are open ended
+type AlertPeriod struct {
+	Start time.Time `json:"start,omitzero"`
+	End   time.Time `json:"end,omitzero"`
+}
+
+// ActiveAt reports whether the alert applies at t, alerts without periods always apply
+func (a Alert) ActiveAt(t time.Time) bool {
+	return a.ActiveDuring(t, t)
+}
+
+// ActiveDuring reports whether the alert applies at any time from from to to
+func (a Alert) ActiveDuring(from, to time.Time) bool {
+	if len(a.ActivePeriods) == 0 {
+		return true
+	}
+	for _, p := range a.ActivePeriods {
+		if (p.Start.IsZero() || !to.Before(p.Start)) && (p.End.IsZero() || from.Before(p.End)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Affects reports whether the alert applies to the trip's route, the trip
+// itself or the stops it is boarded and left at
+func (a Alert) Affects(trip *TripLeg) bool {
+	return a.OnTrip(trip) || a.AtStop(trip.From.StopID) || a.AtStop(trip.To.StopID)
+}
+
+// OnTrip reports whether the alert names the trip or its route
+func (a Alert) OnTrip(trip *TripLeg) bool {
+	return slices.Contains(a.Routes, trip.RouteID) || slices.Contains(a.TripIDs, trip.TripID)
+}
+
+// AtStop reports whether the alert names the stop
+func (a Alert) AtStop(stopID int) bool {
+	return slices.Contains(a.StopIDs, stopID)
 }
 
 // Coordinate represents a geographic coordinate
@@ -124,9 +543,15 @@ type Coordinate struct {
 	Lat float64 `json:"lat"`
 }
 
+func (c Coordinate) point() geo.Point {
+	return geo.Point{Lat: c.Lat, Lon: c.Lon}
+}
+
 // Router interface for route finding services
 type Router interface {
 	FindRoute(ctx context.Context, req RouteRequest) (RouteResponse, error)
 	HealthCheck(ctx context.Context) (bool, error)
+	// ReloadNetwork makes the router serve a published network version
+	ReloadNetwork(ctx context.Context, version int64) error
 	Close() error
 }
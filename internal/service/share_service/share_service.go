@@ -0,0 +1,148 @@
+package share_service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	DefaultTTL = 30 * 24 * time.Hour
+	idLength   = 10
+	idAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	maxRetries = 3
+)
+
+var ErrNotFound = errors.New("shared journey not found or expired")
+
+// SharedJourney is a journey persisted under a short opaque ID
+type SharedJourney struct {
+	ID        string                `json:"id"`
+	Journey   route_service.Journey `json:"journey"`
+	CreatedAt time.Time             `json:"created_at"`
+	ExpiresAt time.Time             `json:"expires_at"`
+}
+
+type Service struct {
+	store *postgres.Store
+	ttl   time.Duration
+}
+
+func NewService(store *postgres.Store, ttl time.Duration) *Service {
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	return &Service{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// Save persists the journey and returns it with its new short ID
+func (s *Service) Save(ctx context.Context, journey route_service.Journey) (SharedJourney, error) {
+	payload, err := json.Marshal(journey)
+	if err != nil {
+		return SharedJourney{}, fmt.Errorf("failed to encode journey: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+
+	// IDs are random so collisions are rare, retry a few times just in case
+	for range maxRetries {
+		id, err := newID()
+		if err != nil {
+			return SharedJourney{}, err
+		}
+
+		row, err := s.store.CreateSharedJourney(ctx, database.CreateSharedJourneyParams{
+			ID:        id,
+			Journey:   payload,
+			ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		})
+		if postgres.IsUniqueViolation(err) {
+			continue
+		}
+		if err != nil {
+			return SharedJourney{}, fmt.Errorf("failed to save journey: %w", err)
+		}
+		return toSharedJourney(row)
+	}
+
+	return SharedJourney{}, errors.New("failed to allocate a unique journey id")
+}
+
+// Get returns a non-expired shared journey
+func (s *Service) Get(ctx context.Context, id string) (SharedJourney, error) {
+	row, err := s.store.GetSharedJourney(ctx, id)
+	if postgres.IsNotFound(err) {
+		return SharedJourney{}, ErrNotFound
+	}
+	if err != nil {
+		return SharedJourney{}, fmt.Errorf("failed to load journey: %w", err)
+	}
+	return toSharedJourney(row)
+}
+
+// PurgeExpired periodically deletes expired journeys until ctx is done
+func (s *Service) PurgeExpired(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.store.DeleteExpiredSharedJourneys(ctx)
+			if err != nil {
+				log.Printf("Error purging expired shared journeys: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Purged %d expired shared journeys", n)
+			}
+		}
+	}
+}
+
+func toSharedJourney(row database.SharedJourney) (SharedJourney, error) {
+	var journey route_service.Journey
+	if err := json.Unmarshal(row.Journey, &journey); err != nil {
+		return SharedJourney{}, fmt.Errorf("failed to decode journey: %w", err)
+	}
+	return SharedJourney{
+		ID:        row.ID,
+		Journey:   journey,
+		CreatedAt: row.CreatedAt.Time,
+		ExpiresAt: row.ExpiresAt.Time,
+	}, nil
+}
+
+// newID draws idLength characters uniformly from idAlphabet. Random bytes at
+// or above the largest multiple of the alphabet size are rejected, mapping
+// them with a plain modulo would favour the first characters
+func newID() (string, error) {
+	limit := 256 - 256%len(idAlphabet)
+	id := make([]byte, 0, idLength)
+	buf := make([]byte, idLength)
+	for len(id) < idLength {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate id: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) < limit && len(id) < idLength {
+				id = append(id, idAlphabet[int(b)%len(idAlphabet)])
+			}
+		}
+	}
+	return string(id), nil
+}
@@ -0,0 +1,226 @@
+package submission_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+	"github.com/Marwan051/final_project_backend/internal/service/moderation_service"
+	"github.com/Marwan051/final_project_backend/internal/service/network_service"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+
+	DefaultMode = "microbus"
+)
+
+var (
+	ErrNotFound          = errors.New("route submission not found")
+	ErrInvalidSubmission = errors.New("invalid route submission")
+)
+
+// Submission is a route shape proposed by a rider or field staff
+type Submission struct {
+	ID                  int64                      `json:"id"`
+	SubmittedBy         int64                      `json:"submitted_by,omitempty"`
+	Name                string                     `json:"name"`
+	Mode                string                     `json:"mode"`
+	Fare                *float64                   `json:"fare,omitempty"`
+	OriginTerminal      string                     `json:"origin_terminal"`
+	DestinationTerminal string                     `json:"destination_terminal"`
+	Shape               []route_service.Coordinate `json:"shape"`
+	RawPoints           int                        `json:"raw_points"`
+	LengthMeters        float64                    `json:"length_meters"`
+	// MatchedRatio is the share of the trace snapped onto streets, 0 when
+	// no street network is loaded
+	MatchedRatio float64   `json:"matched_ratio"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SubmissionParams is an uploaded trace, either as GPX or a coordinate list,
+// and what the submitter knows about the route
+type SubmissionParams struct {
+	Name                string       `json:"name"`
+	Mode                string       `json:"mode,omitempty"`
+	Fare                *float64     `json:"fare,omitempty"`
+	OriginTerminal      string       `json:"origin_terminal"`
+	DestinationTerminal string       `json:"destination_terminal"`
+	GPX                 string       `json:"gpx,omitempty"`
+	Points              []TracePoint `json:"points,omitempty"`
+}
+
+type Service struct {
+	store   *postgres.Store
+	matcher Matcher
+}
+
+// NewService creates the submission service, traces are map-matched with
+// matcher when it is not nil
+func NewService(store *postgres.Store, matcher Matcher) *Service {
+	return &Service{
+		store:   store,
+		matcher: matcher,
+	}
+}
+
+// Submit cleans, map-matches and simplifies the trace and queues it for
+// moderation as a new route
+func (s *Service) Submit(ctx context.Context, userID int64, params SubmissionParams) (Submission, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	params.OriginTerminal = strings.TrimSpace(params.OriginTerminal)
+	params.DestinationTerminal = strings.TrimSpace(params.DestinationTerminal)
+	if params.Mode == "" {
+		params.Mode = DefaultMode
+	}
+
+	switch {
+	case params.Name == "":
+		return Submission{}, fmt.Errorf("%w: name is required", ErrInvalidSubmission)
+	case params.OriginTerminal == "" || params.DestinationTerminal == "":
+		return Submission{}, fmt.Errorf("%w: both terminals are required", ErrInvalidSubmission)
+	case params.Fare != nil && *params.Fare < 0:
+		return Submission{}, fmt.Errorf("%w: fare must not be negative", ErrInvalidSubmission)
+	case (params.GPX == "") == (len(params.Points) == 0):
+		return Submission{}, fmt.Errorf("%w: send either gpx or points", ErrInvalidSubmission)
+	}
+
+	points := params.Points
+	if params.GPX != "" {
+		var err error
+		if points, err = ParseGPX(params.GPX); err != nil {
+			return Submission{}, err
+		}
+	}
+	if len(points) > maxTracePoints {
+		return Submission{}, fmt.Errorf("%w: trace has more than %d points", ErrInvalidSubmission, maxTracePoints)
+	}
+
+	shape, matchedRatio := Shape(points, s.matcher)
+	length := geo.Length(shape)
+	if len(shape) < 2 || length < minRouteLengthMeters {
+		return Submission{}, fmt.Errorf("%w: trace is shorter than %d meters after cleaning", ErrInvalidSubmission, minRouteLengthMeters)
+	}
+
+	encoded, err := json.Marshal(toCoordinates(shape))
+	if err != nil {
+		return Submission{}, err
+	}
+
+	var fare pgtype.Float8
+	if params.Fare != nil {
+		fare = pgtype.Float8{Float64: *params.Fare, Valid: true}
+	}
+
+	// The proposed route goes through the moderation queue like any other edit
+	route, err := json.Marshal(network_service.RouteParams{
+		ShortName: params.Name,
+		LongName:  params.OriginTerminal + " - " + params.DestinationTerminal,
+		Mode:      params.Mode,
+		Shape:     toCoordinates(shape),
+		Fare:      params.Fare,
+	})
+	if err != nil {
+		return Submission{}, err
+	}
+
+	var row database.RouteSubmission
+	err = s.store.InTx(ctx, func(q *database.Queries) error {
+		var err error
+		row, err = q.CreateRouteSubmission(ctx, database.CreateRouteSubmissionParams{
+			SubmittedBy:         pgtype.Int8{Int64: userID, Valid: userID != 0},
+			Name:                params.Name,
+			Mode:                params.Mode,
+			Fare:                fare,
+			OriginTerminal:      params.OriginTerminal,
+			DestinationTerminal: params.DestinationTerminal,
+			Shape:               encoded,
+			RawPoints:           int32(len(points)),
+			LengthMeters:        length,
+			MatchedRatio:        matchedRatio,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save route submission: %w", err)
+		}
+
+		_, err = moderation_service.Propose(ctx, q, userID, row.ID, network_service.Change{
+			Entity:  network_service.EntityRoute,
+			Action:  network_service.ActionCreate,
+			Payload: route,
+		})
+		return err
+	})
+	if err != nil {
+		return Submission{}, err
+	}
+	return toSubmission(row)
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (Submission, error) {
+	row, err := s.store.GetRouteSubmission(ctx, id)
+	if postgres.IsNotFound(err) {
+		return Submission{}, ErrNotFound
+	}
+	if err != nil {
+		return Submission{}, fmt.Errorf("failed to load route submission: %w", err)
+	}
+	return toSubmission(row)
+}
+
+// ListByUser returns a user's submissions, newest first
+func (s *Service) ListByUser(ctx context.Context, userID int64) ([]Submission, error) {
+	rows, err := s.store.ListRouteSubmissionsByUser(ctx, pgtype.Int8{Int64: userID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list route submissions: %w", err)
+	}
+
+	submissions := make([]Submission, len(rows))
+	for i, row := range rows {
+		if submissions[i], err = toSubmission(row); err != nil {
+			return nil, err
+		}
+	}
+	return submissions, nil
+}
+
+func toSubmission(row database.RouteSubmission) (Submission, error) {
+	sub := Submission{
+		ID:                  row.ID,
+		SubmittedBy:         row.SubmittedBy.Int64,
+		Name:                row.Name,
+		Mode:                row.Mode,
+		OriginTerminal:      row.OriginTerminal,
+		DestinationTerminal: row.DestinationTerminal,
+		RawPoints:           int(row.RawPoints),
+		LengthMeters:        row.LengthMeters,
+		MatchedRatio:        row.MatchedRatio,
+		Status:              row.Status,
+		CreatedAt:           row.CreatedAt.Time,
+	}
+	if row.Fare.Valid {
+		sub.Fare = &row.Fare.Float64
+	}
+	if err := json.Unmarshal(row.Shape, &sub.Shape); err != nil {
+		return Submission{}, fmt.Errorf("failed to decode route shape: %w", err)
+	}
+	return sub, nil
+}
+
+func toCoordinates(points []geo.Point) []route_service.Coordinate {
+	coords := make([]route_service.Coordinate, len(points))
+	for i, p := range points {
+		coords[i] = route_service.Coordinate{Lat: p.Lat, Lon: p.Lon}
+	}
+	return coords
+}
@@ -0,0 +1,127 @@
+package submission_service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+)
+
+const (
+	// maxTracePoints caps the size of an uploaded trace
+	maxTracePoints = 50000
+	// minPointSpacingMeters collapses GPS jitter while the vehicle is stopped
+	minPointSpacingMeters = 5
+	// maxTraceSpeedMps drops fixes no microbus could have reached
+	maxTraceSpeedMps = 40
+	// simplifyToleranceMeters is how far the stored shape may stray from the trace
+	simplifyToleranceMeters = 10
+	// minRouteLengthMeters rejects traces too short to be a route
+	minRouteLengthMeters = 200
+)
+
+// TracePoint is a GPS fix of an uploaded trace, time is optional
+type TracePoint struct {
+	Lat  float64   `json:"lat"`
+	Lon  float64   `json:"lon"`
+	Time time.Time `json:"time,omitzero"`
+}
+
+type gpxFile struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Time string  `xml:"time"`
+}
+
+// ParseGPX reads the track points of a GPX document, falling back to its
+// route points when it has no tracks
+func ParseGPX(data string) ([]TracePoint, error) {
+	var doc gpxFile
+	if err := xml.NewDecoder(strings.NewReader(data)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: invalid GPX: %v", ErrInvalidSubmission, err)
+	}
+
+	var raw []gpxPoint
+	for _, trk := range doc.Tracks {
+		for _, seg := range trk.Segments {
+			raw = append(raw, seg.Points...)
+		}
+	}
+	if len(raw) == 0 {
+		for _, rte := range doc.Routes {
+			raw = append(raw, rte.Points...)
+		}
+	}
+
+	points := make([]TracePoint, len(raw))
+	for i, p := range raw {
+		points[i] = TracePoint{Lat: p.Lat, Lon: p.Lon}
+		if p.Time != "" {
+			// A bad timestamp only disables the speed check for that point
+			points[i].Time, _ = time.Parse(time.RFC3339, strings.TrimSpace(p.Time))
+		}
+	}
+	return points, nil
+}
+
+// Clean drops invalid fixes, jitter and impossible jumps from a trace
+func Clean(points []TracePoint) []TracePoint {
+	cleaned := make([]TracePoint, 0, len(points))
+	for _, p := range points {
+		point := geo.Point{Lat: p.Lat, Lon: p.Lon}
+		if !point.Valid() || (p.Lat == 0 && p.Lon == 0) {
+			continue
+		}
+		if len(cleaned) > 0 {
+			prev := cleaned[len(cleaned)-1]
+			d := geo.Distance(geo.Point{Lat: prev.Lat, Lon: prev.Lon}, point)
+			if d < minPointSpacingMeters {
+				continue
+			}
+			if !p.Time.IsZero() && !prev.Time.IsZero() {
+				elapsed := p.Time.Sub(prev.Time).Seconds()
+				if elapsed <= 0 || d/elapsed > maxTraceSpeedMps {
+					continue
+				}
+			}
+		}
+		cleaned = append(cleaned, p)
+	}
+	return cleaned
+}
+
+// Matcher snaps a GPS trace onto the street network and reports the share
+// of fixes it matched
+type Matcher interface {
+	Match(trace []geo.Point) ([]geo.Point, float64)
+}
+
+// Shape cleans a trace, map-matches it onto the streets when a matcher is
+// given and simplifies it into a route shape. It returns the share of fixes
+// matched, 0 without a matcher
+func Shape(points []TracePoint, matcher Matcher) ([]geo.Point, float64) {
+	cleaned := Clean(points)
+	line := make([]geo.Point, len(cleaned))
+	for i, p := range cleaned {
+		line[i] = geo.Point{Lat: p.Lat, Lon: p.Lon}
+	}
+
+	var ratio float64
+	if matcher != nil && len(line) > 0 {
+		line, ratio = matcher.Match(line)
+	}
+	return geo.Simplify(line, simplifyToleranceMeters), ratio
+}
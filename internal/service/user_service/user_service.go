@@ -0,0 +1,220 @@
+package user_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Marwan051/final_project_backend/internal/auth"
+	"github.com/Marwan051/final_project_backend/internal/store/postgres"
+	database "github.com/Marwan051/final_project_backend/internal/store/postgres/sqlc_out"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrInvalidEmail       = errors.New("invalid email address")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrNotFound           = errors.New("user not found")
+	ErrInvalidRole        = errors.New("invalid role")
+)
+
+// User is the public view of an account
+type User struct {
+	ID          int64     `json:"id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TokenPair is returned on login and refresh
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	TokenType        string    `json:"token_type"`
+}
+
+type Service struct {
+	store  *postgres.Store
+	tokens *auth.TokenManager
+}
+
+func NewService(store *postgres.Store, tokens *auth.TokenManager) *Service {
+	return &Service{
+		store:  store,
+		tokens: tokens,
+	}
+}
+
+// Register creates a new account with the user role. Admins are promoted
+// from the command line with SetRole, never at registration
+func (s *Service) Register(ctx context.Context, email, password, displayName string) (User, error) {
+	email = normalizeEmail(email)
+	if _, err := mail.ParseAddress(email); err != nil {
+		return User{}, ErrInvalidEmail
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return User{}, err
+	}
+
+	row, err := s.store.CreateUser(ctx, database.CreateUserParams{
+		Email:        email,
+		PasswordHash: hash,
+		DisplayName:  strings.TrimSpace(displayName),
+		Role:         auth.RoleUser,
+	})
+	if postgres.IsUniqueViolation(err) {
+		return User{}, ErrEmailTaken
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return toUser(row), nil
+}
+
+// Login verifies the credentials and issues a token pair
+func (s *Service) Login(ctx context.Context, email, password string) (TokenPair, error) {
+	if len(password) > auth.MaxPasswordLength {
+		return TokenPair{}, auth.ErrPasswordTooLong
+	}
+
+	row, err := s.store.GetUserByEmail(ctx, normalizeEmail(email))
+	if postgres.IsNotFound(err) {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	if !auth.CheckPassword(row.PasswordHash, password) {
+		return TokenPair{}, ErrInvalidCredentials
+	}
+
+	return s.issue(ctx, row)
+}
+
+// Refresh rotates a refresh token, the old one can not be used again
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	rt, err := s.tokens.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if _, err := s.store.RevokeRefreshToken(ctx, rt.ID); err != nil {
+		if postgres.IsNotFound(err) {
+			return TokenPair{}, auth.ErrInvalidToken
+		}
+		return TokenPair{}, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	row, err := s.store.GetUserByID(ctx, rt.UserID)
+	if postgres.IsNotFound(err) {
+		return TokenPair{}, auth.ErrInvalidToken
+	}
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	return s.issue(ctx, row)
+}
+
+// Logout revokes a refresh token, unknown or already revoked tokens are ignored
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	rt, err := s.tokens.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.store.RevokeRefreshToken(ctx, rt.ID); err != nil && !postgres.IsNotFound(err) {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	return nil
+}
+
+// Get returns a user by ID
+func (s *Service) Get(ctx context.Context, id int64) (User, error) {
+	row, err := s.store.GetUserByID(ctx, id)
+	if postgres.IsNotFound(err) {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("failed to load user: %w", err)
+	}
+	return toUser(row), nil
+}
+
+// SetRole changes the role of an existing account. The new role applies to
+// access tokens issued after the change
+func (s *Service) SetRole(ctx context.Context, email, role string) (User, error) {
+	if role != auth.RoleUser && role != auth.RoleAdmin {
+		return User{}, fmt.Errorf("%w: '%s'", ErrInvalidRole, role)
+	}
+
+	row, err := s.store.SetUserRole(ctx, database.SetUserRoleParams{
+		Email: normalizeEmail(email),
+		Role:  role,
+	})
+	if postgres.IsNotFound(err) {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("failed to update role: %w", err)
+	}
+	return toUser(row), nil
+}
+
+func (s *Service) issue(ctx context.Context, row database.User) (TokenPair, error) {
+	access, accessExpiresAt, err := s.tokens.IssueAccessToken(auth.Principal{
+		UserID: row.ID,
+		Email:  row.Email,
+		Role:   row.Role,
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refresh, err := s.tokens.IssueRefreshToken(row.ID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	err = s.store.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{
+		ID:        refresh.ID,
+		UserID:    row.ID,
+		ExpiresAt: pgtype.Timestamptz{Time: refresh.ExpiresAt, Valid: true},
+	})
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refresh.Token,
+		RefreshExpiresAt: refresh.ExpiresAt,
+		TokenType:        "Bearer",
+	}, nil
+}
+
+func toUser(row database.User) User {
+	return User{
+		ID:          row.ID,
+		Email:       row.Email,
+		DisplayName: row.DisplayName,
+		Role:        row.Role,
+		CreatedAt:   row.CreatedAt.Time,
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
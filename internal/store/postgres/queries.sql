@@ -0,0 +1,515 @@
+-- name: CreateSharedJourney :one
+INSERT INTO shared_journeys (id, journey, expires_at)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetSharedJourney :one
+SELECT * FROM shared_journeys
+WHERE id = $1 AND expires_at > now();
+
+-- name: DeleteExpiredSharedJourneys :execrows
+DELETE FROM shared_journeys
+WHERE expires_at <= now();
+
+-- name: CreateUser :one
+INSERT INTO users (email, password_hash, display_name, role)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $2, updated_at = now()
+WHERE email = $1
+RETURNING *;
+
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (id, user_id, expires_at)
+VALUES ($1, $2, $3);
+
+-- name: RevokeRefreshToken :one
+-- Revokes an active token, returning no rows if it was already revoked or expired
+UPDATE refresh_tokens
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL AND expires_at > now()
+RETURNING *;
+
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, owner_id, prefix, key_hash, scopes, daily_quota, rate_limit_per_minute)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: ListAPIKeys :many
+SELECT k.*, COALESCE(u.request_count, 0)::INTEGER AS requests_today
+FROM api_keys k
+LEFT JOIN api_key_usage u ON u.key_id = k.id AND u.day = (now() AT TIME ZONE 'UTC')::date
+ORDER BY k.id;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL;
+
+-- name: RotateAPIKey :one
+UPDATE api_keys
+SET prefix = $2, key_hash = $3, rotated_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: IncrementAPIKeyUsage :one
+-- Counts a request against the key's quota for the current UTC day. Nothing
+-- is counted and no row is returned once the quota is used up, zero is unlimited
+INSERT INTO api_key_usage AS u (key_id, day, request_count)
+VALUES (sqlc.arg(key_id), (now() AT TIME ZONE 'UTC')::date, 1)
+ON CONFLICT (key_id, day) DO UPDATE
+SET request_count = u.request_count + 1
+WHERE sqlc.arg(quota)::integer = 0 OR u.request_count < sqlc.arg(quota)::integer
+RETURNING request_count;
+
+-- name: TakeRateLimitToken :one
+-- Refills the bucket for the time elapsed since the last call and takes a
+-- token if one is available, all in a single atomic statement
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES (@key, sqlc.arg(burst)::float8 - 1, TRUE, now())
+ON CONFLICT (key) DO UPDATE SET
+    tokens = CASE
+        WHEN LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * sqlc.arg(rate)::float8) >= 1
+        THEN LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * sqlc.arg(rate)::float8) - 1
+        ELSE LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * sqlc.arg(rate)::float8)
+    END,
+    allowed = LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * sqlc.arg(rate)::float8) >= 1,
+    updated_at = now()
+RETURNING tokens, allowed;
+
+-- name: PeekRateLimitTokens :one
+-- Returns the tokens the bucket would hold now without taking one
+SELECT LEAST(sqlc.arg(burst)::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * sqlc.arg(rate)::float8)::float8 AS tokens
+FROM rate_limit_buckets b
+WHERE b.key = @key;
+
+-- name: DeleteIdleRateLimitBuckets :exec
+DELETE FROM rate_limit_buckets
+WHERE updated_at < @idle_since;
+
+-- name: CreatePlace :one
+INSERT INTO places (user_id, name, kind, lat, lon)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListPlaces :many
+SELECT * FROM places
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetPlace :one
+SELECT * FROM places
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdatePlace :one
+UPDATE places
+SET name = $3, kind = $4, lat = $5, lon = $6, updated_at = now()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeletePlace :execrows
+DELETE FROM places
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateCommuteProfile :one
+INSERT INTO commute_profiles (user_id, name, restricted_modes, weights, walking_cutoff, max_transfers)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListCommuteProfiles :many
+SELECT * FROM commute_profiles
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetCommuteProfile :one
+SELECT * FROM commute_profiles
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateCommuteProfile :one
+UPDATE commute_profiles
+SET name = $3, restricted_modes = $4, weights = $5, walking_cutoff = $6, max_transfers = $7, updated_at = now()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteCommuteProfile :execrows
+DELETE FROM commute_profiles
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateTripSelection :one
+INSERT INTO trip_selections (user_id, selected, alternatives)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListTripSelections :many
+SELECT * FROM trip_selections
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: GetUserRankingWeights :one
+SELECT * FROM user_ranking_weights
+WHERE user_id = $1;
+
+-- name: GetUserRankingWeightsForUpdate :one
+SELECT * FROM user_ranking_weights
+WHERE user_id = $1
+FOR UPDATE;
+
+-- name: UpsertUserRankingWeights :one
+INSERT INTO user_ranking_weights (user_id, time, cost, walk, transfer, samples)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id) DO UPDATE
+SET time = EXCLUDED.time,
+    cost = EXCLUDED.cost,
+    walk = EXCLUDED.walk,
+    transfer = EXCLUDED.transfer,
+    samples = EXCLUDED.samples,
+    updated_at = now()
+RETURNING *;
+
+-- name: CreateServiceAlert :one
+INSERT INTO service_alerts (cause, effect, severity, header, description, routes, trip_ids, stop_ids, active_periods, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: ListServiceAlerts :many
+SELECT * FROM service_alerts
+ORDER BY id DESC;
+
+-- name: GetServiceAlert :one
+SELECT * FROM service_alerts
+WHERE id = $1;
+
+-- name: UpdateServiceAlert :one
+UPDATE service_alerts
+SET cause = $2, effect = $3, severity = $4, header = $5, description = $6,
+    routes = $7, trip_ids = $8, stop_ids = $9, active_periods = $10, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteServiceAlert :execrows
+DELETE FROM service_alerts
+WHERE id = $1;
+
+-- name: CreateRouteSubmission :one
+INSERT INTO route_submissions (submitted_by, name, mode, fare, origin_terminal, destination_terminal, shape, raw_points, length_meters, matched_ratio)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetRouteSubmission :one
+SELECT * FROM route_submissions
+WHERE id = $1;
+
+-- name: ListRouteSubmissionsByUser :many
+SELECT * FROM route_submissions
+WHERE submitted_by = $1
+ORDER BY created_at DESC;
+
+-- name: UpdateRouteSubmissionStatus :exec
+UPDATE route_submissions
+SET status = $2
+WHERE id = $1;
+
+-- name: ListNetworkStops :many
+SELECT * FROM network_stops
+ORDER BY id;
+
+-- name: GetNetworkStop :one
+SELECT * FROM network_stops
+WHERE id = $1;
+
+-- name: CreateNetworkStop :one
+INSERT INTO network_stops (name, lat, lon, zone, wheelchair_boarding, has_stairs, has_overpass)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UpdateNetworkStop :one
+UPDATE network_stops
+SET name = $2, lat = $3, lon = $4, zone = $5, wheelchair_boarding = $6, has_stairs = $7, has_overpass = $8,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteNetworkStop :execrows
+DELETE FROM network_stops
+WHERE id = $1;
+
+-- name: ListNetworkRoutes :many
+SELECT * FROM network_routes
+ORDER BY id;
+
+-- name: GetNetworkRoute :one
+SELECT * FROM network_routes
+WHERE id = $1;
+
+-- name: CreateNetworkRoute :one
+INSERT INTO network_routes (short_name, long_name, mode, shape, wheelchair_accessible)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateNetworkRoute :one
+UPDATE network_routes
+SET short_name = $2, long_name = $3, mode = $4, shape = $5, wheelchair_accessible = $6, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteNetworkRoute :execrows
+DELETE FROM network_routes
+WHERE id = $1;
+
+-- name: ListNetworkTripPatterns :many
+SELECT * FROM network_trip_patterns
+ORDER BY id;
+
+-- name: GetNetworkTripPattern :one
+SELECT * FROM network_trip_patterns
+WHERE id = $1;
+
+-- name: CreateNetworkTripPattern :one
+INSERT INTO network_trip_patterns (route_id, headsign, stop_ids)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: UpdateNetworkTripPattern :one
+UPDATE network_trip_patterns
+SET route_id = $2, headsign = $3, stop_ids = $4, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteNetworkTripPattern :execrows
+DELETE FROM network_trip_patterns
+WHERE id = $1;
+
+-- name: CountMissingNetworkStops :one
+SELECT count(*) FROM unnest(sqlc.arg(stop_ids)::BIGINT[]) AS s(id)
+WHERE NOT EXISTS (SELECT 1 FROM network_stops WHERE network_stops.id = s.id);
+
+-- name: ListNetworkFares :many
+SELECT * FROM network_fares
+ORDER BY id;
+
+-- name: GetNetworkFare :one
+SELECT * FROM network_fares
+WHERE id = $1;
+
+-- name: CreateNetworkFare :one
+INSERT INTO network_fares (route_id, amount, kind, mode, bands, from_zone, to_zone, percent, within_minutes, rider_category)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: UpdateNetworkFare :one
+UPDATE network_fares
+SET route_id = $2, amount = $3, kind = $4, mode = $5, bands = $6, from_zone = $7, to_zone = $8,
+    percent = $9, within_minutes = $10, rider_category = $11, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteNetworkFare :execrows
+DELETE FROM network_fares
+WHERE id = $1;
+
+-- name: CreateNetworkVersion :one
+INSERT INTO network_versions (change_request_id, created_by)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetLatestNetworkVersion :one
+SELECT * FROM network_versions
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: CreateChangeRequest :one
+INSERT INTO change_requests (entity, action, entity_id, payload, submitted_by, submission_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetChangeRequest :one
+SELECT * FROM change_requests
+WHERE id = $1;
+
+-- name: GetChangeRequestForUpdate :one
+SELECT * FROM change_requests
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListChangeRequests :many
+SELECT * FROM change_requests
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: ReviewChangeRequest :one
+UPDATE change_requests
+SET status = $2, reviewed_by = $3, reviewed_at = now(), network_version = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateChangeRequestComment :one
+INSERT INTO change_request_comments (change_request_id, user_id, body)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListChangeRequestComments :many
+SELECT * FROM change_request_comments
+WHERE change_request_id = $1
+ORDER BY created_at;
+
+-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (actor_id, action, entity, entity_id, change_request_id, before, after)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListAuditEntries :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg(entity)::TEXT IS NULL OR entity = sqlc.narg(entity))
+  AND (sqlc.narg(entity_id)::BIGINT IS NULL OR entity_id = sqlc.narg(entity_id))
+ORDER BY created_at DESC
+LIMIT $1;
+
+-- name: CountTripPatternsUsingStop :one
+SELECT count(*) FROM network_trip_patterns
+WHERE sqlc.arg(stop_id)::BIGINT = ANY (stop_ids);
+
+-- name: ListNetworkStopsInBox :many
+SELECT * FROM network_stops
+WHERE lat BETWEEN sqlc.arg(min_lat)::float8 AND sqlc.arg(max_lat)::float8
+  AND lon BETWEEN sqlc.arg(min_lon)::float8 AND sqlc.arg(max_lon)::float8;
+
+-- name: ReplaceStopInTripPatterns :execrows
+-- Repoints patterns at the new stop, collapsing the repeats left where the
+-- old and new stops were consecutive
+UPDATE network_trip_patterns p
+SET stop_ids = (
+    SELECT COALESCE(array_agg(r.stop_id ORDER BY r.pos), '{}')::BIGINT[]
+    FROM (
+        SELECT t.stop_id, t.pos, lag(t.stop_id) OVER (ORDER BY t.pos) AS prev_id
+        FROM unnest(array_replace(p.stop_ids, sqlc.arg(old_stop_id)::BIGINT, sqlc.arg(new_stop_id)::BIGINT)) WITH ORDINALITY AS t(stop_id, pos)
+    ) r
+    WHERE r.prev_id IS DISTINCT FROM r.stop_id
+), updated_at = now()
+WHERE sqlc.arg(old_stop_id)::BIGINT = ANY (p.stop_ids);
+
+-- name: CreateNotificationSubscription :one
+-- An unconfirmed target only gets a new confirmation token once the last
+-- one was sent before resend_before
+INSERT INTO notification_subscriptions AS s (user_id, channel, target, p256dh, auth, confirm_token_hash, confirmed_at, confirm_sent_at)
+VALUES (@user_id, @channel, @target, @p256dh, @auth, @confirm_token_hash, @confirmed_at, @confirm_sent_at)
+ON CONFLICT (user_id, channel, target) DO UPDATE
+SET p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth,
+    confirm_token_hash = CASE WHEN s.confirmed_at IS NULL AND (s.confirm_sent_at IS NULL OR s.confirm_sent_at < sqlc.arg(resend_before)::timestamptz)
+        THEN EXCLUDED.confirm_token_hash ELSE s.confirm_token_hash END,
+    confirm_sent_at = CASE WHEN s.confirmed_at IS NULL AND (s.confirm_sent_at IS NULL OR s.confirm_sent_at < sqlc.arg(resend_before)::timestamptz)
+        THEN EXCLUDED.confirm_sent_at ELSE s.confirm_sent_at END,
+    confirmed_at = COALESCE(s.confirmed_at, EXCLUDED.confirmed_at)
+RETURNING *;
+
+-- name: CountUnconfirmedNotificationSubscriptions :one
+-- Counts the user's unconfirmed targets other than target
+SELECT count(*) FROM notification_subscriptions
+WHERE user_id = $1 AND confirmed_at IS NULL AND target <> $2;
+
+-- name: ConfirmNotificationSubscription :execrows
+UPDATE notification_subscriptions
+SET confirmed_at = now(), confirm_token_hash = ''
+WHERE confirm_token_hash = $1 AND confirm_token_hash <> '' AND confirmed_at IS NULL;
+
+-- name: DeleteUnconfirmedNotificationSubscriptions :execrows
+DELETE FROM notification_subscriptions
+WHERE confirmed_at IS NULL AND created_at < sqlc.arg(before)::timestamptz;
+
+-- name: ListNotificationSubscriptions :many
+SELECT * FROM notification_subscriptions
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteNotificationSubscription :execrows
+DELETE FROM notification_subscriptions
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteNotificationSubscriptionByTarget :exec
+DELETE FROM notification_subscriptions
+WHERE channel = $1 AND target = $2;
+
+-- name: CreateTripReminder :one
+INSERT INTO trip_reminders (user_id, journey, depart_at, lead_minutes, watch_alerts, locale)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListTripReminders :many
+SELECT * FROM trip_reminders
+WHERE user_id = $1 AND depart_at > now() - interval '1 hour'
+ORDER BY depart_at;
+
+-- name: GetTripReminder :one
+SELECT * FROM trip_reminders
+WHERE id = $1;
+
+-- name: DeleteTripReminder :execrows
+DELETE FROM trip_reminders
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListWatchedTripReminders :many
+SELECT * FROM trip_reminders
+WHERE watch_alerts AND depart_at > now() AND depart_at <= sqlc.arg(until)::timestamptz
+ORDER BY depart_at;
+
+-- name: DeleteTripRemindersBefore :execrows
+DELETE FROM trip_reminders
+WHERE depart_at < sqlc.arg(before)::timestamptz;
+
+-- name: EnqueueNotificationJob :execrows
+INSERT INTO notification_jobs (user_id, reminder_id, kind, dedupe_key, payload, run_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (dedupe_key) DO NOTHING;
+
+-- name: ClaimNotificationJobs :many
+UPDATE notification_jobs
+SET status = 'running', attempts = attempts + 1, locked_until = sqlc.arg(locked_until)::timestamptz
+WHERE id IN (
+    SELECT id FROM notification_jobs
+    WHERE (status = 'pending' AND run_at <= now())
+       OR (status = 'running' AND locked_until < now())
+    ORDER BY run_at
+    LIMIT sqlc.arg(batch)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteNotificationJob :exec
+UPDATE notification_jobs
+SET status = 'sent', sent_at = now(), locked_until = NULL, last_error = ''
+WHERE id = $1;
+
+-- name: RetryNotificationJob :exec
+UPDATE notification_jobs
+SET status = 'pending', run_at = sqlc.arg(run_at), locked_until = NULL, last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id);
+
+-- name: ReleaseNotificationJob :exec
+UPDATE notification_jobs
+SET status = 'pending', attempts = attempts - 1, locked_until = NULL
+WHERE id = $1 AND status = 'running';
+
+-- name: FailNotificationJob :exec
+UPDATE notification_jobs
+SET status = 'failed', locked_until = NULL, last_error = $2
+WHERE id = $1;
+
+-- name: DeleteFinishedNotificationJobs :execrows
+DELETE FROM notification_jobs
+WHERE status IN ('sent', 'failed') AND created_at < sqlc.arg(before)::timestamptz;
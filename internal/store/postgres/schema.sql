@@ -0,0 +1,313 @@
+-- Shared journeys (short links)
+CREATE TABLE IF NOT EXISTS shared_journeys (
+    id          TEXT PRIMARY KEY,
+    journey     JSONB       NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS shared_journeys_expires_at_idx ON shared_journeys (expires_at);
+
+-- Users
+CREATE TABLE IF NOT EXISTS users (
+    id             BIGSERIAL PRIMARY KEY,
+    email          TEXT        NOT NULL UNIQUE,
+    password_hash  TEXT        NOT NULL,
+    display_name   TEXT        NOT NULL DEFAULT '',
+    role           TEXT        NOT NULL DEFAULT 'user',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Refresh tokens are identified by the JWT ID so they can be rotated and revoked
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id          TEXT PRIMARY KEY,
+    user_id     BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+
+-- API keys for third-party integrators, only the SHA-256 of the key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id                     BIGSERIAL PRIMARY KEY,
+    name                   TEXT        NOT NULL,
+    owner_id               BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    prefix                 TEXT        NOT NULL,
+    key_hash               TEXT        NOT NULL UNIQUE,
+    scopes                 TEXT[]      NOT NULL DEFAULT '{}',
+    daily_quota            INTEGER     NOT NULL DEFAULT 0,
+    rate_limit_per_minute  INTEGER     NOT NULL DEFAULT 0,
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT now(),
+    rotated_at             TIMESTAMPTZ,
+    revoked_at             TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    key_id         BIGINT  NOT NULL REFERENCES api_keys (id) ON DELETE CASCADE,
+    day            DATE    NOT NULL,
+    request_count  INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
+
+-- Token buckets for the distributed rate limiter
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key         TEXT PRIMARY KEY,
+    tokens      DOUBLE PRECISION NOT NULL,
+    allowed     BOOLEAN          NOT NULL,
+    updated_at  TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+-- Favorite places per user (home, work, university, ...)
+CREATE TABLE IF NOT EXISTS places (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT           NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name        TEXT             NOT NULL,
+    kind        TEXT             NOT NULL DEFAULT 'other',
+    lat         DOUBLE PRECISION NOT NULL,
+    lon         DOUBLE PRECISION NOT NULL,
+    created_at  TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name)
+);
+
+-- Commute profiles hold default routing options, weights is a RoutingWeights JSON object
+CREATE TABLE IF NOT EXISTS commute_profiles (
+    id                BIGSERIAL PRIMARY KEY,
+    user_id           BIGINT           NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name              TEXT             NOT NULL,
+    restricted_modes  TEXT[]           NOT NULL DEFAULT '{}',
+    weights           JSONB,
+    walking_cutoff    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    max_transfers     INTEGER          NOT NULL DEFAULT 0,
+    created_at        TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name)
+);
+
+-- Journeys users picked out of a route response, with the alternatives they were shown
+CREATE TABLE IF NOT EXISTS trip_selections (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    selected      JSONB       NOT NULL,
+    alternatives  JSONB       NOT NULL DEFAULT '[]',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS trip_selections_user_id_idx ON trip_selections (user_id, created_at DESC);
+
+-- Ranking weights learned from each user's selections
+CREATE TABLE IF NOT EXISTS user_ranking_weights (
+    user_id     BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    time        DOUBLE PRECISION NOT NULL,
+    cost        DOUBLE PRECISION NOT NULL,
+    walk        DOUBLE PRECISION NOT NULL,
+    transfer    DOUBLE PRECISION NOT NULL,
+    samples     INTEGER          NOT NULL DEFAULT 0,
+    updated_at  TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+-- Service alerts published by admins, header and description map language codes to text
+CREATE TABLE IF NOT EXISTS service_alerts (
+    id              BIGSERIAL PRIMARY KEY,
+    cause           TEXT        NOT NULL DEFAULT 'UNKNOWN_CAUSE',
+    effect          TEXT        NOT NULL DEFAULT 'UNKNOWN_EFFECT',
+    severity        TEXT        NOT NULL DEFAULT 'UNKNOWN_SEVERITY',
+    header          JSONB       NOT NULL,
+    description     JSONB       NOT NULL DEFAULT '{}',
+    routes          TEXT[]      NOT NULL DEFAULT '{}',
+    trip_ids        TEXT[]      NOT NULL DEFAULT '{}',
+    stop_ids        INTEGER[]   NOT NULL DEFAULT '{}',
+    active_periods  JSONB       NOT NULL DEFAULT '[]',
+    created_by      BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Route shapes recorded by riders and field staff, held for moderation
+CREATE TABLE IF NOT EXISTS route_submissions (
+    id                    BIGSERIAL PRIMARY KEY,
+    submitted_by          BIGINT           REFERENCES users (id) ON DELETE SET NULL,
+    name                  TEXT             NOT NULL,
+    mode                  TEXT             NOT NULL DEFAULT 'microbus',
+    fare                  DOUBLE PRECISION,
+    origin_terminal       TEXT             NOT NULL,
+    destination_terminal  TEXT             NOT NULL,
+    shape                 JSONB            NOT NULL,
+    raw_points            INTEGER          NOT NULL,
+    length_meters         DOUBLE PRECISION NOT NULL,
+    status                TEXT             NOT NULL DEFAULT 'pending',
+    -- Share of the trace's fixes snapped onto the street network, 0 when none is loaded
+    matched_ratio         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at            TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS route_submissions_submitted_by_idx ON route_submissions (submitted_by, created_at DESC);
+
+-- Transit network edited through the moderation queue
+CREATE TABLE IF NOT EXISTS network_stops (
+    id                   BIGSERIAL PRIMARY KEY,
+    name                 TEXT             NOT NULL,
+    lat                  DOUBLE PRECISION NOT NULL,
+    lon                  DOUBLE PRECISION NOT NULL,
+    -- Zones drive zone-based fares
+    zone                 TEXT             NOT NULL DEFAULT '',
+    -- Accessibility attributes, wheelchair values follow GTFS: 0 unknown, 1 yes, 2 no
+    wheelchair_boarding  SMALLINT         NOT NULL DEFAULT 0,
+    has_stairs           BOOLEAN          NOT NULL DEFAULT false,
+    has_overpass         BOOLEAN          NOT NULL DEFAULT false,
+    created_at           TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    updated_at           TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS network_routes (
+    id                     BIGSERIAL PRIMARY KEY,
+    short_name             TEXT        NOT NULL,
+    long_name              TEXT        NOT NULL DEFAULT '',
+    mode                   TEXT        NOT NULL,
+    shape                  JSONB       NOT NULL DEFAULT '[]',
+    -- Follows GTFS: 0 unknown, 1 yes, 2 no
+    wheelchair_accessible  SMALLINT    NOT NULL DEFAULT 0,
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at             TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS network_trip_patterns (
+    id          BIGSERIAL PRIMARY KEY,
+    route_id    BIGINT      NOT NULL REFERENCES network_routes (id) ON DELETE CASCADE,
+    headsign    TEXT        NOT NULL,
+    stop_ids    BIGINT[]    NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS network_trip_patterns_route_id_idx ON network_trip_patterns (route_id);
+
+-- Fares are rules of a kind: flat, distance, zone, transfer or concession.
+-- A rule without a route applies to every route, or to every route of mode
+CREATE TABLE IF NOT EXISTS network_fares (
+    id              BIGSERIAL PRIMARY KEY,
+    route_id        BIGINT           REFERENCES network_routes (id) ON DELETE CASCADE,
+    amount          DOUBLE PRECISION NOT NULL,
+    kind            TEXT             NOT NULL DEFAULT 'flat',
+    mode            TEXT             NOT NULL DEFAULT '',
+    bands           JSONB            NOT NULL DEFAULT '[]',
+    from_zone       TEXT             NOT NULL DEFAULT '',
+    to_zone         TEXT             NOT NULL DEFAULT '',
+    percent         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    within_minutes  INTEGER          NOT NULL DEFAULT 0,
+    rider_category  TEXT             NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS network_fares_route_id_idx ON network_fares (route_id);
+
+-- Every approved change produces a new network version
+CREATE TABLE IF NOT EXISTS network_versions (
+    version            BIGSERIAL PRIMARY KEY,
+    change_request_id  BIGINT,
+    created_by         BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Proposed edits to the network, payload holds the proposed fields
+CREATE TABLE IF NOT EXISTS change_requests (
+    id               BIGSERIAL PRIMARY KEY,
+    entity           TEXT        NOT NULL,
+    action           TEXT        NOT NULL,
+    entity_id        BIGINT,
+    payload          JSONB       NOT NULL DEFAULT '{}',
+    status           TEXT        NOT NULL DEFAULT 'pending',
+    submitted_by     BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    submission_id    BIGINT      REFERENCES route_submissions (id) ON DELETE SET NULL,
+    reviewed_by      BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_at      TIMESTAMPTZ,
+    network_version  BIGINT,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS change_requests_status_idx ON change_requests (status, created_at);
+
+CREATE TABLE IF NOT EXISTS change_request_comments (
+    id                 BIGSERIAL PRIMARY KEY,
+    change_request_id  BIGINT      NOT NULL REFERENCES change_requests (id) ON DELETE CASCADE,
+    user_id            BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    body               TEXT        NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS change_request_comments_change_request_id_idx ON change_request_comments (change_request_id, created_at);
+
+-- Audit trail of every network and moderation action
+CREATE TABLE IF NOT EXISTS audit_log (
+    id                 BIGSERIAL PRIMARY KEY,
+    actor_id           BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    action             TEXT        NOT NULL,
+    entity             TEXT        NOT NULL,
+    entity_id          BIGINT,
+    change_request_id  BIGINT,
+    before             JSONB,
+    after              JSONB,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity, entity_id, created_at DESC);
+
+-- Where a user's notifications are delivered: a web push endpoint, an FCM
+-- token or an email address
+CREATE TABLE IF NOT EXISTS notification_subscriptions (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    channel             TEXT        NOT NULL,
+    target              TEXT        NOT NULL,
+    p256dh              TEXT        NOT NULL DEFAULT '',
+    auth                TEXT        NOT NULL DEFAULT '',
+    -- Email targets receive nothing until the link sent to them is opened,
+    -- the SHA-256 of its token is kept until then
+    confirm_token_hash  TEXT        NOT NULL DEFAULT '',
+    confirmed_at        TIMESTAMPTZ,
+    confirm_sent_at     TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, channel, target)
+);
+
+CREATE INDEX IF NOT EXISTS notification_subscriptions_confirm_idx ON notification_subscriptions (confirm_token_hash) WHERE confirmed_at IS NULL;
+
+-- Departure reminders for a saved journey, optionally watching its routes for alerts
+CREATE TABLE IF NOT EXISTS trip_reminders (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    journey       JSONB       NOT NULL,
+    depart_at     TIMESTAMPTZ NOT NULL,
+    lead_minutes  INTEGER     NOT NULL,
+    watch_alerts  BOOLEAN     NOT NULL DEFAULT true,
+    -- Language tag the notifications are written in
+    locale        TEXT        NOT NULL DEFAULT 'en',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS trip_reminders_user_idx ON trip_reminders (user_id, depart_at);
+CREATE INDEX IF NOT EXISTS trip_reminders_depart_idx ON trip_reminders (depart_at) WHERE watch_alerts;
+
+-- Persistent notification queue. Workers claim due jobs with SKIP LOCKED and
+-- hold them until locked_until, a crashed worker's jobs are claimed again after
+CREATE TABLE IF NOT EXISTS notification_jobs (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    reminder_id   BIGINT      REFERENCES trip_reminders (id) ON DELETE CASCADE,
+    kind          TEXT        NOT NULL,
+    dedupe_key    TEXT        UNIQUE,
+    payload       JSONB       NOT NULL,
+    status        TEXT        NOT NULL DEFAULT 'pending',
+    run_at        TIMESTAMPTZ NOT NULL,
+    attempts      INTEGER     NOT NULL DEFAULT 0,
+    locked_until  TIMESTAMPTZ,
+    last_error    TEXT        NOT NULL DEFAULT '',
+    sent_at       TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notification_jobs_due_idx ON notification_jobs (run_at) WHERE status IN ('pending', 'running');
@@ -0,0 +1,265 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+
+package database
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ApiKey struct {
+	ID                 int64
+	Name               string
+	OwnerID            pgtype.Int8
+	Prefix             string
+	KeyHash            string
+	Scopes             []string
+	DailyQuota         int32
+	RateLimitPerMinute int32
+	CreatedAt          pgtype.Timestamptz
+	RotatedAt          pgtype.Timestamptz
+	RevokedAt          pgtype.Timestamptz
+}
+
+type ApiKeyUsage struct {
+	KeyID        int64
+	Day          pgtype.Date
+	RequestCount int32
+}
+
+type AuditLog struct {
+	ID              int64
+	ActorID         pgtype.Int8
+	Action          string
+	Entity          string
+	EntityID        pgtype.Int8
+	ChangeRequestID pgtype.Int8
+	Before          []byte
+	After           []byte
+	CreatedAt       pgtype.Timestamptz
+}
+
+type ChangeRequest struct {
+	ID             int64
+	Entity         string
+	Action         string
+	EntityID       pgtype.Int8
+	Payload        []byte
+	Status         string
+	SubmittedBy    pgtype.Int8
+	SubmissionID   pgtype.Int8
+	ReviewedBy     pgtype.Int8
+	ReviewedAt     pgtype.Timestamptz
+	NetworkVersion pgtype.Int8
+	CreatedAt      pgtype.Timestamptz
+}
+
+type ChangeRequestComment struct {
+	ID              int64
+	ChangeRequestID int64
+	UserID          pgtype.Int8
+	Body            string
+	CreatedAt       pgtype.Timestamptz
+}
+
+type CommuteProfile struct {
+	ID              int64
+	UserID          int64
+	Name            string
+	RestrictedModes []string
+	Weights         []byte
+	WalkingCutoff   float64
+	MaxTransfers    int32
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
+type NetworkFare struct {
+	ID            int64
+	RouteID       pgtype.Int8
+	Amount        float64
+	Kind          string
+	Mode          string
+	Bands         []byte
+	FromZone      string
+	ToZone        string
+	Percent       float64
+	WithinMinutes int32
+	RiderCategory string
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type NetworkRoute struct {
+	ID                   int64
+	ShortName            string
+	LongName             string
+	Mode                 string
+	Shape                []byte
+	WheelchairAccessible int16
+	CreatedAt            pgtype.Timestamptz
+	UpdatedAt            pgtype.Timestamptz
+}
+
+type NetworkStop struct {
+	ID                 int64
+	Name               string
+	Lat                float64
+	Lon                float64
+	Zone               string
+	WheelchairBoarding int16
+	HasStairs          bool
+	HasOverpass        bool
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+}
+
+type NetworkTripPattern struct {
+	ID        int64
+	RouteID   int64
+	Headsign  string
+	StopIds   []int64
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type NetworkVersion struct {
+	Version         int64
+	ChangeRequestID pgtype.Int8
+	CreatedBy       pgtype.Int8
+	CreatedAt       pgtype.Timestamptz
+}
+
+type NotificationJob struct {
+	ID          int64
+	UserID      int64
+	ReminderID  pgtype.Int8
+	Kind        string
+	DedupeKey   pgtype.Text
+	Payload     []byte
+	Status      string
+	RunAt       pgtype.Timestamptz
+	Attempts    int32
+	LockedUntil pgtype.Timestamptz
+	LastError   string
+	SentAt      pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+}
+
+type NotificationSubscription struct {
+	ID               int64
+	UserID           int64
+	Channel          string
+	Target           string
+	P256dh           string
+	Auth             string
+	ConfirmTokenHash string
+	ConfirmedAt      pgtype.Timestamptz
+	ConfirmSentAt    pgtype.Timestamptz
+	CreatedAt        pgtype.Timestamptz
+}
+
+type Place struct {
+	ID        int64
+	UserID    int64
+	Name      string
+	Kind      string
+	Lat       float64
+	Lon       float64
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type RateLimitBucket struct {
+	Key       string
+	Tokens    float64
+	Allowed   bool
+	UpdatedAt pgtype.Timestamptz
+}
+
+type RefreshToken struct {
+	ID        string
+	UserID    int64
+	ExpiresAt pgtype.Timestamptz
+	RevokedAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type RouteSubmission struct {
+	ID                  int64
+	SubmittedBy         pgtype.Int8
+	Name                string
+	Mode                string
+	Fare                pgtype.Float8
+	OriginTerminal      string
+	DestinationTerminal string
+	Shape               []byte
+	RawPoints           int32
+	LengthMeters        float64
+	Status              string
+	MatchedRatio        float64
+	CreatedAt           pgtype.Timestamptz
+}
+
+type ServiceAlert struct {
+	ID            int64
+	Cause         string
+	Effect        string
+	Severity      string
+	Header        []byte
+	Description   []byte
+	Routes        []string
+	TripIds       []string
+	StopIds       []int32
+	ActivePeriods []byte
+	CreatedBy     pgtype.Int8
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type SharedJourney struct {
+	ID        string
+	Journey   []byte
+	CreatedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+type TripReminder struct {
+	ID          int64
+	UserID      int64
+	Journey     []byte
+	DepartAt    pgtype.Timestamptz
+	LeadMinutes int32
+	WatchAlerts bool
+	Locale      string
+	CreatedAt   pgtype.Timestamptz
+}
+
+type TripSelection struct {
+	ID           int64
+	UserID       int64
+	Selected     []byte
+	Alternatives []byte
+	CreatedAt    pgtype.Timestamptz
+}
+
+type User struct {
+	ID           int64
+	Email        string
+	PasswordHash string
+	DisplayName  string
+	Role         string
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+}
+
+type UserRankingWeight struct {
+	UserID    int64
+	Time      float64
+	Cost      float64
+	Walk      float64
+	Transfer  float64
+	Samples   int32
+	UpdatedAt pgtype.Timestamptz
+}
@@ -0,0 +1,2788 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: queries.sql
+
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimNotificationJobs = `-- name: ClaimNotificationJobs :many
+UPDATE notification_jobs
+SET status = 'running', attempts = attempts + 1, locked_until = $1::timestamptz
+WHERE id IN (
+    SELECT id FROM notification_jobs
+    WHERE (status = 'pending' AND run_at <= now())
+       OR (status = 'running' AND locked_until < now())
+    ORDER BY run_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, user_id, reminder_id, kind, dedupe_key, payload, status, run_at, attempts, locked_until, last_error, sent_at, created_at
+`
+
+type ClaimNotificationJobsParams struct {
+	LockedUntil pgtype.Timestamptz
+	Batch       int32
+}
+
+func (q *Queries) ClaimNotificationJobs(ctx context.Context, arg ClaimNotificationJobsParams) ([]NotificationJob, error) {
+	rows, err := q.db.Query(ctx, claimNotificationJobs, arg.LockedUntil, arg.Batch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationJob
+	for rows.Next() {
+		var i NotificationJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ReminderID,
+			&i.Kind,
+			&i.DedupeKey,
+			&i.Payload,
+			&i.Status,
+			&i.RunAt,
+			&i.Attempts,
+			&i.LockedUntil,
+			&i.LastError,
+			&i.SentAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeNotificationJob = `-- name: CompleteNotificationJob :exec
+UPDATE notification_jobs
+SET status = 'sent', sent_at = now(), locked_until = NULL, last_error = ''
+WHERE id = $1
+`
+
+func (q *Queries) CompleteNotificationJob(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, completeNotificationJob, id)
+	return err
+}
+
+const confirmNotificationSubscription = `-- name: ConfirmNotificationSubscription :execrows
+UPDATE notification_subscriptions
+SET confirmed_at = now(), confirm_token_hash = ''
+WHERE confirm_token_hash = $1 AND confirm_token_hash <> '' AND confirmed_at IS NULL
+`
+
+func (q *Queries) ConfirmNotificationSubscription(ctx context.Context, confirmTokenHash string) (int64, error) {
+	result, err := q.db.Exec(ctx, confirmNotificationSubscription, confirmTokenHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countMissingNetworkStops = `-- name: CountMissingNetworkStops :one
+SELECT count(*) FROM unnest($1::BIGINT[]) AS s(id)
+WHERE NOT EXISTS (SELECT 1 FROM network_stops WHERE network_stops.id = s.id)
+`
+
+func (q *Queries) CountMissingNetworkStops(ctx context.Context, stopIds []int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countMissingNetworkStops, stopIds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTripPatternsUsingStop = `-- name: CountTripPatternsUsingStop :one
+SELECT count(*) FROM network_trip_patterns
+WHERE $1::BIGINT = ANY (stop_ids)
+`
+
+func (q *Queries) CountTripPatternsUsingStop(ctx context.Context, stopID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countTripPatternsUsingStop, stopID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUnconfirmedNotificationSubscriptions = `-- name: CountUnconfirmedNotificationSubscriptions :one
+SELECT count(*) FROM notification_subscriptions
+WHERE user_id = $1 AND confirmed_at IS NULL AND target <> $2
+`
+
+type CountUnconfirmedNotificationSubscriptionsParams struct {
+	UserID int64
+	Target string
+}
+
+// Counts the user's unconfirmed targets other than target
+func (q *Queries) CountUnconfirmedNotificationSubscriptions(ctx context.Context, arg CountUnconfirmedNotificationSubscriptionsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnconfirmedNotificationSubscriptions, arg.UserID, arg.Target)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, owner_id, prefix, key_hash, scopes, daily_quota, rate_limit_per_minute)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, name, owner_id, prefix, key_hash, scopes, daily_quota, rate_limit_per_minute, created_at, rotated_at, revoked_at
+`
+
+type CreateAPIKeyParams struct {
+	Name               string
+	OwnerID            pgtype.Int8
+	Prefix             string
+	KeyHash            string
+	Scopes             []string
+	DailyQuota         int32
+	RateLimitPerMinute int32
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, createAPIKey,
+		arg.Name,
+		arg.OwnerID,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Scopes,
+		arg.DailyQuota,
+		arg.RateLimitPerMinute,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.DailyQuota,
+		&i.RateLimitPerMinute,
+		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const createAuditEntry = `-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (actor_id, action, entity, entity_id, change_request_id, before, after)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type CreateAuditEntryParams struct {
+	ActorID         pgtype.Int8
+	Action          string
+	Entity          string
+	EntityID        pgtype.Int8
+	ChangeRequestID pgtype.Int8
+	Before          []byte
+	After           []byte
+}
+
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) error {
+	_, err := q.db.Exec(ctx, createAuditEntry,
+		arg.ActorID,
+		arg.Action,
+		arg.Entity,
+		arg.EntityID,
+		arg.ChangeRequestID,
+		arg.Before,
+		arg.After,
+	)
+	return err
+}
+
+const createChangeRequest = `-- name: CreateChangeRequest :one
+INSERT INTO change_requests (entity, action, entity_id, payload, submitted_by, submission_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, entity, action, entity_id, payload, status, submitted_by, submission_id, reviewed_by, reviewed_at, network_version, created_at
+`
+
+type CreateChangeRequestParams struct {
+	Entity       string
+	Action       string
+	EntityID     pgtype.Int8
+	Payload      []byte
+	SubmittedBy  pgtype.Int8
+	SubmissionID pgtype.Int8
+}
+
+func (q *Queries) CreateChangeRequest(ctx context.Context, arg CreateChangeRequestParams) (ChangeRequest, error) {
+	row := q.db.QueryRow(ctx, createChangeRequest,
+		arg.Entity,
+		arg.Action,
+		arg.EntityID,
+		arg.Payload,
+		arg.SubmittedBy,
+		arg.SubmissionID,
+	)
+	var i ChangeRequest
+	err := row.Scan(
+		&i.ID,
+		&i.Entity,
+		&i.Action,
+		&i.EntityID,
+		&i.Payload,
+		&i.Status,
+		&i.SubmittedBy,
+		&i.SubmissionID,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.NetworkVersion,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createChangeRequestComment = `-- name: CreateChangeRequestComment :one
+INSERT INTO change_request_comments (change_request_id, user_id, body)
+VALUES ($1, $2, $3)
+RETURNING id, change_request_id, user_id, body, created_at
+`
+
+type CreateChangeRequestCommentParams struct {
+	ChangeRequestID int64
+	UserID          pgtype.Int8
+	Body            string
+}
+
+func (q *Queries) CreateChangeRequestComment(ctx context.Context, arg CreateChangeRequestCommentParams) (ChangeRequestComment, error) {
+	row := q.db.QueryRow(ctx, createChangeRequestComment, arg.ChangeRequestID, arg.UserID, arg.Body)
+	var i ChangeRequestComment
+	err := row.Scan(
+		&i.ID,
+		&i.ChangeRequestID,
+		&i.UserID,
+		&i.Body,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createCommuteProfile = `-- name: CreateCommuteProfile :one
+INSERT INTO commute_profiles (user_id, name, restricted_modes, weights, walking_cutoff, max_transfers)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, name, restricted_modes, weights, walking_cutoff, max_transfers, created_at, updated_at
+`
+
+type CreateCommuteProfileParams struct {
+	UserID          int64
+	Name            string
+	RestrictedModes []string
+	Weights         []byte
+	WalkingCutoff   float64
+	MaxTransfers    int32
+}
+
+func (q *Queries) CreateCommuteProfile(ctx context.Context, arg CreateCommuteProfileParams) (CommuteProfile, error) {
+	row := q.db.QueryRow(ctx, createCommuteProfile,
+		arg.UserID,
+		arg.Name,
+		arg.RestrictedModes,
+		arg.Weights,
+		arg.WalkingCutoff,
+		arg.MaxTransfers,
+	)
+	var i CommuteProfile
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.RestrictedModes,
+		&i.Weights,
+		&i.WalkingCutoff,
+		&i.MaxTransfers,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createNetworkFare = `-- name: CreateNetworkFare :one
+INSERT INTO network_fares (route_id, amount, kind, mode, bands, from_zone, to_zone, percent, within_minutes, rider_category)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, route_id, amount, kind, mode, bands, from_zone, to_zone, percent, within_minutes, rider_category, created_at, updated_at
+`
+
+type CreateNetworkFareParams struct {
+	RouteID       pgtype.Int8
+	Amount        float64
+	Kind          string
+	Mode          string
+	Bands         []byte
+	FromZone      string
+	ToZone        string
+	Percent       float64
+	WithinMinutes int32
+	RiderCategory string
+}
+
+func (q *Queries) CreateNetworkFare(ctx context.Context, arg CreateNetworkFareParams) (NetworkFare, error) {
+	row := q.db.QueryRow(ctx, createNetworkFare,
+		arg.RouteID,
+		arg.Amount,
+		arg.Kind,
+		arg.Mode,
+		arg.Bands,
+		arg.FromZone,
+		arg.ToZone,
+		arg.Percent,
+		arg.WithinMinutes,
+		arg.RiderCategory,
+	)
+	var i NetworkFare
+	err := row.Scan(
+		&i.ID,
+		&i.RouteID,
+		&i.Amount,
+		&i.Kind,
+		&i.Mode,
+		&i.Bands,
+		&i.FromZone,
+		&i.ToZone,
+		&i.Percent,
+		&i.WithinMinutes,
+		&i.RiderCategory,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createNetworkRoute = `-- name: CreateNetworkRoute :one
+INSERT INTO network_routes (short_name, long_name, mode, shape, wheelchair_accessible)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, short_name, long_name, mode, shape, wheelchair_accessible, created_at, updated_at
+`
+
+type CreateNetworkRouteParams struct {
+	ShortName            string
+	LongName             string
+	Mode                 string
+	Shape                []byte
+	WheelchairAccessible int16
+}
+
+func (q *Queries) CreateNetworkRoute(ctx context.Context, arg CreateNetworkRouteParams) (NetworkRoute, error) {
+	row := q.db.QueryRow(ctx, createNetworkRoute,
+		arg.ShortName,
+		arg.LongName,
+		arg.Mode,
+		arg.Shape,
+		arg.WheelchairAccessible,
+	)
+	var i NetworkRoute
+	err := row.Scan(
+		&i.ID,
+		&i.ShortName,
+		&i.LongName,
+		&i.Mode,
+		&i.Shape,
+		&i.WheelchairAccessible,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createNetworkStop = `-- name: CreateNetworkStop :one
+INSERT INTO network_stops (name, lat, lon, zone, wheelchair_boarding, has_stairs, has_overpass)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, name, lat, lon, zone, wheelchair_boarding, has_stairs, has_overpass, created_at, updated_at
+`
+
+type CreateNetworkStopParams struct {
+	Name               string
+	Lat                float64
+	Lon                float64
+	Zone               string
+	WheelchairBoarding int16
+	HasStairs          bool
+	HasOverpass        bool
+}
+
+func (q *Queries) CreateNetworkStop(ctx context.Context, arg CreateNetworkStopParams) (NetworkStop, error) {
+	row := q.db.QueryRow(ctx, createNetworkStop,
+		arg.Name,
+		arg.Lat,
+		arg.Lon,
+		arg.Zone,
+		arg.WheelchairBoarding,
+		arg.HasStairs,
+		arg.HasOverpass,
+	)
+	var i NetworkStop
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Lat,
+		&i.Lon,
+		&i.Zone,
+		&i.WheelchairBoarding,
+		&i.HasStairs,
+		&i.HasOverpass,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createNetworkTripPattern = `-- name: CreateNetworkTripPattern :one
+INSERT INTO network_trip_patterns (route_id, headsign, stop_ids)
+VALUES ($1, $2, $3)
+RETURNING id, route_id, headsign, stop_ids, created_at, updated_at
+`
+
+type CreateNetworkTripPatternParams struct {
+	RouteID  int64
+	Headsign string
+	StopIds  []int64
+}
+
+func (q *Queries) CreateNetworkTripPattern(ctx context.Context, arg CreateNetworkTripPatternParams) (NetworkTripPattern, error) {
+	row := q.db.QueryRow(ctx, createNetworkTripPattern, arg.RouteID, arg.Headsign, arg.StopIds)
+	var i NetworkTripPattern
+	err := row.Scan(
+		&i.ID,
+		&i.RouteID,
+		&i.Headsign,
+		&i.StopIds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createNetworkVersion = `-- name: CreateNetworkVersion :one
+INSERT INTO network_versions (change_request_id, created_by)
+VALUES ($1, $2)
+RETURNING version, change_request_id, created_by, created_at
+`
+
+type CreateNetworkVersionParams struct {
+	ChangeRequestID pgtype.Int8
+	CreatedBy       pgtype.Int8
+}
+
+func (q *Queries) CreateNetworkVersion(ctx context.Context, arg CreateNetworkVersionParams) (NetworkVersion, error) {
+	row := q.db.QueryRow(ctx, createNetworkVersion, arg.ChangeRequestID, arg.CreatedBy)
+	var i NetworkVersion
+	err := row.Scan(
+		&i.Version,
+		&i.ChangeRequestID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createNotificationSubscription = `-- name: CreateNotificationSubscription :one
+INSERT INTO notification_subscriptions AS s (user_id, channel, target, p256dh, auth, confirm_token_hash, confirmed_at, confirm_sent_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (user_id, channel, target) DO UPDATE
+SET p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth,
+    confirm_token_hash = CASE WHEN s.confirmed_at IS NULL AND (s.confirm_sent_at IS NULL OR s.confirm_sent_at < $9::timestamptz)
+        THEN EXCLUDED.confirm_token_hash ELSE s.confirm_token_hash END,
+    confirm_sent_at = CASE WHEN s.confirmed_at IS NULL AND (s.confirm_sent_at IS NULL OR s.confirm_sent_at < $9::timestamptz)
+        THEN EXCLUDED.confirm_sent_at ELSE s.confirm_sent_at END,
+    confirmed_at = COALESCE(s.confirmed_at, EXCLUDED.confirmed_at)
+RETURNING id, user_id, channel, target, p256dh, auth, confirm_token_hash, confirmed_at, confirm_sent_at, created_at
+`
+
+type CreateNotificationSubscriptionParams struct {
+	UserID           int64
+	Channel          string
+	Target           string
+	P256dh           string
+	Auth             string
+	ConfirmTokenHash string
+	ConfirmedAt      pgtype.Timestamptz
+	ConfirmSentAt    pgtype.Timestamptz
+	ResendBefore     pgtype.Timestamptz
+}
+
+// An unconfirmed target only gets a new confirmation token once the last
+// one was sent before resend_before
+func (q *Queries) CreateNotificationSubscription(ctx context.Context, arg CreateNotificationSubscriptionParams) (NotificationSubscription, error) {
+	row := q.db.QueryRow(ctx, createNotificationSubscription,
+		arg.UserID,
+		arg.Channel,
+		arg.Target,
+		arg.P256dh,
+		arg.Auth,
+		arg.ConfirmTokenHash,
+		arg.ConfirmedAt,
+		arg.ConfirmSentAt,
+		arg.ResendBefore,
+	)
+	var i NotificationSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Channel,
+		&i.Target,
+		&i.P256dh,
+		&i.Auth,
+		&i.ConfirmTokenHash,
+		&i.ConfirmedAt,
+		&i.ConfirmSentAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPlace = `-- name: CreatePlace :one
+INSERT INTO places (user_id, name, kind, lat, lon)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, name, kind, lat, lon, created_at, updated_at
+`
+
+type CreatePlaceParams struct {
+	UserID int64
+	Name   string
+	Kind   string
+	Lat    float64
+	Lon    float64
+}
+
+func (q *Queries) CreatePlace(ctx context.Context, arg CreatePlaceParams) (Place, error) {
+	row := q.db.QueryRow(ctx, createPlace,
+		arg.UserID,
+		arg.Name,
+		arg.Kind,
+		arg.Lat,
+		arg.Lon,
+	)
+	var i Place
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Kind,
+		&i.Lat,
+		&i.Lon,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createRefreshToken = `-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (id, user_id, expires_at)
+VALUES ($1, $2, $3)
+`
+
+type CreateRefreshTokenParams struct {
+	ID        string
+	UserID    int64
+	ExpiresAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) error {
+	_, err := q.db.Exec(ctx, createRefreshToken, arg.ID, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
+const createRouteSubmission = `-- name: CreateRouteSubmission :one
+INSERT INTO route_submissions (submitted_by, name, mode, fare, origin_terminal, destination_terminal, shape, raw_points, length_meters, matched_ratio)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, submitted_by, name, mode, fare, origin_terminal, destination_terminal, shape, raw_points, length_meters, status, matched_ratio, created_at
+`
+
+type CreateRouteSubmissionParams struct {
+	SubmittedBy         pgtype.Int8
+	Name                string
+	Mode                string
+	Fare                pgtype.Float8
+	OriginTerminal      string
+	DestinationTerminal string
+	Shape               []byte
+	RawPoints           int32
+	LengthMeters        float64
+	MatchedRatio        float64
+}
+
+func (q *Queries) CreateRouteSubmission(ctx context.Context, arg CreateRouteSubmissionParams) (RouteSubmission, error) {
+	row := q.db.QueryRow(ctx, createRouteSubmission,
+		arg.SubmittedBy,
+		arg.Name,
+		arg.Mode,
+		arg.Fare,
+		arg.OriginTerminal,
+		arg.DestinationTerminal,
+		arg.Shape,
+		arg.RawPoints,
+		arg.LengthMeters,
+		arg.MatchedRatio,
+	)
+	var i RouteSubmission
+	err := row.Scan(
+		&i.ID,
+		&i.SubmittedBy,
+		&i.Name,
+		&i.Mode,
+		&i.Fare,
+		&i.OriginTerminal,
+		&i.DestinationTerminal,
+		&i.Shape,
+		&i.RawPoints,
+		&i.LengthMeters,
+		&i.Status,
+		&i.MatchedRatio,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createServiceAlert = `-- name: CreateServiceAlert :one
+INSERT INTO service_alerts (cause, effect, severity, header, description, routes, trip_ids, stop_ids, active_periods, created_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, cause, effect, severity, header, description, routes, trip_ids, stop_ids, active_periods, created_by, created_at, updated_at
+`
+
+type CreateServiceAlertParams struct {
+	Cause         string
+	Effect        string
+	Severity      string
+	Header        []byte
+	Description   []byte
+	Routes        []string
+	TripIds       []string
+	StopIds       []int32
+	ActivePeriods []byte
+	CreatedBy     pgtype.Int8
+}
+
+func (q *Queries) CreateServiceAlert(ctx context.Context, arg CreateServiceAlertParams) (ServiceAlert, error) {
+	row := q.db.QueryRow(ctx, createServiceAlert,
+		arg.Cause,
+		arg.Effect,
+		arg.Severity,
+		arg.Header,
+		arg.Description,
+		arg.Routes,
+		arg.TripIds,
+		arg.StopIds,
+		arg.ActivePeriods,
+		arg.CreatedBy,
+	)
+	var i ServiceAlert
+	err := row.Scan(
+		&i.ID,
+		&i.Cause,
+		&i.Effect,
+		&i.Severity,
+		&i.Header,
+		&i.Description,
+		&i.Routes,
+		&i.TripIds,
+		&i.StopIds,
+		&i.ActivePeriods,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createSharedJourney = `-- name: CreateSharedJourney :one
+INSERT INTO shared_journeys (id, journey, expires_at)
+VALUES ($1, $2, $3)
+RETURNING id, journey, created_at, expires_at
+`
+
+type CreateSharedJourneyParams struct {
+	ID        string
+	Journey   []byte
+	ExpiresAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateSharedJourney(ctx context.Context, arg CreateSharedJourneyParams) (SharedJourney, error) {
+	row := q.db.QueryRow(ctx, createSharedJourney, arg.ID, arg.Journey, arg.ExpiresAt)
+	var i SharedJourney
+	err := row.Scan(
+		&i.ID,
+		&i.Journey,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const createTripReminder = `-- name: CreateTripReminder :one
+INSERT INTO trip_reminders (user_id, journey, depart_at, lead_minutes, watch_alerts, locale)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, journey, depart_at, lead_minutes, watch_alerts, locale, created_at
+`
+
+type CreateTripReminderParams struct {
+	UserID      int64
+	Journey     []byte
+	DepartAt    pgtype.Timestamptz
+	LeadMinutes int32
+	WatchAlerts bool
+	Locale      string
+}
+
+func (q *Queries) CreateTripReminder(ctx context.Context, arg CreateTripReminderParams) (TripReminder, error) {
+	row := q.db.QueryRow(ctx, createTripReminder,
+		arg.UserID,
+		arg.Journey,
+		arg.DepartAt,
+		arg.LeadMinutes,
+		arg.WatchAlerts,
+		arg.Locale,
+	)
+	var i TripReminder
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Journey,
+		&i.DepartAt,
+		&i.LeadMinutes,
+		&i.WatchAlerts,
+		&i.Locale,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTripSelection = `-- name: CreateTripSelection :one
+INSERT INTO trip_selections (user_id, selected, alternatives)
+VALUES ($1, $2, $3)
+RETURNING id, user_id, selected, alternatives, created_at
+`
+
+type CreateTripSelectionParams struct {
+	UserID       int64
+	Selected     []byte
+	Alternatives []byte
+}
+
+func (q *Queries) CreateTripSelection(ctx context.Context, arg CreateTripSelectionParams) (TripSelection, error) {
+	row := q.db.QueryRow(ctx, createTripSelection, arg.UserID, arg.Selected, arg.Alternatives)
+	var i TripSelection
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Selected,
+		&i.Alternatives,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (email, password_hash, display_name, role)
+VALUES ($1, $2, $3, $4)
+RETURNING id, email, password_hash, display_name, role, created_at, updated_at
+`
+
+type CreateUserParams struct {
+	Email        string
+	PasswordHash string
+	DisplayName  string
+	Role         string
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, createUser,
+		arg.Email,
+		arg.PasswordHash,
+		arg.DisplayName,
+		arg.Role,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.DisplayName,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteCommuteProfile = `-- name: DeleteCommuteProfile :execrows
+DELETE FROM commute_profiles
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteCommuteProfileParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) DeleteCommuteProfile(ctx context.Context, arg DeleteCommuteProfileParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCommuteProfile, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredSharedJourneys = `-- name: DeleteExpiredSharedJourneys :execrows
+DELETE FROM shared_journeys
+WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredSharedJourneys(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredSharedJourneys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteFinishedNotificationJobs = `-- name: DeleteFinishedNotificationJobs :execrows
+DELETE FROM notification_jobs
+WHERE status IN ('sent', 'failed') AND created_at < $1::timestamptz
+`
+
+func (q *Queries) DeleteFinishedNotificationJobs(ctx context.Context, before pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteFinishedNotificationJobs, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteIdleRateLimitBuckets = `-- name: DeleteIdleRateLimitBuckets :exec
+DELETE FROM rate_limit_buckets
+WHERE updated_at < $1
+`
+
+func (q *Queries) DeleteIdleRateLimitBuckets(ctx context.Context, idleSince pgtype.Timestamptz) error {
+	_, err := q.db.Exec(ctx, deleteIdleRateLimitBuckets, idleSince)
+	return err
+}
+
+const deleteNetworkFare = `-- name: DeleteNetworkFare :execrows
+DELETE FROM network_fares
+WHERE id = $1
+`
+
+func (q *Queries) DeleteNetworkFare(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteNetworkFare, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteNetworkRoute = `-- name: DeleteNetworkRoute :execrows
+DELETE FROM network_routes
+WHERE id = $1
+`
+
+func (q *Queries) DeleteNetworkRoute(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteNetworkRoute, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteNetworkStop = `-- name: DeleteNetworkStop :execrows
+DELETE FROM network_stops
+WHERE id = $1
+`
+
+func (q *Queries) DeleteNetworkStop(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteNetworkStop, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteNetworkTripPattern = `-- name: DeleteNetworkTripPattern :execrows
+DELETE FROM network_trip_patterns
+WHERE id = $1
+`
+
+func (q *Queries) DeleteNetworkTripPattern(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteNetworkTripPattern, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteNotificationSubscription = `-- name: DeleteNotificationSubscription :execrows
+DELETE FROM notification_subscriptions
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteNotificationSubscriptionParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) DeleteNotificationSubscription(ctx context.Context, arg DeleteNotificationSubscriptionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteNotificationSubscription, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteNotificationSubscriptionByTarget = `-- name: DeleteNotificationSubscriptionByTarget :exec
+DELETE FROM notification_subscriptions
+WHERE channel = $1 AND target = $2
+`
+
+type DeleteNotificationSubscriptionByTargetParams struct {
+	Channel string
+	Target  string
+}
+
+func (q *Queries) DeleteNotificationSubscriptionByTarget(ctx context.Context, arg DeleteNotificationSubscriptionByTargetParams) error {
+	_, err := q.db.Exec(ctx, deleteNotificationSubscriptionByTarget, arg.Channel, arg.Target)
+	return err
+}
+
+const deletePlace = `-- name: DeletePlace :execrows
+DELETE FROM places
+WHERE id = $1 AND user_id = $2
+`
+
+type DeletePlaceParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) DeletePlace(ctx context.Context, arg DeletePlaceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePlace, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteServiceAlert = `-- name: DeleteServiceAlert :execrows
+DELETE FROM service_alerts
+WHERE id = $1
+`
+
+func (q *Queries) DeleteServiceAlert(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteServiceAlert, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteTripReminder = `-- name: DeleteTripReminder :execrows
+DELETE FROM trip_reminders
+WHERE id = $1 AND user_id = $2
+`
+
+type DeleteTripReminderParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) DeleteTripReminder(ctx context.Context, arg DeleteTripReminderParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTripReminder, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteTripRemindersBefore = `-- name: DeleteTripRemindersBefore :execrows
+DELETE FROM trip_reminders
+WHERE depart_at < $1::timestamptz
+`
+
+func (q *Queries) DeleteTripRemindersBefore(ctx context.Context, before pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTripRemindersBefore, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUnconfirmedNotificationSubscriptions = `-- name: DeleteUnconfirmedNotificationSubscriptions :execrows
+DELETE FROM notification_subscriptions
+WHERE confirmed_at IS NULL AND created_at < $1::timestamptz
+`
+
+func (q *Queries) DeleteUnconfirmedNotificationSubscriptions(ctx context.Context, before pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUnconfirmedNotificationSubscriptions, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueNotificationJob = `-- name: EnqueueNotificationJob :execrows
+INSERT INTO notification_jobs (user_id, reminder_id, kind, dedupe_key, payload, run_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (dedupe_key) DO NOTHING
+`
+
+type EnqueueNotificationJobParams struct {
+	UserID     int64
+	ReminderID pgtype.Int8
+	Kind       string
+	DedupeKey  pgtype.Text
+	Payload    []byte
+	RunAt      pgtype.Timestamptz
+}
+
+func (q *Queries) EnqueueNotificationJob(ctx context.Context, arg EnqueueNotificationJobParams) (int64, error) {
+	result, err := q.db.Exec(ctx, enqueueNotificationJob,
+		arg.UserID,
+		arg.ReminderID,
+		arg.Kind,
+		arg.DedupeKey,
+		arg.Payload,
+		arg.RunAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const failNotificationJob = `-- name: FailNotificationJob :exec
+UPDATE notification_jobs
+SET status = 'failed', locked_until = NULL, last_error = $2
+WHERE id = $1
+`
+
+type FailNotificationJobParams struct {
+	ID        int64
+	LastError string
+}
+
+func (q *Queries) FailNotificationJob(ctx context.Context, arg FailNotificationJobParams) error {
+	_, err := q.db.Exec(ctx, failNotificationJob, arg.ID, arg.LastError)
+	return err
+}
+
+const getActiveAPIKeyByHash = `-- name: GetActiveAPIKeyByHash :one
+SELECT id, name, owner_id, prefix, key_hash, scopes, daily_quota, rate_limit_per_minute, created_at, rotated_at, revoked_at FROM api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getActiveAPIKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.DailyQuota,
+		&i.RateLimitPerMinute,
+		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getChangeRequest = `-- name: GetChangeRequest :one
+SELECT id, entity, action, entity_id, payload, status, submitted_by, submission_id, reviewed_by, reviewed_at, network_version, created_at FROM change_requests
+WHERE id = $1
+`
+
+func (q *Queries) GetChangeRequest(ctx context.Context, id int64) (ChangeRequest, error) {
+	row := q.db.QueryRow(ctx, getChangeRequest, id)
+	var i ChangeRequest
+	err := row.Scan(
+		&i.ID,
+		&i.Entity,
+		&i.Action,
+		&i.EntityID,
+		&i.Payload,
+		&i.Status,
+		&i.SubmittedBy,
+		&i.SubmissionID,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.NetworkVersion,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getChangeRequestForUpdate = `-- name: GetChangeRequestForUpdate :one
+SELECT id, entity, action, entity_id, payload, status, submitted_by, submission_id, reviewed_by, reviewed_at, network_version, created_at FROM change_requests
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetChangeRequestForUpdate(ctx context.Context, id int64) (ChangeRequest, error) {
+	row := q.db.QueryRow(ctx, getChangeRequestForUpdate, id)
+	var i ChangeRequest
+	err := row.Scan(
+		&i.ID,
+		&i.Entity,
+		&i.Action,
+		&i.EntityID,
+		&i.Payload,
+		&i.Status,
+		&i.SubmittedBy,
+		&i.SubmissionID,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.NetworkVersion,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCommuteProfile = `-- name: GetCommuteProfile :one
+SELECT id, user_id, name, restricted_modes, weights, walking_cutoff, max_transfers, created_at, updated_at FROM commute_profiles
+WHERE id = $1 AND user_id = $2
+`
+
+type GetCommuteProfileParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) GetCommuteProfile(ctx context.Context, arg GetCommuteProfileParams) (CommuteProfile, error) {
+	row := q.db.QueryRow(ctx, getCommuteProfile, arg.ID, arg.UserID)
+	var i CommuteProfile
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.RestrictedModes,
+		&i.Weights,
+		&i.WalkingCutoff,
+		&i.MaxTransfers,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLatestNetworkVersion = `-- name: GetLatestNetworkVersion :one
+SELECT version, change_request_id, created_by, created_at FROM network_versions
+ORDER BY version DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestNetworkVersion(ctx context.Context) (NetworkVersion, error) {
+	row := q.db.QueryRow(ctx, getLatestNetworkVersion)
+	var i NetworkVersion
+	err := row.Scan(
+		&i.Version,
+		&i.ChangeRequestID,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getNetworkFare = `-- name: GetNetworkFare :one
+SELECT id, route_id, amount, kind, mode, bands, from_zone, to_zone, percent, within_minutes, rider_category, created_at, updated_at FROM network_fares
+WHERE id = $1
+`
+
+func (q *Queries) GetNetworkFare(ctx context.Context, id int64) (NetworkFare, error) {
+	row := q.db.QueryRow(ctx, getNetworkFare, id)
+	var i NetworkFare
+	err := row.Scan(
+		&i.ID,
+		&i.RouteID,
+		&i.Amount,
+		&i.Kind,
+		&i.Mode,
+		&i.Bands,
+		&i.FromZone,
+		&i.ToZone,
+		&i.Percent,
+		&i.WithinMinutes,
+		&i.RiderCategory,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getNetworkRoute = `-- name: GetNetworkRoute :one
+SELECT id, short_name, long_name, mode, shape, wheelchair_accessible, created_at, updated_at FROM network_routes
+WHERE id = $1
+`
+
+func (q *Queries) GetNetworkRoute(ctx context.Context, id int64) (NetworkRoute, error) {
+	row := q.db.QueryRow(ctx, getNetworkRoute, id)
+	var i NetworkRoute
+	err := row.Scan(
+		&i.ID,
+		&i.ShortName,
+		&i.LongName,
+		&i.Mode,
+		&i.Shape,
+		&i.WheelchairAccessible,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getNetworkStop = `-- name: GetNetworkStop :one
+SELECT id, name, lat, lon, zone, wheelchair_boarding, has_stairs, has_overpass, created_at, updated_at FROM network_stops
+WHERE id = $1
+`
+
+func (q *Queries) GetNetworkStop(ctx context.Context, id int64) (NetworkStop, error) {
+	row := q.db.QueryRow(ctx, getNetworkStop, id)
+	var i NetworkStop
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Lat,
+		&i.Lon,
+		&i.Zone,
+		&i.WheelchairBoarding,
+		&i.HasStairs,
+		&i.HasOverpass,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getNetworkTripPattern = `-- name: GetNetworkTripPattern :one
+SELECT id, route_id, headsign, stop_ids, created_at, updated_at FROM network_trip_patterns
+WHERE id = $1
+`
+
+func (q *Queries) GetNetworkTripPattern(ctx context.Context, id int64) (NetworkTripPattern, error) {
+	row := q.db.QueryRow(ctx, getNetworkTripPattern, id)
+	var i NetworkTripPattern
+	err := row.Scan(
+		&i.ID,
+		&i.RouteID,
+		&i.Headsign,
+		&i.StopIds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPlace = `-- name: GetPlace :one
+SELECT id, user_id, name, kind, lat, lon, created_at, updated_at FROM places
+WHERE id = $1 AND user_id = $2
+`
+
+type GetPlaceParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) GetPlace(ctx context.Context, arg GetPlaceParams) (Place, error) {
+	row := q.db.QueryRow(ctx, getPlace, arg.ID, arg.UserID)
+	var i Place
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Kind,
+		&i.Lat,
+		&i.Lon,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRouteSubmission = `-- name: GetRouteSubmission :one
+SELECT id, submitted_by, name, mode, fare, origin_terminal, destination_terminal, shape, raw_points, length_meters, status, matched_ratio, created_at FROM route_submissions
+WHERE id = $1
+`
+
+func (q *Queries) GetRouteSubmission(ctx context.Context, id int64) (RouteSubmission, error) {
+	row := q.db.QueryRow(ctx, getRouteSubmission, id)
+	var i RouteSubmission
+	err := row.Scan(
+		&i.ID,
+		&i.SubmittedBy,
+		&i.Name,
+		&i.Mode,
+		&i.Fare,
+		&i.OriginTerminal,
+		&i.DestinationTerminal,
+		&i.Shape,
+		&i.RawPoints,
+		&i.LengthMeters,
+		&i.Status,
+		&i.MatchedRatio,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getServiceAlert = `-- name: GetServiceAlert :one
+SELECT id, cause, effect, severity, header, description, routes, trip_ids, stop_ids, active_periods, created_by, created_at, updated_at FROM service_alerts
+WHERE id = $1
+`
+
+func (q *Queries) GetServiceAlert(ctx context.Context, id int64) (ServiceAlert, error) {
+	row := q.db.QueryRow(ctx, getServiceAlert, id)
+	var i ServiceAlert
+	err := row.Scan(
+		&i.ID,
+		&i.Cause,
+		&i.Effect,
+		&i.Severity,
+		&i.Header,
+		&i.Description,
+		&i.Routes,
+		&i.TripIds,
+		&i.StopIds,
+		&i.ActivePeriods,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSharedJourney = `-- name: GetSharedJourney :one
+SELECT id, journey, created_at, expires_at FROM shared_journeys
+WHERE id = $1 AND expires_at > now()
+`
+
+func (q *Queries) GetSharedJourney(ctx context.Context, id string) (SharedJourney, error) {
+	row := q.db.QueryRow(ctx, getSharedJourney, id)
+	var i SharedJourney
+	err := row.Scan(
+		&i.ID,
+		&i.Journey,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getTripReminder = `-- name: GetTripReminder :one
+SELECT id, user_id, journey, depart_at, lead_minutes, watch_alerts, locale, created_at FROM trip_reminders
+WHERE id = $1
+`
+
+func (q *Queries) GetTripReminder(ctx context.Context, id int64) (TripReminder, error) {
+	row := q.db.QueryRow(ctx, getTripReminder, id)
+	var i TripReminder
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Journey,
+		&i.DepartAt,
+		&i.LeadMinutes,
+		&i.WatchAlerts,
+		&i.Locale,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, password_hash, display_name, role, created_at, updated_at FROM users
+WHERE email = $1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.DisplayName,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, email, password_hash, display_name, role, created_at, updated_at FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.DisplayName,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserRankingWeights = `-- name: GetUserRankingWeights :one
+SELECT user_id, time, cost, walk, transfer, samples, updated_at FROM user_ranking_weights
+WHERE user_id = $1
+`
+
+func (q *Queries) GetUserRankingWeights(ctx context.Context, userID int64) (UserRankingWeight, error) {
+	row := q.db.QueryRow(ctx, getUserRankingWeights, userID)
+	var i UserRankingWeight
+	err := row.Scan(
+		&i.UserID,
+		&i.Time,
+		&i.Cost,
+		&i.Walk,
+		&i.Transfer,
+		&i.Samples,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserRankingWeightsForUpdate = `-- name: GetUserRankingWeightsForUpdate :one
+SELECT user_id, time, cost, walk, transfer, samples, updated_at FROM user_ranking_weights
+WHERE user_id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetUserRankingWeightsForUpdate(ctx context.Context, userID int64) (UserRankingWeight, error) {
+	row := q.db.QueryRow(ctx, getUserRankingWeightsForUpdate, userID)
+	var i UserRankingWeight
+	err := row.Scan(
+		&i.UserID,
+		&i.Time,
+		&i.Cost,
+		&i.Walk,
+		&i.Transfer,
+		&i.Samples,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const incrementAPIKeyUsage = `-- name: IncrementAPIKeyUsage :one
+INSERT INTO api_key_usage AS u (key_id, day, request_count)
+VALUES ($1, (now() AT TIME ZONE 'UTC')::date, 1)
+ON CONFLICT (key_id, day) DO UPDATE
+SET request_count = u.request_count + 1
+WHERE $2::integer = 0 OR u.request_count < $2::integer
+RETURNING request_count
+`
+
+type IncrementAPIKeyUsageParams struct {
+	KeyID int64
+	Quota int32
+}
+
+// Counts a request against the key's quota for the current UTC day. Nothing
+// is counted and no row is returned once the quota is used up, zero is unlimited
+func (q *Queries) IncrementAPIKeyUsage(ctx context.Context, arg IncrementAPIKeyUsageParams) (int32, error) {
+	row := q.db.QueryRow(ctx, incrementAPIKeyUsage, arg.KeyID, arg.Quota)
+	var request_count int32
+	err := row.Scan(&request_count)
+	return request_count, err
+}
+
+const listAPIKeys = `-- name: ListAPIKeys :many
+SELECT k.id, k.name, k.owner_id, k.prefix, k.key_hash, k.scopes, k.daily_quota, k.rate_limit_per_minute, k.created_at, k.rotated_at, k.revoked_at, COALESCE(u.request_count, 0)::INTEGER AS requests_today
+FROM api_keys k
+LEFT JOIN api_key_usage u ON u.key_id = k.id AND u.day = (now() AT TIME ZONE 'UTC')::date
+ORDER BY k.id
+`
+
+type ListAPIKeysRow struct {
+	ID                 int64
+	Name               string
+	OwnerID            pgtype.Int8
+	Prefix             string
+	KeyHash            string
+	Scopes             []string
+	DailyQuota         int32
+	RateLimitPerMinute int32
+	CreatedAt          pgtype.Timestamptz
+	RotatedAt          pgtype.Timestamptz
+	RevokedAt          pgtype.Timestamptz
+	RequestsToday      int32
+}
+
+func (q *Queries) ListAPIKeys(ctx context.Context) ([]ListAPIKeysRow, error) {
+	rows, err := q.db.Query(ctx, listAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAPIKeysRow
+	for rows.Next() {
+		var i ListAPIKeysRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OwnerID,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scopes,
+			&i.DailyQuota,
+			&i.RateLimitPerMinute,
+			&i.CreatedAt,
+			&i.RotatedAt,
+			&i.RevokedAt,
+			&i.RequestsToday,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEntries = `-- name: ListAuditEntries :many
+SELECT id, actor_id, action, entity, entity_id, change_request_id, before, after, created_at FROM audit_log
+WHERE ($2::TEXT IS NULL OR entity = $2)
+  AND ($3::BIGINT IS NULL OR entity_id = $3)
+ORDER BY created_at DESC
+LIMIT $1
+`
+
+type ListAuditEntriesParams struct {
+	Limit    int32
+	Entity   pgtype.Text
+	EntityID pgtype.Int8
+}
+
+func (q *Queries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.Query(ctx, listAuditEntries, arg.Limit, arg.Entity, arg.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.Action,
+			&i.Entity,
+			&i.EntityID,
+			&i.ChangeRequestID,
+			&i.Before,
+			&i.After,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChangeRequestComments = `-- name: ListChangeRequestComments :many
+SELECT id, change_request_id, user_id, body, created_at FROM change_request_comments
+WHERE change_request_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListChangeRequestComments(ctx context.Context, changeRequestID int64) ([]ChangeRequestComment, error) {
+	rows, err := q.db.Query(ctx, listChangeRequestComments, changeRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChangeRequestComment
+	for rows.Next() {
+		var i ChangeRequestComment
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChangeRequestID,
+			&i.UserID,
+			&i.Body,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChangeRequests = `-- name: ListChangeRequests :many
+SELECT id, entity, action, entity_id, payload, status, submitted_by, submission_id, reviewed_by, reviewed_at, network_version, created_at FROM change_requests
+WHERE status = $1
+ORDER BY created_at
+LIMIT $2
+`
+
+type ListChangeRequestsParams struct {
+	Status string
+	Limit  int32
+}
+
+func (q *Queries) ListChangeRequests(ctx context.Context, arg ListChangeRequestsParams) ([]ChangeRequest, error) {
+	rows, err := q.db.Query(ctx, listChangeRequests, arg.Status, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChangeRequest
+	for rows.Next() {
+		var i ChangeRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.Entity,
+			&i.Action,
+			&i.EntityID,
+			&i.Payload,
+			&i.Status,
+			&i.SubmittedBy,
+			&i.SubmissionID,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.NetworkVersion,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCommuteProfiles = `-- name: ListCommuteProfiles :many
+SELECT id, user_id, name, restricted_modes, weights, walking_cutoff, max_transfers, created_at, updated_at FROM commute_profiles
+WHERE user_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListCommuteProfiles(ctx context.Context, userID int64) ([]CommuteProfile, error) {
+	rows, err := q.db.Query(ctx, listCommuteProfiles, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CommuteProfile
+	for rows.Next() {
+		var i CommuteProfile
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.RestrictedModes,
+			&i.Weights,
+			&i.WalkingCutoff,
+			&i.MaxTransfers,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkFares = `-- name: ListNetworkFares :many
+SELECT id, route_id, amount, kind, mode, bands, from_zone, to_zone, percent, within_minutes, rider_category, created_at, updated_at FROM network_fares
+ORDER BY id
+`
+
+func (q *Queries) ListNetworkFares(ctx context.Context) ([]NetworkFare, error) {
+	rows, err := q.db.Query(ctx, listNetworkFares)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NetworkFare
+	for rows.Next() {
+		var i NetworkFare
+		if err := rows.Scan(
+			&i.ID,
+			&i.RouteID,
+			&i.Amount,
+			&i.Kind,
+			&i.Mode,
+			&i.Bands,
+			&i.FromZone,
+			&i.ToZone,
+			&i.Percent,
+			&i.WithinMinutes,
+			&i.RiderCategory,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkRoutes = `-- name: ListNetworkRoutes :many
+SELECT id, short_name, long_name, mode, shape, wheelchair_accessible, created_at, updated_at FROM network_routes
+ORDER BY id
+`
+
+func (q *Queries) ListNetworkRoutes(ctx context.Context) ([]NetworkRoute, error) {
+	rows, err := q.db.Query(ctx, listNetworkRoutes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NetworkRoute
+	for rows.Next() {
+		var i NetworkRoute
+		if err := rows.Scan(
+			&i.ID,
+			&i.ShortName,
+			&i.LongName,
+			&i.Mode,
+			&i.Shape,
+			&i.WheelchairAccessible,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkStops = `-- name: ListNetworkStops :many
+SELECT id, name, lat, lon, zone, wheelchair_boarding, has_stairs, has_overpass, created_at, updated_at FROM network_stops
+ORDER BY id
+`
+
+func (q *Queries) ListNetworkStops(ctx context.Context) ([]NetworkStop, error) {
+	rows, err := q.db.Query(ctx, listNetworkStops)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NetworkStop
+	for rows.Next() {
+		var i NetworkStop
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Lat,
+			&i.Lon,
+			&i.Zone,
+			&i.WheelchairBoarding,
+			&i.HasStairs,
+			&i.HasOverpass,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkStopsInBox = `-- name: ListNetworkStopsInBox :many
+SELECT id, name, lat, lon, zone, wheelchair_boarding, has_stairs, has_overpass, created_at, updated_at FROM network_stops
+WHERE lat BETWEEN $1::float8 AND $2::float8
+  AND lon BETWEEN $3::float8 AND $4::float8
+`
+
+type ListNetworkStopsInBoxParams struct {
+	MinLat float64
+	MaxLat float64
+	MinLon float64
+	MaxLon float64
+}
+
+func (q *Queries) ListNetworkStopsInBox(ctx context.Context, arg ListNetworkStopsInBoxParams) ([]NetworkStop, error) {
+	rows, err := q.db.Query(ctx, listNetworkStopsInBox,
+		arg.MinLat,
+		arg.MaxLat,
+		arg.MinLon,
+		arg.MaxLon,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NetworkStop
+	for rows.Next() {
+		var i NetworkStop
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Lat,
+			&i.Lon,
+			&i.Zone,
+			&i.WheelchairBoarding,
+			&i.HasStairs,
+			&i.HasOverpass,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetworkTripPatterns = `-- name: ListNetworkTripPatterns :many
+SELECT id, route_id, headsign, stop_ids, created_at, updated_at FROM network_trip_patterns
+ORDER BY id
+`
+
+func (q *Queries) ListNetworkTripPatterns(ctx context.Context) ([]NetworkTripPattern, error) {
+	rows, err := q.db.Query(ctx, listNetworkTripPatterns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NetworkTripPattern
+	for rows.Next() {
+		var i NetworkTripPattern
+		if err := rows.Scan(
+			&i.ID,
+			&i.RouteID,
+			&i.Headsign,
+			&i.StopIds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotificationSubscriptions = `-- name: ListNotificationSubscriptions :many
+SELECT id, user_id, channel, target, p256dh, auth, confirm_token_hash, confirmed_at, confirm_sent_at, created_at FROM notification_subscriptions
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListNotificationSubscriptions(ctx context.Context, userID int64) ([]NotificationSubscription, error) {
+	rows, err := q.db.Query(ctx, listNotificationSubscriptions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationSubscription
+	for rows.Next() {
+		var i NotificationSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Channel,
+			&i.Target,
+			&i.P256dh,
+			&i.Auth,
+			&i.ConfirmTokenHash,
+			&i.ConfirmedAt,
+			&i.ConfirmSentAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlaces = `-- name: ListPlaces :many
+SELECT id, user_id, name, kind, lat, lon, created_at, updated_at FROM places
+WHERE user_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListPlaces(ctx context.Context, userID int64) ([]Place, error) {
+	rows, err := q.db.Query(ctx, listPlaces, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Place
+	for rows.Next() {
+		var i Place
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Kind,
+			&i.Lat,
+			&i.Lon,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRouteSubmissionsByUser = `-- name: ListRouteSubmissionsByUser :many
+SELECT id, submitted_by, name, mode, fare, origin_terminal, destination_terminal, shape, raw_points, length_meters, status, matched_ratio, created_at FROM route_submissions
+WHERE submitted_by = $1
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListRouteSubmissionsByUser(ctx context.Context, submittedBy pgtype.Int8) ([]RouteSubmission, error) {
+	rows, err := q.db.Query(ctx, listRouteSubmissionsByUser, submittedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RouteSubmission
+	for rows.Next() {
+		var i RouteSubmission
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubmittedBy,
+			&i.Name,
+			&i.Mode,
+			&i.Fare,
+			&i.OriginTerminal,
+			&i.DestinationTerminal,
+			&i.Shape,
+			&i.RawPoints,
+			&i.LengthMeters,
+			&i.Status,
+			&i.MatchedRatio,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServiceAlerts = `-- name: ListServiceAlerts :many
+SELECT id, cause, effect, severity, header, description, routes, trip_ids, stop_ids, active_periods, created_by, created_at, updated_at FROM service_alerts
+ORDER BY id DESC
+`
+
+func (q *Queries) ListServiceAlerts(ctx context.Context) ([]ServiceAlert, error) {
+	rows, err := q.db.Query(ctx, listServiceAlerts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ServiceAlert
+	for rows.Next() {
+		var i ServiceAlert
+		if err := rows.Scan(
+			&i.ID,
+			&i.Cause,
+			&i.Effect,
+			&i.Severity,
+			&i.Header,
+			&i.Description,
+			&i.Routes,
+			&i.TripIds,
+			&i.StopIds,
+			&i.ActivePeriods,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTripReminders = `-- name: ListTripReminders :many
+SELECT id, user_id, journey, depart_at, lead_minutes, watch_alerts, locale, created_at FROM trip_reminders
+WHERE user_id = $1 AND depart_at > now() - interval '1 hour'
+ORDER BY depart_at
+`
+
+func (q *Queries) ListTripReminders(ctx context.Context, userID int64) ([]TripReminder, error) {
+	rows, err := q.db.Query(ctx, listTripReminders, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TripReminder
+	for rows.Next() {
+		var i TripReminder
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Journey,
+			&i.DepartAt,
+			&i.LeadMinutes,
+			&i.WatchAlerts,
+			&i.Locale,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTripSelections = `-- name: ListTripSelections :many
+SELECT id, user_id, selected, alternatives, created_at FROM trip_selections
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListTripSelectionsParams struct {
+	UserID int64
+	Limit  int32
+}
+
+func (q *Queries) ListTripSelections(ctx context.Context, arg ListTripSelectionsParams) ([]TripSelection, error) {
+	rows, err := q.db.Query(ctx, listTripSelections, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TripSelection
+	for rows.Next() {
+		var i TripSelection
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Selected,
+			&i.Alternatives,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWatchedTripReminders = `-- name: ListWatchedTripReminders :many
+SELECT id, user_id, journey, depart_at, lead_minutes, watch_alerts, locale, created_at FROM trip_reminders
+WHERE watch_alerts AND depart_at > now() AND depart_at <= $1::timestamptz
+ORDER BY depart_at
+`
+
+func (q *Queries) ListWatchedTripReminders(ctx context.Context, until pgtype.Timestamptz) ([]TripReminder, error) {
+	rows, err := q.db.Query(ctx, listWatchedTripReminders, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TripReminder
+	for rows.Next() {
+		var i TripReminder
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Journey,
+			&i.DepartAt,
+			&i.LeadMinutes,
+			&i.WatchAlerts,
+			&i.Locale,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const peekRateLimitTokens = `-- name: PeekRateLimitTokens :one
+SELECT LEAST($1::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $2::float8)::float8 AS tokens
+FROM rate_limit_buckets b
+WHERE b.key = $3
+`
+
+type PeekRateLimitTokensParams struct {
+	Burst float64
+	Rate  float64
+	Key   string
+}
+
+// Returns the tokens the bucket would hold now without taking one
+func (q *Queries) PeekRateLimitTokens(ctx context.Context, arg PeekRateLimitTokensParams) (float64, error) {
+	row := q.db.QueryRow(ctx, peekRateLimitTokens, arg.Burst, arg.Rate, arg.Key)
+	var tokens float64
+	err := row.Scan(&tokens)
+	return tokens, err
+}
+
+const releaseNotificationJob = `-- name: ReleaseNotificationJob :exec
+UPDATE notification_jobs
+SET status = 'pending', attempts = attempts - 1, locked_until = NULL
+WHERE id = $1 AND status = 'running'
+`
+
+func (q *Queries) ReleaseNotificationJob(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, releaseNotificationJob, id)
+	return err
+}
+
+const replaceStopInTripPatterns = `-- name: ReplaceStopInTripPatterns :execrows
+UPDATE network_trip_patterns p
+SET stop_ids = (
+    SELECT COALESCE(array_agg(r.stop_id ORDER BY r.pos), '{}')::BIGINT[]
+    FROM (
+        SELECT t.stop_id, t.pos, lag(t.stop_id) OVER (ORDER BY t.pos) AS prev_id
+        FROM unnest(array_replace(p.stop_ids, $1::BIGINT, $2::BIGINT)) WITH ORDINALITY AS t(stop_id, pos)
+    ) r
+    WHERE r.prev_id IS DISTINCT FROM r.stop_id
+), updated_at = now()
+WHERE $1::BIGINT = ANY (p.stop_ids)
+`
+
+type ReplaceStopInTripPatternsParams struct {
+	OldStopID int64
+	NewStopID int64
+}
+
+// Repoints patterns at the new stop, collapsing the repeats left where the
+// old and new stops were consecutive
+func (q *Queries) ReplaceStopInTripPatterns(ctx context.Context, arg ReplaceStopInTripPatternsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, replaceStopInTripPatterns, arg.OldStopID, arg.NewStopID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const retryNotificationJob = `-- name: RetryNotificationJob :exec
+UPDATE notification_jobs
+SET status = 'pending', run_at = $1, locked_until = NULL, last_error = $2
+WHERE id = $3
+`
+
+type RetryNotificationJobParams struct {
+	RunAt     pgtype.Timestamptz
+	LastError string
+	ID        int64
+}
+
+func (q *Queries) RetryNotificationJob(ctx context.Context, arg RetryNotificationJobParams) error {
+	_, err := q.db.Exec(ctx, retryNotificationJob, arg.RunAt, arg.LastError, arg.ID)
+	return err
+}
+
+const reviewChangeRequest = `-- name: ReviewChangeRequest :one
+UPDATE change_requests
+SET status = $2, reviewed_by = $3, reviewed_at = now(), network_version = $4
+WHERE id = $1
+RETURNING id, entity, action, entity_id, payload, status, submitted_by, submission_id, reviewed_by, reviewed_at, network_version, created_at
+`
+
+type ReviewChangeRequestParams struct {
+	ID             int64
+	Status         string
+	ReviewedBy     pgtype.Int8
+	NetworkVersion pgtype.Int8
+}
+
+func (q *Queries) ReviewChangeRequest(ctx context.Context, arg ReviewChangeRequestParams) (ChangeRequest, error) {
+	row := q.db.QueryRow(ctx, reviewChangeRequest,
+		arg.ID,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.NetworkVersion,
+	)
+	var i ChangeRequest
+	err := row.Scan(
+		&i.ID,
+		&i.Entity,
+		&i.Action,
+		&i.EntityID,
+		&i.Payload,
+		&i.Status,
+		&i.SubmittedBy,
+		&i.SubmissionID,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.NetworkVersion,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING id, name, owner_id, prefix, key_hash, scopes, daily_quota, rate_limit_per_minute, created_at, rotated_at, revoked_at
+`
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, id int64) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, revokeAPIKey, id)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.DailyQuota,
+		&i.RateLimitPerMinute,
+		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const revokeRefreshToken = `-- name: RevokeRefreshToken :one
+UPDATE refresh_tokens
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL AND expires_at > now()
+RETURNING id, user_id, expires_at, revoked_at, created_at
+`
+
+// Revokes an active token, returning no rows if it was already revoked or expired
+func (q *Queries) RevokeRefreshToken(ctx context.Context, id string) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, revokeRefreshToken, id)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const rotateAPIKey = `-- name: RotateAPIKey :one
+UPDATE api_keys
+SET prefix = $2, key_hash = $3, rotated_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING id, name, owner_id, prefix, key_hash, scopes, daily_quota, rate_limit_per_minute, created_at, rotated_at, revoked_at
+`
+
+type RotateAPIKeyParams struct {
+	ID      int64
+	Prefix  string
+	KeyHash string
+}
+
+func (q *Queries) RotateAPIKey(ctx context.Context, arg RotateAPIKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, rotateAPIKey, arg.ID, arg.Prefix, arg.KeyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.DailyQuota,
+		&i.RateLimitPerMinute,
+		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET role = $2, updated_at = now()
+WHERE email = $1
+RETURNING id, email, password_hash, display_name, role, created_at, updated_at
+`
+
+type SetUserRoleParams struct {
+	Email string
+	Role  string
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserRole, arg.Email, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.DisplayName,
+		&i.Role,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const takeRateLimitToken = `-- name: TakeRateLimitToken :one
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES ($1, $2::float8 - 1, TRUE, now())
+ON CONFLICT (key) DO UPDATE SET
+    tokens = CASE
+        WHEN LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8) >= 1
+        THEN LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8) - 1
+        ELSE LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8)
+    END,
+    allowed = LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8) >= 1,
+    updated_at = now()
+RETURNING tokens, allowed
+`
+
+type TakeRateLimitTokenParams struct {
+	Key   string
+	Burst float64
+	Rate  float64
+}
+
+type TakeRateLimitTokenRow struct {
+	Tokens  float64
+	Allowed bool
+}
+
+// Refills the bucket for the time elapsed since the last call and takes a
+// token if one is available, all in a single atomic statement
+func (q *Queries) TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error) {
+	row := q.db.QueryRow(ctx, takeRateLimitToken, arg.Key, arg.Burst, arg.Rate)
+	var i TakeRateLimitTokenRow
+	err := row.Scan(&i.Tokens, &i.Allowed)
+	return i, err
+}
+
+const updateCommuteProfile = `-- name: UpdateCommuteProfile :one
+UPDATE commute_profiles
+SET name = $3, restricted_modes = $4, weights = $5, walking_cutoff = $6, max_transfers = $7, updated_at = now()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, name, restricted_modes, weights, walking_cutoff, max_transfers, created_at, updated_at
+`
+
+type UpdateCommuteProfileParams struct {
+	ID              int64
+	UserID          int64
+	Name            string
+	RestrictedModes []string
+	Weights         []byte
+	WalkingCutoff   float64
+	MaxTransfers    int32
+}
+
+func (q *Queries) UpdateCommuteProfile(ctx context.Context, arg UpdateCommuteProfileParams) (CommuteProfile, error) {
+	row := q.db.QueryRow(ctx, updateCommuteProfile,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.RestrictedModes,
+		arg.Weights,
+		arg.WalkingCutoff,
+		arg.MaxTransfers,
+	)
+	var i CommuteProfile
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.RestrictedModes,
+		&i.Weights,
+		&i.WalkingCutoff,
+		&i.MaxTransfers,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateNetworkFare = `-- name: UpdateNetworkFare :one
+UPDATE network_fares
+SET route_id = $2, amount = $3, kind = $4, mode = $5, bands = $6, from_zone = $7, to_zone = $8,
+    percent = $9, within_minutes = $10, rider_category = $11, updated_at = now()
+WHERE id = $1
+RETURNING id, route_id, amount, kind, mode, bands, from_zone, to_zone, percent, within_minutes, rider_category, created_at, updated_at
+`
+
+type UpdateNetworkFareParams struct {
+	ID            int64
+	RouteID       pgtype.Int8
+	Amount        float64
+	Kind          string
+	Mode          string
+	Bands         []byte
+	FromZone      string
+	ToZone        string
+	Percent       float64
+	WithinMinutes int32
+	RiderCategory string
+}
+
+func (q *Queries) UpdateNetworkFare(ctx context.Context, arg UpdateNetworkFareParams) (NetworkFare, error) {
+	row := q.db.QueryRow(ctx, updateNetworkFare,
+		arg.ID,
+		arg.RouteID,
+		arg.Amount,
+		arg.Kind,
+		arg.Mode,
+		arg.Bands,
+		arg.FromZone,
+		arg.ToZone,
+		arg.Percent,
+		arg.WithinMinutes,
+		arg.RiderCategory,
+	)
+	var i NetworkFare
+	err := row.Scan(
+		&i.ID,
+		&i.RouteID,
+		&i.Amount,
+		&i.Kind,
+		&i.Mode,
+		&i.Bands,
+		&i.FromZone,
+		&i.ToZone,
+		&i.Percent,
+		&i.WithinMinutes,
+		&i.RiderCategory,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateNetworkRoute = `-- name: UpdateNetworkRoute :one
+UPDATE network_routes
+SET short_name = $2, long_name = $3, mode = $4, shape = $5, wheelchair_accessible = $6, updated_at = now()
+WHERE id = $1
+RETURNING id, short_name, long_name, mode, shape, wheelchair_accessible, created_at, updated_at
+`
+
+type UpdateNetworkRouteParams struct {
+	ID                   int64
+	ShortName            string
+	LongName             string
+	Mode                 string
+	Shape                []byte
+	WheelchairAccessible int16
+}
+
+func (q *Queries) UpdateNetworkRoute(ctx context.Context, arg UpdateNetworkRouteParams) (NetworkRoute, error) {
+	row := q.db.QueryRow(ctx, updateNetworkRoute,
+		arg.ID,
+		arg.ShortName,
+		arg.LongName,
+		arg.Mode,
+		arg.Shape,
+		arg.WheelchairAccessible,
+	)
+	var i NetworkRoute
+	err := row.Scan(
+		&i.ID,
+		&i.ShortName,
+		&i.LongName,
+		&i.Mode,
+		&i.Shape,
+		&i.WheelchairAccessible,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateNetworkStop = `-- name: UpdateNetworkStop :one
+UPDATE network_stops
+SET name = $2, lat = $3, lon = $4, zone = $5, wheelchair_boarding = $6, has_stairs = $7, has_overpass = $8,
+    updated_at = now()
+WHERE id = $1
+RETURNING id, name, lat, lon, zone, wheelchair_boarding, has_stairs, has_overpass, created_at, updated_at
+`
+
+type UpdateNetworkStopParams struct {
+	ID                 int64
+	Name               string
+	Lat                float64
+	Lon                float64
+	Zone               string
+	WheelchairBoarding int16
+	HasStairs          bool
+	HasOverpass        bool
+}
+
+func (q *Queries) UpdateNetworkStop(ctx context.Context, arg UpdateNetworkStopParams) (NetworkStop, error) {
+	row := q.db.QueryRow(ctx, updateNetworkStop,
+		arg.ID,
+		arg.Name,
+		arg.Lat,
+		arg.Lon,
+		arg.Zone,
+		arg.WheelchairBoarding,
+		arg.HasStairs,
+		arg.HasOverpass,
+	)
+	var i NetworkStop
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Lat,
+		&i.Lon,
+		&i.Zone,
+		&i.WheelchairBoarding,
+		&i.HasStairs,
+		&i.HasOverpass,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateNetworkTripPattern = `-- name: UpdateNetworkTripPattern :one
+UPDATE network_trip_patterns
+SET route_id = $2, headsign = $3, stop_ids = $4, updated_at = now()
+WHERE id = $1
+RETURNING id, route_id, headsign, stop_ids, created_at, updated_at
+`
+
+type UpdateNetworkTripPatternParams struct {
+	ID       int64
+	RouteID  int64
+	Headsign string
+	StopIds  []int64
+}
+
+func (q *Queries) UpdateNetworkTripPattern(ctx context.Context, arg UpdateNetworkTripPatternParams) (NetworkTripPattern, error) {
+	row := q.db.QueryRow(ctx, updateNetworkTripPattern,
+		arg.ID,
+		arg.RouteID,
+		arg.Headsign,
+		arg.StopIds,
+	)
+	var i NetworkTripPattern
+	err := row.Scan(
+		&i.ID,
+		&i.RouteID,
+		&i.Headsign,
+		&i.StopIds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updatePlace = `-- name: UpdatePlace :one
+UPDATE places
+SET name = $3, kind = $4, lat = $5, lon = $6, updated_at = now()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, name, kind, lat, lon, created_at, updated_at
+`
+
+type UpdatePlaceParams struct {
+	ID     int64
+	UserID int64
+	Name   string
+	Kind   string
+	Lat    float64
+	Lon    float64
+}
+
+func (q *Queries) UpdatePlace(ctx context.Context, arg UpdatePlaceParams) (Place, error) {
+	row := q.db.QueryRow(ctx, updatePlace,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Kind,
+		arg.Lat,
+		arg.Lon,
+	)
+	var i Place
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Kind,
+		&i.Lat,
+		&i.Lon,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateRouteSubmissionStatus = `-- name: UpdateRouteSubmissionStatus :exec
+UPDATE route_submissions
+SET status = $2
+WHERE id = $1
+`
+
+type UpdateRouteSubmissionStatusParams struct {
+	ID     int64
+	Status string
+}
+
+func (q *Queries) UpdateRouteSubmissionStatus(ctx context.Context, arg UpdateRouteSubmissionStatusParams) error {
+	_, err := q.db.Exec(ctx, updateRouteSubmissionStatus, arg.ID, arg.Status)
+	return err
+}
+
+const updateServiceAlert = `-- name: UpdateServiceAlert :one
+UPDATE service_alerts
+SET cause = $2, effect = $3, severity = $4, header = $5, description = $6,
+    routes = $7, trip_ids = $8, stop_ids = $9, active_periods = $10, updated_at = now()
+WHERE id = $1
+RETURNING id, cause, effect, severity, header, description, routes, trip_ids, stop_ids, active_periods, created_by, created_at, updated_at
+`
+
+type UpdateServiceAlertParams struct {
+	ID            int64
+	Cause         string
+	Effect        string
+	Severity      string
+	Header        []byte
+	Description   []byte
+	Routes        []string
+	TripIds       []string
+	StopIds       []int32
+	ActivePeriods []byte
+}
+
+func (q *Queries) UpdateServiceAlert(ctx context.Context, arg UpdateServiceAlertParams) (ServiceAlert, error) {
+	row := q.db.QueryRow(ctx, updateServiceAlert,
+		arg.ID,
+		arg.Cause,
+		arg.Effect,
+		arg.Severity,
+		arg.Header,
+		arg.Description,
+		arg.Routes,
+		arg.TripIds,
+		arg.StopIds,
+		arg.ActivePeriods,
+	)
+	var i ServiceAlert
+	err := row.Scan(
+		&i.ID,
+		&i.Cause,
+		&i.Effect,
+		&i.Severity,
+		&i.Header,
+		&i.Description,
+		&i.Routes,
+		&i.TripIds,
+		&i.StopIds,
+		&i.ActivePeriods,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertUserRankingWeights = `-- name: UpsertUserRankingWeights :one
+INSERT INTO user_ranking_weights (user_id, time, cost, walk, transfer, samples)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id) DO UPDATE
+SET time = EXCLUDED.time,
+    cost = EXCLUDED.cost,
+    walk = EXCLUDED.walk,
+    transfer = EXCLUDED.transfer,
+    samples = EXCLUDED.samples,
+    updated_at = now()
+RETURNING user_id, time, cost, walk, transfer, samples, updated_at
+`
+
+type UpsertUserRankingWeightsParams struct {
+	UserID   int64
+	Time     float64
+	Cost     float64
+	Walk     float64
+	Transfer float64
+	Samples  int32
+}
+
+func (q *Queries) UpsertUserRankingWeights(ctx context.Context, arg UpsertUserRankingWeightsParams) (UserRankingWeight, error) {
+	row := q.db.QueryRow(ctx, upsertUserRankingWeights,
+		arg.UserID,
+		arg.Time,
+		arg.Cost,
+		arg.Walk,
+		arg.Transfer,
+		arg.Samples,
+	)
+	var i UserRankingWeight
+	err := row.Scan(
+		&i.UserID,
+		&i.Time,
+		&i.Cost,
+		&i.Walk,
+		&i.Transfer,
+		&i.Samples,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/caarlos0/env/v6"
 	"github.com/joho/godotenv"
 )
@@ -10,6 +13,55 @@ type Config struct {
 	Port               string `env:"PORT,required"`
 	ENV                string `env:"ENV,required"`
 	RoutingServiceAddr string `env:"ROUTING_SERVICE_ADDR,required"`
+
+	PublicBaseURL string        `env:"PUBLIC_BASE_URL" envDefault:"http://localhost:3000"`
+	ShareLinkTTL  time.Duration `env:"SHARE_LINK_TTL" envDefault:"720h"`
+
+	JWTSecret       string        `env:"JWT_SECRET,required"`
+	AccessTokenTTL  time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+
+	RateLimitBackend string `env:"RATE_LIMIT_BACKEND" envDefault:"memory"`
+	RateLimits       string `env:"RATE_LIMITS" envDefault:"/api/v1/route=30,/api/v1/journeys/share=10,/api/v1/itinerary=5,/api/v1/health=600,*=300"`
+	TrustProxy       bool   `env:"TRUST_PROXY" envDefault:"false"`
+
+	// Realtime feeds are GTFS-RT URLs or local file paths, .json files are read as JSON
+	RealtimeFeedSources  []string      `env:"REALTIME_FEED_SOURCES" envSeparator:","`
+	RealtimePollInterval time.Duration `env:"REALTIME_POLL_INTERVAL" envDefault:"30s"`
+	RealtimeMaxAge       time.Duration `env:"REALTIME_MAX_AGE" envDefault:"10m"`
+	// Rider position reports older than this no longer place a vehicle
+	CrowdReportMaxAge time.Duration `env:"CROWD_REPORT_MAX_AGE" envDefault:"3m"`
+	// Service area as "minLat,minLon,maxLat,maxLon", stops outside it are rejected
+	ServiceArea string `env:"SERVICE_AREA"`
+	// New stops closer than this many meters to an existing one are rejected
+	StopDuplicateRadius float64 `env:"STOP_DUPLICATE_RADIUS" envDefault:"25"`
+	// URL the routing service fetches network snapshots from, defaults to this gateway
+	NetworkSnapshotURL string `env:"NETWORK_SNAPSHOT_URL"`
+	// Secret the routing service presents to fetch snapshots, random per process when empty
+	NetworkSnapshotToken string `env:"NETWORK_SNAPSHOT_TOKEN"`
+	// Pricing of first and last mile rides as "mode=base:..,km:..,min:..,minimum:..,kmh:..;..."
+	MobilityPricing string `env:"MOBILITY_PRICING" envDefault:"taxi=base:10,km:4,min:0.5,minimum:20,kmh:22;ride_hail=base:12,km:4.5,min:0.6,minimum:25,kmh:22;bike=base:5,min:0.5,kmh:14;scooter=base:10,min:1.5,kmh:16"`
+	// CO2-equivalent grams per vehicle km and average occupancy per mode as "mode=grams:occupancy,..."
+	EmissionFactors string `env:"EMISSION_FACTORS" envDefault:"bus=1100:35,microbus=300:11,minibus=500:18,metro=3500:150,tram=1500:60,train=5000:200,taxi=180:1.4,ride_hail=180:1.4,scooter=35:1,bike=0:1,car=180:1.2"`
+	// OSM PBF extract walking paths are routed on, empty keeps the router's paths
+	OSMExtractPath string `env:"OSM_EXTRACT_PATH"`
+	// Walking speed in meters per second used with the street graph
+	WalkingSpeed float64 `env:"WALKING_SPEED" envDefault:"1.3"`
+
+	// How often due notifications are sent and reminders checked for alerts
+	NotifyPollInterval time.Duration `env:"NOTIFY_POLL_INTERVAL" envDefault:"15s"`
+	// Logs notifications instead of sending them, for local development
+	NotifyFakeTransport bool `env:"NOTIFY_FAKE_TRANSPORT" envDefault:"false"`
+	// Web push is enabled with a VAPID key, the unpadded base64url P-256 private key
+	VAPIDPrivateKey string `env:"VAPID_PRIVATE_KEY"`
+	VAPIDSubject    string `env:"VAPID_SUBJECT" envDefault:"mailto:admin@example.com"`
+	// FCM is enabled with the path of a Firebase service account key
+	FCMCredentialsFile string `env:"FCM_CREDENTIALS_FILE"`
+	// Email is enabled with an SMTP server as host:port
+	SMTPAddr     string `env:"SMTP_ADDR"`
+	SMTPFrom     string `env:"SMTP_FROM"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
 }
 
 // Cfg will hold your application’s config after Load()
@@ -18,5 +70,11 @@ var Cfg Config
 // Load reads .env (if present) and then parses into Cfg.
 func LoadENV() error {
 	_ = godotenv.Load()
-	return env.Parse(&Cfg)
+	if err := env.Parse(&Cfg); err != nil {
+		return err
+	}
+	if !(Cfg.WalkingSpeed > 0) {
+		return fmt.Errorf("WALKING_SPEED must be positive, got %v", Cfg.WalkingSpeed)
+	}
+	return nil
 }
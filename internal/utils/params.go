@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// PathInt64 parses a positive integer path value such as {id}
+func PathInt64(r *http.Request, name string) (int64, error) {
+	v, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil || v <= 0 {
+		return 0, errors.New("invalid " + name)
+	}
+	return v, nil
+}
@@ -0,0 +1,213 @@
+package walking
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+	"github.com/paulmach/osm"
+	"github.com/paulmach/osm/osmpbf"
+)
+
+// cellDegrees is the size of the grid cells used to find the node nearest a point
+const cellDegrees = 0.002
+
+// walkable are the highway values a pedestrian may use unless tagged otherwise
+var walkable = map[string]bool{
+	"footway": true, "pedestrian": true, "path": true, "steps": true, "corridor": true,
+	"living_street": true, "residential": true, "service": true, "unclassified": true, "track": true,
+	"tertiary": true, "tertiary_link": true, "secondary": true, "secondary_link": true,
+	"primary": true, "primary_link": true, "trunk": true, "trunk_link": true, "road": true, "cycleway": true,
+}
+
+// drivable are the highway values buses and microbuses may use unless
+// tagged otherwise
+var drivable = map[string]bool{
+	"motorway": true, "motorway_link": true, "trunk": true, "trunk_link": true,
+	"primary": true, "primary_link": true, "secondary": true, "secondary_link": true,
+	"tertiary": true, "tertiary_link": true, "unclassified": true, "residential": true,
+	"living_street": true, "service": true, "road": true, "busway": true,
+}
+
+type edge struct {
+	to     int32
+	meters float32
+	// steps marks stairs, walks avoiding stairs skip the edge
+	steps bool
+}
+
+// Graph is a street network of an OSM extract, either the streets a
+// pedestrian may walk or those a vehicle may drive
+type Graph struct {
+	points []geo.Point
+	edges  [][]edge
+	cells  map[[2]int32][]int32
+}
+
+// ways collects the ways of one graph and numbers the nodes they use
+type ways struct {
+	ids   map[osm.NodeID]int32
+	nodes [][]osm.NodeID
+	steps []bool
+}
+
+func newWays() *ways {
+	return &ways{ids: make(map[osm.NodeID]int32)}
+}
+
+func (w *ways) add(way *osm.Way, steps bool) {
+	nodes := make([]osm.NodeID, len(way.Nodes))
+	for i, n := range way.Nodes {
+		nodes[i] = n.ID
+		if _, ok := w.ids[n.ID]; !ok {
+			w.ids[n.ID] = int32(len(w.ids))
+		}
+	}
+	w.nodes = append(w.nodes, nodes)
+	w.steps = append(w.steps, steps)
+}
+
+// Load builds the walk graph and the drive graph of an OSM PBF extract. Ways
+// are read first to keep only the nodes they use, then the file is read
+// again for the nodes
+func Load(ctx context.Context, path string) (walk, drive *Graph, err error) {
+	walkWays, driveWays := newWays(), newWays()
+	err = scan(ctx, path, func(s *osmpbf.Scanner) { s.SkipNodes, s.SkipRelations = true, true }, func(o osm.Object) {
+		w, ok := o.(*osm.Way)
+		if !ok {
+			return
+		}
+		if isWalkable(w.Tags) {
+			walkWays.add(w, w.Tags.Find("highway") == "steps")
+		}
+		if isDrivable(w.Tags) {
+			driveWays.add(w, false)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	walkPoints := make([]geo.Point, len(walkWays.ids))
+	drivePoints := make([]geo.Point, len(driveWays.ids))
+	err = scan(ctx, path, func(s *osmpbf.Scanner) { s.SkipWays, s.SkipRelations = true, true }, func(o osm.Object) {
+		n, ok := o.(*osm.Node)
+		if !ok {
+			return
+		}
+		if i, ok := walkWays.ids[n.ID]; ok {
+			walkPoints[i] = geo.Point{Lat: n.Lat, Lon: n.Lon}
+		}
+		if i, ok := driveWays.ids[n.ID]; ok {
+			drivePoints[i] = geo.Point{Lat: n.Lat, Lon: n.Lon}
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newGraph(walkWays, walkPoints), newGraph(driveWays, drivePoints), nil
+}
+
+func newGraph(w *ways, points []geo.Point) *Graph {
+	g := &Graph{
+		points: points,
+		edges:  make([][]edge, len(points)),
+		cells:  make(map[[2]int32][]int32),
+	}
+	for i, way := range w.nodes {
+		steps := w.steps[i]
+		for k := 1; k < len(way); k++ {
+			a, b := w.ids[way[k-1]], w.ids[way[k]]
+			meters := float32(geo.Distance(g.points[a], g.points[b]))
+			g.edges[a] = append(g.edges[a], edge{to: b, meters: meters, steps: steps})
+			g.edges[b] = append(g.edges[b], edge{to: a, meters: meters, steps: steps})
+		}
+	}
+	for i, p := range g.points {
+		if len(g.edges[i]) > 0 {
+			c := cell(p)
+			g.cells[c] = append(g.cells[c], int32(i))
+		}
+	}
+	return g
+}
+
+func scan(ctx context.Context, path string, setup func(*osmpbf.Scanner), visit func(osm.Object)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open OSM extract: %w", err)
+	}
+	defer f.Close()
+
+	s := osmpbf.New(ctx, f, runtime.GOMAXPROCS(0))
+	defer s.Close()
+	setup(s)
+
+	for s.Scan() {
+		visit(s.Object())
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("failed to read OSM extract: %w", err)
+	}
+	return nil
+}
+
+func isWalkable(tags osm.Tags) bool {
+	switch tags.Find("foot") {
+	case "yes", "designated", "permissive":
+		return tags.Find("highway") != ""
+	case "no":
+		return false
+	}
+	if a := tags.Find("access"); a == "no" || a == "private" {
+		return false
+	}
+	return walkable[tags.Find("highway")] && tags.Find("area") != "yes"
+}
+
+// isDrivable reports whether a bus may use the way. Public service vehicle
+// access overrides a general ban on motor vehicles
+func isDrivable(tags osm.Tags) bool {
+	if a := tags.Find("access"); a == "no" || a == "private" {
+		return false
+	}
+	if mv := tags.Find("motor_vehicle"); (mv == "no" || mv == "private") && tags.Find("psv") != "yes" && tags.Find("bus") != "yes" {
+		return false
+	}
+	return drivable[tags.Find("highway")] && tags.Find("area") != "yes"
+}
+
+// Nodes is the number of nodes with at least one street
+func (g *Graph) Nodes() int {
+	n := 0
+	for _, c := range g.cells {
+		n += len(c)
+	}
+	return n
+}
+
+// nearest returns the graph node closest to p within maxMeters
+func (g *Graph) nearest(p geo.Point, maxMeters float64) (int32, bool) {
+	c := cell(p)
+	rings := int32(math.Ceil(maxMeters / (cellDegrees * 111_000 * math.Max(math.Cos(p.Lat*math.Pi/180), 0.1))))
+
+	best, bestMeters := int32(-1), maxMeters
+	for dy := -rings; dy <= rings; dy++ {
+		for dx := -rings; dx <= rings; dx++ {
+			for _, i := range g.cells[[2]int32{c[0] + dy, c[1] + dx}] {
+				if d := geo.Distance(p, g.points[i]); d <= bestMeters {
+					best, bestMeters = i, d
+				}
+			}
+		}
+	}
+	return best, best >= 0
+}
+
+func cell(p geo.Point) [2]int32 {
+	return [2]int32{int32(math.Floor(p.Lat / cellDegrees)), int32(math.Floor(p.Lon / cellDegrees))}
+}
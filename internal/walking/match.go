@@ -0,0 +1,51 @@
+package walking
+
+import "github.com/Marwan051/final_project_backend/internal/geo"
+
+const (
+	// matchSnapMeters is how far a GPS fix may be from a street node to be matched
+	matchSnapMeters = 40
+	// matchDetourFactor bounds the street path between consecutive fixes, longer
+	// paths mean the vehicle left the graph's streets
+	matchDetourFactor = 3
+)
+
+// Match snaps a vehicle's GPS trace onto the streets, joining consecutive
+// fixes along the shortest street path between them. It is meant for the
+// drive graph, on the walk graph paths could cut through footways. Fixes
+// away from any street and gaps with no plausible street path keep the raw
+// trace. It also returns the share of fixes matched
+func (g *Graph) Match(trace []geo.Point) ([]geo.Point, float64) {
+	if len(trace) == 0 {
+		return nil, 0
+	}
+
+	matched := make([]geo.Point, 0, len(trace))
+	prev, snapped := int32(-1), 0
+	for _, p := range trace {
+		node, ok := g.nearest(p, matchSnapMeters)
+		if !ok {
+			matched = append(matched, p)
+			prev = -1
+			continue
+		}
+		snapped++
+		if node == prev {
+			continue
+		}
+
+		if prev >= 0 {
+			limit := matchDetourFactor*geo.Distance(g.points[prev], g.points[node]) + matchSnapMeters
+			if nodes, _, ok := g.astar(prev, node, limit, false); ok {
+				for _, n := range nodes[1:] {
+					matched = append(matched, g.points[n])
+				}
+				prev = node
+				continue
+			}
+		}
+		matched = append(matched, g.points[node])
+		prev = node
+	}
+	return matched, float64(snapped) / float64(len(trace))
+}
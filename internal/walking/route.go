@@ -0,0 +1,113 @@
+package walking
+
+import (
+	"container/heap"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+)
+
+const (
+	// snapMeters is how far a point may be from the nearest street node
+	snapMeters = 250
+	// detourFactor bounds the search, walks longer than this times the
+	// straight line are not worth finding
+	detourFactor = 4
+)
+
+// Route finds the shortest walk between two points along the streets,
+// leaving out stairs when avoidSteps is set. The path starts at from and
+// ends at to, false when either is off the network or they are not connected
+func (g *Graph) Route(from, to geo.Point, avoidSteps bool) ([]geo.Point, float64, bool) {
+	src, ok := g.nearest(from, snapMeters)
+	if !ok {
+		return nil, 0, false
+	}
+	dst, ok := g.nearest(to, snapMeters)
+	if !ok {
+		return nil, 0, false
+	}
+
+	nodes, meters, ok := g.astar(src, dst, detourFactor*geo.Distance(from, to)+2*snapMeters, avoidSteps)
+	if !ok {
+		return nil, 0, false
+	}
+
+	path := make([]geo.Point, 0, len(nodes)+2)
+	path = append(path, from)
+	for _, n := range nodes {
+		path = append(path, g.points[n])
+	}
+	path = append(path, to)
+
+	meters += geo.Distance(from, g.points[src]) + geo.Distance(g.points[dst], to)
+	return path, meters, true
+}
+
+type item struct {
+	node  int32
+	dist  float64
+	score float64
+}
+
+type queue []item
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].score < q[j].score }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)        { *q = append(*q, x.(item)) }
+func (q *queue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
+
+// astar returns the nodes from src to dst and the length of the walk,
+// giving up on walks longer than limit meters
+func (g *Graph) astar(src, dst int32, limit float64, avoidSteps bool) ([]int32, float64, bool) {
+	target := g.points[dst]
+	dist := map[int32]float64{src: 0}
+	prev := map[int32]int32{}
+
+	q := &queue{{node: src, dist: 0, score: geo.Distance(g.points[src], target)}}
+	for q.Len() > 0 {
+		cur := heap.Pop(q).(item)
+		if cur.node == dst {
+			break
+		}
+		d := dist[cur.node]
+		if cur.dist > d {
+			// Stale entry, the node was reached on a shorter walk since
+			continue
+		}
+
+		for _, e := range g.edges[cur.node] {
+			if avoidSteps && e.steps {
+				continue
+			}
+			nd := d + float64(e.meters)
+			if nd > limit {
+				continue
+			}
+			if old, seen := dist[e.to]; seen && old <= nd {
+				continue
+			}
+			dist[e.to], prev[e.to] = nd, cur.node
+			heap.Push(q, item{node: e.to, dist: nd, score: nd + geo.Distance(g.points[e.to], target)})
+		}
+	}
+
+	meters, ok := dist[dst]
+	if !ok {
+		return nil, 0, false
+	}
+	nodes := []int32{dst}
+	for n := dst; n != src; {
+		n = prev[n]
+		nodes = append(nodes, n)
+	}
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+	return nodes, meters, true
+}
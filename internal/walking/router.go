@@ -0,0 +1,132 @@
+package walking
+
+import (
+	"context"
+	"math"
+
+	"github.com/Marwan051/final_project_backend/internal/geo"
+	"github.com/Marwan051/final_project_backend/internal/service/route_service"
+)
+
+// simplifyMeters drops street nodes that do not change the drawn path
+const simplifyMeters = 3
+
+// Router decorates another Router, replacing the walking parts of journeys
+// with paths along the streets and their real length
+type Router struct {
+	route_service.Router
+	graph *Graph
+	// speed is the walking speed in meters per second
+	speed float64
+}
+
+func NewRouter(inner route_service.Router, graph *Graph, speed float64) *Router {
+	return &Router{
+		Router: inner,
+		graph:  graph,
+		speed:  speed,
+	}
+}
+
+func (r *Router) FindRoute(ctx context.Context, req route_service.RouteRequest) (route_service.RouteResponse, error) {
+	resp, err := r.Router.FindRoute(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	start := geo.Point{Lat: req.StartLat, Lon: req.StartLon}
+	end := geo.Point{Lat: req.EndLat, Lon: req.EndLon}
+	avoidSteps := req.Accessibility != nil && (req.Accessibility.AvoidStairs || req.Accessibility.StepFreeOnly)
+	for i := range resp.Journeys {
+		r.walk(&resp.Journeys[i], start, end, avoidSteps)
+	}
+	return resp, nil
+}
+
+// walk routes every walk and transfer leg between the legs around it and
+// moves the journey summary by the difference
+func (r *Router) walk(j *route_service.Journey, start, end geo.Point, avoidSteps bool) {
+	for k, leg := range j.Legs {
+		var path *[]route_service.Coordinate
+		var meters *int
+		switch {
+		case leg.Walk != nil:
+			path, meters = &leg.Walk.Path, &leg.Walk.DistanceMeters
+		case leg.Transfer != nil:
+			path, meters = &leg.Transfer.Path, &leg.Transfer.WalkingDistanceMeters
+		default:
+			continue
+		}
+
+		from, to := legEnd(j.Legs[:k], start), legStart(j.Legs[k+1:], end)
+		if len(*path) >= 2 {
+			from, to = point((*path)[0]), point((*path)[len(*path)-1])
+		}
+		streets, length, ok := r.graph.Route(from, to, avoidSteps)
+		if !ok {
+			continue
+		}
+
+		newMeters := int(math.Round(length))
+		walkMinutes := r.minutes(newMeters)
+		var delta int
+		if leg.Walk != nil {
+			delta = walkMinutes - leg.Walk.DurationMinutes
+			leg.Walk.DurationMinutes = walkMinutes
+		} else {
+			// Transfers include waiting, only the walking part changes
+			delta = walkMinutes - r.minutes(*meters)
+			leg.Transfer.DurationMinutes = max(0, leg.Transfer.DurationMinutes+delta)
+		}
+
+		j.Summary.WalkingDistanceMeters += newMeters - *meters
+		j.Summary.TotalDistanceMeters += newMeters - *meters
+		j.Summary.TotalTimeMinutes = max(0, j.Summary.TotalTimeMinutes+delta)
+		*meters = newMeters
+		*path = coordinates(geo.Simplify(streets, simplifyMeters))
+	}
+}
+
+func (r *Router) minutes(meters int) int {
+	return int(math.Ceil(float64(meters) / r.speed / 60))
+}
+
+// legEnd is where the legs before a walk leave the rider, the start of the
+// journey when there are none
+func legEnd(before []route_service.Leg, start geo.Point) geo.Point {
+	for i := len(before) - 1; i >= 0; i-- {
+		switch leg := before[i]; {
+		case leg.Trip != nil:
+			return point(leg.Trip.To.Coord)
+		case leg.Ride != nil:
+			return point(leg.Ride.To)
+		}
+	}
+	return start
+}
+
+// legStart is where the legs after a walk pick the rider up, the end of the
+// journey when there are none
+func legStart(after []route_service.Leg, end geo.Point) geo.Point {
+	for _, leg := range after {
+		switch {
+		case leg.Trip != nil:
+			return point(leg.Trip.From.Coord)
+		case leg.Ride != nil:
+			return point(leg.Ride.From)
+		}
+	}
+	return end
+}
+
+func point(c route_service.Coordinate) geo.Point {
+	return geo.Point{Lat: c.Lat, Lon: c.Lon}
+}
+
+func coordinates(points []geo.Point) []route_service.Coordinate {
+	coords := make([]route_service.Coordinate, len(points))
+	for i, p := range points {
+		coords[i] = route_service.Coordinate{Lat: p.Lat, Lon: p.Lon}
+	}
+	return coords
+}